@@ -0,0 +1,100 @@
+package api
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// TrustedProxies are the networks of the reverse proxies and load balancers in front of the API.
+// Only they are trusted to say which client they forward a request for; anyone else could send
+// X-Forwarded-For or X-Real-IP headers to pose as another client.
+type TrustedProxies []netip.Prefix
+
+// ClientIP returns the IP address of the client that sent r. If r comes from a trusted proxy, the
+// address is the last one in X-Forwarded-For that is not of a trusted proxy, or X-Real-IP if
+// there is no X-Forwarded-For. Otherwise it is the address of the peer.
+func (p TrustedProxies) ClientIP(r *http.Request) string {
+	peer, ok := remoteIP(r)
+	if !ok {
+		return r.RemoteAddr
+	}
+	if !p.trusts(peer) {
+		return peer.String()
+	}
+
+	hops := forwardedFor(r.Header)
+	if len(hops) == 0 {
+		if realIP, err := netip.ParseAddr(strings.TrimSpace(r.Header.Get("X-Real-IP"))); err == nil {
+			return realIP.Unmap().String()
+		}
+		return peer.String()
+	}
+	// Each proxy appends the address it received the request from, so addresses are only as
+	// trustworthy as the proxy that appended them: walk back from the peer until an untrusted one.
+	client := peer
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop, err := netip.ParseAddr(hops[i])
+		if err != nil {
+			break
+		}
+		client = hop.Unmap()
+		if !p.trusts(client) {
+			break
+		}
+	}
+	return client.String()
+}
+
+func (p TrustedProxies) trusts(ip netip.Addr) bool {
+	for _, prefix := range p {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// remoteIP returns the address of the peer that sent r.
+func remoteIP(r *http.Request) (netip.Addr, bool) {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return ip.Unmap(), true
+}
+
+// forwardedFor returns the addresses in all X-Forwarded-For headers of h, in order.
+func forwardedFor(h http.Header) []string {
+	var hops []string
+	for _, value := range h.Values("X-Forwarded-For") {
+		for _, hop := range strings.Split(value, ",") {
+			if hop = strings.TrimSpace(hop); hop != "" {
+				hops = append(hops, hop)
+			}
+		}
+	}
+	return hops
+}
+
+type clientIPKey struct{}
+
+// WithClientIP returns a copy of ctx carrying the IP address of the request's client.
+func WithClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPKey{}, ip)
+}
+
+// ClientIP returns the IP address of the client that sent the request, as stored by WithClientIP,
+// or the address of the peer if none was stored.
+func ClientIP(r *http.Request) string {
+	if ip, ok := r.Context().Value(clientIPKey{}).(string); ok {
+		return ip
+	}
+	return TrustedProxies(nil).ClientIP(r)
+}
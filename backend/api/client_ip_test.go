@@ -0,0 +1,86 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientIP(t *testing.T) {
+	proxies := TrustedProxies{netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("2001:db8::/32")}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		headers    map[string][]string
+		want       string
+	}{
+		{name: "no proxy", remoteAddr: "192.0.2.7:4321", want: "192.0.2.7"},
+		{
+			name:       "untrusted peer cannot pose as another client",
+			remoteAddr: "192.0.2.7:4321",
+			headers:    map[string][]string{"X-Forwarded-For": {"198.51.100.1"}, "X-Real-Ip": {"198.51.100.2"}},
+			want:       "192.0.2.7",
+		},
+		{
+			name:       "trusted proxy",
+			remoteAddr: "10.1.2.3:4321",
+			headers:    map[string][]string{"X-Forwarded-For": {"198.51.100.1"}},
+			want:       "198.51.100.1",
+		},
+		{
+			name:       "addresses before the first untrusted one are ignored",
+			remoteAddr: "10.1.2.3:4321",
+			headers:    map[string][]string{"X-Forwarded-For": {"203.0.113.9, 198.51.100.1", "10.4.5.6"}},
+			want:       "198.51.100.1",
+		},
+		{
+			name:       "only trusted proxies",
+			remoteAddr: "10.1.2.3:4321",
+			headers:    map[string][]string{"X-Forwarded-For": {"10.9.9.9, 10.4.5.6"}},
+			want:       "10.9.9.9",
+		},
+		{
+			name:       "invalid address stops at the proxy that added it",
+			remoteAddr: "10.1.2.3:4321",
+			headers:    map[string][]string{"X-Forwarded-For": {"198.51.100.1, bogus, 10.4.5.6"}},
+			want:       "10.4.5.6",
+		},
+		{
+			name:       "X-Real-IP from a trusted proxy",
+			remoteAddr: "[2001:db8::1]:4321",
+			headers:    map[string][]string{"X-Real-Ip": {"198.51.100.2"}},
+			want:       "198.51.100.2",
+		},
+		{
+			name:       "IPv4-mapped peer",
+			remoteAddr: "[::ffff:10.1.2.3]:4321",
+			headers:    map[string][]string{"X-Forwarded-For": {"198.51.100.1"}},
+			want:       "198.51.100.1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			for name, values := range tt.headers {
+				r.Header[name] = values
+			}
+			assert.Equal(t, tt.want, proxies.ClientIP(r))
+		})
+	}
+}
+
+func TestClientIPFromContext(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = "10.1.2.3:4321"
+	r.Header.Set("X-Forwarded-For", "198.51.100.1")
+	// Without trusted proxies, the headers are ignored.
+	assert.Equal(t, "10.1.2.3", ClientIP(r))
+
+	r = r.WithContext(WithClientIP(r.Context(), "198.51.100.1"))
+	assert.Equal(t, "198.51.100.1", ClientIP(r))
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/kaiohenricunha/go-music-k8s/backend/api"
+	"github.com/kaiohenricunha/go-music-k8s/backend/api/middleware"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/service"
+)
+
+// AccountHandlers encapsulates handlers for email verification and password resets.
+type AccountHandlers struct {
+	accountService service.AccountService
+}
+
+// NewAccountHandlers creates an instance of AccountHandlers.
+func NewAccountHandlers(accountService service.AccountService) *AccountHandlers {
+	return &AccountHandlers{accountService: accountService}
+}
+
+type emailTokenRequest struct {
+	Token string `json:"token" validate:"required,max=4096"`
+}
+
+type passwordResetRequest struct {
+	Email string `json:"email" validate:"required,max=254,email"`
+}
+
+type resetPasswordRequest struct {
+	Token    string `json:"token" validate:"required,max=4096"`
+	Password string `json:"password" validate:"required,min=8,max=72,password"`
+}
+
+// RequestEmailVerificationHandler handles POST requests to email the caller a new verification link.
+func (h *AccountHandlers) RequestEmailVerificationHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		api.LogErrorAndRespond(w, "Authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.accountService.RequestEmailVerification(userID); err != nil {
+		respondWithAccountError(w, "Failed to send verification email", err)
+		return
+	}
+
+	api.RespondWithJSON(w, http.StatusAccepted, map[string]string{"message": "Verification email sent"})
+}
+
+// VerifyEmailHandler handles POST requests with the token of a verification link.
+func (h *AccountHandlers) VerifyEmailHandler(w http.ResponseWriter, r *http.Request) {
+	var req emailTokenRequest
+	if !api.DecodeJSON(w, r, &req) {
+		return
+	}
+
+	if err := h.accountService.VerifyEmail(req.Token); err != nil {
+		respondWithAccountError(w, "Failed to verify email", err)
+		return
+	}
+
+	api.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Email verified"})
+}
+
+// RequestPasswordResetHandler handles POST requests to email a password reset link. It responds
+// the same whether or not a user has the email, and before the email is sent, so that neither
+// the response nor its timing reveals which emails are registered.
+func (h *AccountHandlers) RequestPasswordResetHandler(w http.ResponseWriter, r *http.Request) {
+	var req passwordResetRequest
+	if !api.DecodeJSON(w, r, &req) {
+		return
+	}
+
+	sendInBackground("password reset email", func() error { return h.accountService.RequestPasswordReset(req.Email) })
+
+	api.RespondWithJSON(w, http.StatusAccepted, map[string]string{"message": "If an account has this email, a password reset link has been sent to it"})
+}
+
+// ResetPasswordHandler handles POST requests with the token of a password reset link and the new password.
+func (h *AccountHandlers) ResetPasswordHandler(w http.ResponseWriter, r *http.Request) {
+	var req resetPasswordRequest
+	if !api.DecodeJSON(w, r, &req) {
+		return
+	}
+
+	if err := h.accountService.ResetPassword(req.Token, req.Password); err != nil {
+		respondWithAccountError(w, "Failed to reset password", err)
+		return
+	}
+
+	api.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Password reset"})
+}
+
+// sendInBackground sends an email without making the client wait for the mail server.
+func sendInBackground(what string, send func() error) {
+	go func() {
+		if err := send(); err != nil {
+			log.Printf("Failed to send %s: %v", what, err)
+		}
+	}()
+}
+
+func respondWithAccountError(w http.ResponseWriter, message string, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidEmailToken):
+		api.RespondWithRequestError(w, http.StatusBadRequest, api.RequestError{Error: "The link is invalid, expired or was already used", Code: "invalid_token"}, err)
+	case errors.Is(err, service.ErrEmailAlreadyVerified):
+		api.RespondWithRequestError(w, http.StatusConflict, api.RequestError{Error: "Email already verified", Code: "email_already_verified"}, err)
+	case errors.Is(err, service.ErrUserNotFound):
+		api.LogErrorWithDetails(w, "User not found", err, http.StatusNotFound)
+	default:
+		api.LogErrorWithDetails(w, message, err, http.StatusInternalServerError)
+	}
+}
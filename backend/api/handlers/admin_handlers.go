@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/kaiohenricunha/go-music-k8s/backend/api"
+	"github.com/kaiohenricunha/go-music-k8s/backend/api/middleware"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/service"
+)
+
+// AdminHandlers encapsulates handlers for administrative operations.
+type AdminHandlers struct {
+	catalogRefreshService service.CatalogRefreshService
+	loginService          service.LoginService
+}
+
+// NewAdminHandlers creates an instance of AdminHandlers.
+func NewAdminHandlers(catalogRefreshService service.CatalogRefreshService, loginService service.LoginService) *AdminHandlers {
+	return &AdminHandlers{
+		catalogRefreshService: catalogRefreshService,
+		loginService:          loginService,
+	}
+}
+
+// GetCatalogRefreshStatusHandler handles GET requests for the catalog refresh worker's progress.
+func (h *AdminHandlers) GetCatalogRefreshStatusHandler(w http.ResponseWriter, r *http.Request) {
+	api.RespondWithJSON(w, http.StatusOK, h.catalogRefreshService.Status())
+}
+
+// UnlockUserHandler handles POST requests to lift a lock on a user's logins after failed attempts.
+func (h *AdminHandlers) UnlockUserHandler(w http.ResponseWriter, r *http.Request) {
+	username := mux.Vars(r)["username"]
+
+	adminID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		api.LogErrorAndRespond(w, "Authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.loginService.UnlockUser(username, adminID); err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			api.LogErrorWithDetails(w, "User not found", err, http.StatusNotFound)
+			return
+		}
+		api.LogErrorWithDetails(w, "Failed to unlock user", err, http.StatusInternalServerError)
+		return
+	}
+
+	api.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "User unlocked successfully"})
+}
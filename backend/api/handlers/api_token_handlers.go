@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/kaiohenricunha/go-music-k8s/backend/api"
+	"github.com/kaiohenricunha/go-music-k8s/backend/api/middleware"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/auth"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/service"
+)
+
+// APITokenHandlers encapsulates handlers for the caller's personal API tokens.
+type APITokenHandlers struct {
+	apiTokenService service.APITokenService
+}
+
+// NewAPITokenHandlers creates an instance of APITokenHandlers.
+func NewAPITokenHandlers(apiTokenService service.APITokenService) *APITokenHandlers {
+	return &APITokenHandlers{apiTokenService: apiTokenService}
+}
+
+// sessionUserID returns the caller's user ID if they logged in. API tokens cannot manage API
+// tokens, so that a leaked token cannot be turned into one with more scopes or a longer life.
+func sessionUserID(ctx context.Context, w http.ResponseWriter) (uint, bool) {
+	claims, ok := auth.ClaimsFromContext(ctx)
+	if !ok {
+		api.LogErrorAndRespond(w, "Authorization required", http.StatusUnauthorized)
+		return 0, false
+	}
+	if claims.APIToken != nil {
+		api.LogErrorAndRespond(w, "API tokens cannot be used to manage API tokens", http.StatusForbidden)
+		return 0, false
+	}
+	return claims.UserID(), true
+}
+
+type createAPITokenRequest struct {
+	Name          string   `json:"name" validate:"required,max=100"`
+	Scopes        []string `json:"scopes" validate:"required,min=1,unique,dive,oneof=read write admin"`
+	ExpiresInDays int      `json:"expires_in_days" validate:"omitempty,min=1,max=365"`
+}
+
+// CreateAPITokenHandler handles POST requests creating a personal API token. The response holds
+// the token itself, which cannot be retrieved later.
+func (h *APITokenHandlers) CreateAPITokenHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := sessionUserID(r.Context(), w)
+	if !ok {
+		return
+	}
+
+	var req createAPITokenRequest
+	if !api.DecodeJSON(w, r, &req) {
+		return
+	}
+
+	token, err := h.apiTokenService.CreateAPIToken(userID, service.APITokenRequest{
+		Name:          req.Name,
+		Scopes:        req.Scopes,
+		ExpiresInDays: req.ExpiresInDays,
+	})
+	if err != nil {
+		respondWithAPITokenError(w, "Failed to create API token", err)
+		return
+	}
+
+	api.RespondWithJSON(w, http.StatusCreated, token)
+}
+
+// ListAPITokensHandler handles GET requests for the caller's API tokens that have not been revoked.
+func (h *APITokenHandlers) ListAPITokensHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		api.LogErrorAndRespond(w, "Authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	tokens, err := h.apiTokenService.ListAPITokens(userID)
+	if err != nil {
+		api.LogErrorWithDetails(w, "Failed to retrieve API tokens", err, http.StatusInternalServerError)
+		return
+	}
+
+	api.RespondWithJSON(w, http.StatusOK, tokens)
+}
+
+// RevokeAPITokenHandler handles DELETE requests revoking one of the caller's API tokens.
+func (h *APITokenHandlers) RevokeAPITokenHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := sessionUserID(r.Context(), w)
+	if !ok {
+		return
+	}
+
+	tokenID, err := strconv.ParseUint(mux.Vars(r)["tokenID"], 10, 64)
+	if err != nil {
+		api.LogErrorWithDetails(w, "Invalid token ID", err, http.StatusBadRequest)
+		return
+	}
+
+	if err := h.apiTokenService.RevokeAPIToken(userID, uint(tokenID)); err != nil {
+		respondWithAPITokenError(w, "Failed to revoke API token", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func respondWithAPITokenError(w http.ResponseWriter, message string, err error) {
+	switch {
+	case errors.Is(err, service.ErrAPITokenNotFound):
+		api.LogErrorWithDetails(w, "API token not found", err, http.StatusNotFound)
+	case errors.Is(err, service.ErrInvalidAPITokenScope):
+		api.LogErrorWithDetails(w, err.Error(), err, http.StatusBadRequest)
+	case errors.Is(err, service.ErrTooManyAPITokens):
+		api.RespondWithRequestError(w, http.StatusConflict, api.RequestError{Error: err.Error(), Code: "too_many_api_tokens"}, err)
+	default:
+		api.LogErrorWithDetails(w, message, err, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/kaiohenricunha/go-music-k8s/backend/api"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/auth"
+)
+
+// AuthHandlers encapsulates handlers that publish how tokens are signed.
+type AuthHandlers struct {
+	tokens auth.TokenConfig
+}
+
+// NewAuthHandlers creates an instance of AuthHandlers.
+func NewAuthHandlers(tokens auth.TokenConfig) *AuthHandlers {
+	return &AuthHandlers{tokens: tokens}
+}
+
+// JWKSHandler handles GET requests for the public keys that verify access tokens. Verifiers may
+// cache the keys briefly, so a new key should be published a while before it signs tokens.
+func (h *AuthHandlers) JWKSHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	api.RespondWithJSON(w, http.StatusOK, h.tokens.Keys.JWKS())
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/kaiohenricunha/go-music-k8s/backend/api"
+	"github.com/kaiohenricunha/go-music-k8s/backend/api/middleware"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/service"
+)
+
+// LibraryHandlers encapsulates handlers for the caller's personal library.
+type LibraryHandlers struct {
+	libraryService service.LibraryService
+}
+
+// NewLibraryHandlers creates an instance of LibraryHandlers.
+func NewLibraryHandlers(libraryService service.LibraryService) *LibraryHandlers {
+	return &LibraryHandlers{libraryService: libraryService}
+}
+
+// LikeSongHandler handles PUT requests adding a song to the caller's library.
+func (h *LibraryHandlers) LikeSongHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		api.LogErrorAndRespond(w, "Authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.libraryService.LikeSong(userID, mux.Vars(r)["songID"]); err != nil {
+		if errors.Is(err, service.ErrSongNotFound) {
+			api.LogErrorWithDetails(w, "Song not found", err, http.StatusNotFound)
+			return
+		}
+		api.LogErrorWithDetails(w, "Failed to like song", err, http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// UnlikeSongHandler handles DELETE requests removing a song from the caller's library.
+func (h *LibraryHandlers) UnlikeSongHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		api.LogErrorAndRespond(w, "Authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.libraryService.UnlikeSong(userID, mux.Vars(r)["songID"]); err != nil {
+		if errors.Is(err, service.ErrSongNotFound) {
+			api.LogErrorWithDetails(w, "Song not found", err, http.StatusNotFound)
+			return
+		}
+		api.LogErrorWithDetails(w, "Failed to unlike song", err, http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetLikedSongsHandler handles GET requests for the caller's liked songs. The optional "sort" query
+// parameter is added_desc (default) or added_asc, and "offset" and "limit" select the page.
+func (h *LibraryHandlers) GetLikedSongsHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		api.LogErrorAndRespond(w, "Authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	query := r.URL.Query()
+	var offset, limit int
+	var err error
+	if value := query.Get("offset"); value != "" {
+		if offset, err = strconv.Atoi(value); err != nil || offset < 0 {
+			api.LogErrorWithDetails(w, "Invalid offset", err, http.StatusBadRequest)
+			return
+		}
+	}
+	if value := query.Get("limit"); value != "" {
+		if limit, err = strconv.Atoi(value); err != nil || limit < 0 {
+			api.LogErrorWithDetails(w, "Invalid limit", err, http.StatusBadRequest)
+			return
+		}
+	}
+
+	page, err := h.libraryService.GetLikedSongs(userID, query.Get("sort"), offset, limit)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidLibrarySort) {
+			api.LogErrorWithDetails(w, err.Error(), err, http.StatusBadRequest)
+			return
+		}
+		api.LogErrorWithDetails(w, "Failed to retrieve liked songs", err, http.StatusInternalServerError)
+		return
+	}
+
+	api.RespondWithJSON(w, http.StatusOK, page)
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/kaiohenricunha/go-music-k8s/backend/api"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/auth"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/service"
+	"golang.org/x/oauth2"
+)
+
+const (
+	// oidcCookieName holds the state, nonce and PKCE verifier of a login in progress.
+	oidcCookieName = "oidc_login"
+	oidcCookiePath = "/api/v1/login/oidc"
+	// oidcLoginTimeout is how long a user has to sign in at the provider, in seconds.
+	oidcLoginTimeout = 10 * 60
+)
+
+// OIDCHandlers encapsulates handlers for logging in with an OpenID Connect provider.
+type OIDCHandlers struct {
+	oidcLoginService service.OIDCLoginService
+	tokens           auth.TokenConfig
+}
+
+// NewOIDCHandlers creates an instance of OIDCHandlers.
+func NewOIDCHandlers(oidcLoginService service.OIDCLoginService, tokens auth.TokenConfig) *OIDCHandlers {
+	return &OIDCHandlers{oidcLoginService: oidcLoginService, tokens: tokens}
+}
+
+// StartOIDCLoginHandler handles GET requests to log in with the provider by redirecting to its
+// sign-in page. The login's secrets are kept in a short-lived cookie until the provider redirects back.
+func (h *OIDCHandlers) StartOIDCLoginHandler(w http.ResponseWriter, r *http.Request) {
+	state, err := randomToken()
+	if err != nil {
+		api.LogErrorWithDetails(w, "Failed to start OIDC login", err, http.StatusInternalServerError)
+		return
+	}
+	nonce, err := randomToken()
+	if err != nil {
+		api.LogErrorWithDetails(w, "Failed to start OIDC login", err, http.StatusInternalServerError)
+		return
+	}
+	verifier := oauth2.GenerateVerifier()
+	authURL, err := h.oidcLoginService.AuthCodeURL(r.Context(), state, nonce, verifier)
+	if err != nil {
+		respondWithOIDCError(w, err)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcCookieName,
+		Value:    strings.Join([]string{state, nonce, verifier}, "."),
+		Path:     oidcCookiePath,
+		MaxAge:   oidcLoginTimeout,
+		HttpOnly: true,
+		Secure:   r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https",
+		// Lax, so that the cookie comes back with the provider's top-level redirect.
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// OIDCCallbackHandler handles the provider's redirect back after the user signed in, and responds
+// with an access token like the password login does.
+func (h *OIDCHandlers) OIDCCallbackHandler(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie(oidcCookieName)
+	// The login's secrets are single-use, whatever the outcome.
+	http.SetCookie(w, &http.Cookie{Name: oidcCookieName, Path: oidcCookiePath, MaxAge: -1, HttpOnly: true})
+	if err != nil {
+		api.LogErrorWithDetails(w, "No OIDC login in progress", err, http.StatusBadRequest)
+		return
+	}
+	parts := strings.Split(cookie.Value, ".")
+	query := r.URL.Query()
+	if len(parts) != 3 || subtle.ConstantTimeCompare([]byte(parts[0]), []byte(query.Get("state"))) != 1 {
+		api.LogErrorAndRespond(w, "OIDC login state does not match", http.StatusBadRequest)
+		return
+	}
+	if providerErr := query.Get("error"); providerErr != "" {
+		api.LogErrorWithDetails(w, "OIDC login failed", fmt.Errorf("provider error %s: %s", providerErr, query.Get("error_description")), http.StatusUnauthorized)
+		return
+	}
+
+	user, err := h.oidcLoginService.Login(r.Context(), query.Get("code"), parts[1], parts[2])
+	if err != nil {
+		respondWithOIDCError(w, err)
+		return
+	}
+
+	token, err := h.tokens.Issue(user.ID)
+	if err != nil {
+		api.LogErrorWithDetails(w, "Failed to generate token", err, http.StatusInternalServerError)
+		return
+	}
+	api.RespondWithJSON(w, http.StatusOK, map[string]string{"token": token})
+}
+
+// respondWithOIDCError maps OIDC login errors to HTTP responses.
+func respondWithOIDCError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrOIDCNotConfigured):
+		api.LogErrorWithDetails(w, "OIDC login is not configured", err, http.StatusNotFound)
+	case errors.Is(err, service.ErrOIDCLoginFailed), errors.Is(err, service.ErrUserNotFound):
+		api.LogErrorWithDetails(w, "OIDC login failed", err, http.StatusUnauthorized)
+	case errors.Is(err, service.ErrEmailNotVerified):
+		api.RespondWithRequestError(w, http.StatusForbidden,
+			api.RequestError{Error: "The identity provider has not verified your email", Code: "email_not_verified"}, err)
+	case errors.Is(err, service.ErrAccountNotLinked):
+		api.RespondWithRequestError(w, http.StatusConflict, api.RequestError{
+			Error: "An account with this email exists. Log in with your password and verify your email, then log in with your identity provider again to link them",
+			Code:  "account_not_linked",
+		}, err)
+	case errors.Is(err, service.ErrEmailTaken):
+		api.RespondWithRequestError(w, http.StatusConflict,
+			api.RequestError{Error: "Email already registered", Code: "email_taken"}, err)
+	default:
+		api.LogErrorWithDetails(w, "Failed to log in with OIDC", err, http.StatusInternalServerError)
+	}
+}
+
+// randomToken returns a random, URL-safe value for the state or nonce of a login.
+func randomToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/kaiohenricunha/go-music-k8s/backend/api"
+	"github.com/kaiohenricunha/go-music-k8s/backend/api/middleware"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/service"
+)
+
+// maxPlayBatchSize limits the size of a play ingestion request body.
+const maxPlayBatchSize = 1 << 20
+
+// PlayHandlers encapsulates handlers for play events and listening history.
+type PlayHandlers struct {
+	playService service.PlayService
+}
+
+// NewPlayHandlers creates an instance of PlayHandlers.
+func NewPlayHandlers(playService service.PlayService) *PlayHandlers {
+	return &PlayHandlers{playService: playService}
+}
+
+// RecordPlaysHandler handles POST requests carrying a batch of the caller's play events.
+func (h *PlayHandlers) RecordPlaysHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		api.LogErrorAndRespond(w, "Authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	var req struct {
+		Plays []service.Play `json:"plays"`
+	}
+	if !api.DecodeJSONWithLimit(w, r, &req, maxPlayBatchSize) {
+		return
+	}
+
+	result, err := h.playService.RecordPlays(userID, req.Plays)
+	if err != nil {
+		if errors.Is(err, service.ErrEmptyPlayBatch) || errors.Is(err, service.ErrTooManyPlays) {
+			api.LogErrorWithDetails(w, err.Error(), err, http.StatusBadRequest)
+			return
+		}
+		api.LogErrorWithDetails(w, "Failed to record plays", err, http.StatusInternalServerError)
+		return
+	}
+
+	api.RespondWithJSON(w, http.StatusOK, result)
+}
+
+// GetHistoryHandler handles GET requests for the caller's listening history. The optional "limit"
+// query parameter sets the page size and "before" continues from the next_before of a previous page.
+func (h *PlayHandlers) GetHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		api.LogErrorAndRespond(w, "Authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	query := r.URL.Query()
+	var before uint64
+	var limit int
+	var err error
+	if value := query.Get("before"); value != "" {
+		if before, err = strconv.ParseUint(value, 10, 64); err != nil {
+			api.LogErrorWithDetails(w, "Invalid before", err, http.StatusBadRequest)
+			return
+		}
+	}
+	if value := query.Get("limit"); value != "" {
+		if limit, err = strconv.Atoi(value); err != nil || limit < 0 {
+			api.LogErrorWithDetails(w, "Invalid limit", err, http.StatusBadRequest)
+			return
+		}
+	}
+
+	history, err := h.playService.GetHistory(userID, uint(before), limit)
+	if err != nil {
+		if errors.Is(err, service.ErrPlayEventNotFound) {
+			api.LogErrorWithDetails(w, "Invalid before", err, http.StatusBadRequest)
+			return
+		}
+		api.LogErrorWithDetails(w, "Failed to retrieve listening history", err, http.StatusInternalServerError)
+		return
+	}
+
+	api.RespondWithJSON(w, http.StatusOK, history)
+}
+
+// GetSongPlayCountHandler handles GET requests for the number of times a song was played.
+func (h *PlayHandlers) GetSongPlayCountHandler(w http.ResponseWriter, r *http.Request) {
+	count, err := h.playService.GetSongPlayCount(mux.Vars(r)["songID"])
+	if err != nil {
+		if errors.Is(err, service.ErrSongNotFound) {
+			api.LogErrorWithDetails(w, "Song not found", err, http.StatusNotFound)
+			return
+		}
+		api.LogErrorWithDetails(w, "Failed to retrieve play count", err, http.StatusInternalServerError)
+		return
+	}
+
+	api.RespondWithJSON(w, http.StatusOK, count)
+}
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/kaiohenricunha/go-music-k8s/backend/api"
+	"github.com/kaiohenricunha/go-music-k8s/backend/api/middleware"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/service"
+)
+
+// inviteCollaboratorRequest is the body of the invite endpoint.
+type inviteCollaboratorRequest struct {
+	Username string `json:"username" validate:"required,max=32"`
+	Role     string `json:"role" validate:"required,oneof=editor viewer"`
+}
+
+// InviteCollaboratorHandler handles POST requests from a playlist's owner to invite a user as editor or viewer.
+func (h *PlaylistHandlers) InviteCollaboratorHandler(w http.ResponseWriter, r *http.Request) {
+	playlistID := mux.Vars(r)["playlistID"]
+
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		api.LogErrorAndRespond(w, "Authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	var req inviteCollaboratorRequest
+	if !api.DecodeJSON(w, r, &req) {
+		return
+	}
+
+	collaborator, err := h.playlistService.InviteCollaborator(playlistID, userID, req.Username, req.Role)
+	if err != nil {
+		h.respondWithCollaboratorError(w, "Failed to invite collaborator", err)
+		return
+	}
+
+	api.RespondWithJSON(w, http.StatusCreated, collaborator)
+}
+
+// GetPlaylistCollaboratorsHandler handles GET requests to list a playlist's collaborators and pending invites.
+func (h *PlaylistHandlers) GetPlaylistCollaboratorsHandler(w http.ResponseWriter, r *http.Request) {
+	playlistID := mux.Vars(r)["playlistID"]
+
+	if _, ok := h.authorizePlaylist(w, r, playlistID, service.PermissionView); !ok {
+		return
+	}
+
+	collaborators, err := h.playlistService.GetPlaylistCollaborators(playlistID)
+	if err != nil {
+		h.respondWithCollaboratorError(w, "Failed to retrieve collaborators", err)
+		return
+	}
+
+	api.RespondWithJSON(w, http.StatusOK, collaborators)
+}
+
+// RemoveCollaboratorHandler handles DELETE requests to remove a collaborator. Owners can remove
+// anyone; collaborators can remove themselves to leave the playlist.
+func (h *PlaylistHandlers) RemoveCollaboratorHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	playlistID := vars["playlistID"]
+	collaboratorID, err := strconv.ParseUint(vars["userID"], 10, 64)
+	if err != nil {
+		api.LogErrorWithDetails(w, "Invalid user ID", err, http.StatusBadRequest)
+		return
+	}
+
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		api.LogErrorAndRespond(w, "Authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.playlistService.RemoveCollaborator(playlistID, userID, uint(collaboratorID)); err != nil {
+		h.respondWithCollaboratorError(w, "Failed to remove collaborator", err)
+		return
+	}
+
+	api.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Collaborator removed successfully"})
+}
+
+// AcceptInviteHandler handles POST requests to accept an invite to a playlist.
+func (h *PlaylistHandlers) AcceptInviteHandler(w http.ResponseWriter, r *http.Request) {
+	h.respondToInvite(w, r, true)
+}
+
+// DeclineInviteHandler handles POST requests to decline an invite to a playlist.
+func (h *PlaylistHandlers) DeclineInviteHandler(w http.ResponseWriter, r *http.Request) {
+	h.respondToInvite(w, r, false)
+}
+
+func (h *PlaylistHandlers) respondToInvite(w http.ResponseWriter, r *http.Request, accept bool) {
+	playlistID := mux.Vars(r)["playlistID"]
+
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		api.LogErrorAndRespond(w, "Authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	collaborator, err := h.playlistService.RespondToInvite(playlistID, userID, accept)
+	if err != nil {
+		h.respondWithCollaboratorError(w, "Failed to respond to invite", err)
+		return
+	}
+
+	api.RespondWithJSON(w, http.StatusOK, collaborator)
+}
+
+// GetPendingInvitesHandler handles GET requests to list the caller's unanswered playlist invites.
+func (h *PlaylistHandlers) GetPendingInvitesHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		api.LogErrorAndRespond(w, "Authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	invites, err := h.playlistService.GetPendingInvites(userID)
+	if err != nil {
+		api.LogErrorWithDetails(w, "Failed to retrieve invites", err, http.StatusInternalServerError)
+		return
+	}
+
+	api.RespondWithJSON(w, http.StatusOK, invites)
+}
+
+// respondWithCollaboratorError maps the errors of the collaborator operations to HTTP responses.
+func (h *PlaylistHandlers) respondWithCollaboratorError(w http.ResponseWriter, errMsg string, err error) {
+	switch {
+	case errors.Is(err, service.ErrPlaylistNotFound):
+		api.LogErrorWithDetails(w, "Playlist not found", err, http.StatusNotFound)
+	case errors.Is(err, service.ErrInviteeNotFound):
+		api.LogErrorWithDetails(w, "User not found", err, http.StatusNotFound)
+	case errors.Is(err, service.ErrCollaboratorNotFound), errors.Is(err, service.ErrNoPendingInvite):
+		api.LogErrorWithDetails(w, err.Error(), err, http.StatusNotFound)
+	case errors.Is(err, service.ErrPlaylistForbidden):
+		api.LogErrorWithDetails(w, "Only the playlist owner can manage collaborators", err, http.StatusForbidden)
+	case errors.Is(err, service.ErrInvalidRole), errors.Is(err, service.ErrCannotInviteOwner):
+		api.LogErrorWithDetails(w, err.Error(), err, http.StatusBadRequest)
+	default:
+		api.LogErrorWithDetails(w, errMsg, err, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/kaiohenricunha/go-music-k8s/backend/api"
+	"github.com/kaiohenricunha/go-music-k8s/backend/api/middleware"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/service"
+)
+
+// copyPlaylistRequest is the optional body of the copy playlist endpoint.
+type copyPlaylistRequest struct {
+	Name       string `json:"playlist_name" validate:"max=100"`
+	Visibility string `json:"visibility" validate:"omitempty,oneof=public unlisted private"`
+}
+
+// mergePlaylistsRequest is the body of the merge playlists endpoint.
+type mergePlaylistsRequest struct {
+	PlaylistIDs []uint `json:"playlist_ids" validate:"required,min=2,max=20"`
+	Name        string `json:"playlist_name" validate:"required,max=100"`
+	Visibility  string `json:"visibility" validate:"omitempty,oneof=public unlisted private"`
+	Order       string `json:"order" validate:"omitempty,oneof=sequential interleave"`
+	Dedupe      string `json:"dedupe" validate:"omitempty,oneof=none song_id name_artist"`
+}
+
+// CopyPlaylistHandler handles POST requests to copy a playlist into a new one owned by the caller.
+// The body is optional and may give the name and visibility of the copy.
+func (h *PlaylistHandlers) CopyPlaylistHandler(w http.ResponseWriter, r *http.Request) {
+	playlistID := mux.Vars(r)["playlistID"]
+
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		api.LogErrorAndRespond(w, "Authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	var req copyPlaylistRequest
+	if !api.DecodeOptionalJSON(w, r, &req) {
+		return
+	}
+
+	playlist, err := h.playlistService.CopyPlaylist(playlistID, userID, req.Name, req.Visibility)
+	if err != nil {
+		h.respondWithCopyError(w, "Failed to copy playlist", err)
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/api/v1/playlists/%d", playlist.ID))
+	api.RespondWithJSON(w, http.StatusCreated, playlist)
+}
+
+// MergePlaylistsHandler handles POST requests to create a playlist from the songs of several playlists.
+func (h *PlaylistHandlers) MergePlaylistsHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		api.LogErrorAndRespond(w, "Authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	var req mergePlaylistsRequest
+	if !api.DecodeJSON(w, r, &req) {
+		return
+	}
+
+	playlist, err := h.playlistService.MergePlaylists(userID, service.PlaylistMerge{
+		PlaylistIDs: req.PlaylistIDs,
+		Name:        req.Name,
+		Visibility:  req.Visibility,
+		Order:       req.Order,
+		Dedupe:      req.Dedupe,
+	})
+	if err != nil {
+		h.respondWithCopyError(w, "Failed to merge playlists", err)
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/api/v1/playlists/%d", playlist.ID))
+	api.RespondWithJSON(w, http.StatusCreated, playlist)
+}
+
+// DedupePlaylistHandler handles POST requests to remove duplicate songs from a playlist. The
+// "by" query parameter is song_id (the default) or name_artist.
+func (h *PlaylistHandlers) DedupePlaylistHandler(w http.ResponseWriter, r *http.Request) {
+	playlistID := mux.Vars(r)["playlistID"]
+
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		api.LogErrorAndRespond(w, "Authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	result, err := h.playlistService.DedupePlaylist(playlistID, userID, r.URL.Query().Get("by"))
+	if err != nil {
+		h.respondWithCopyError(w, "Failed to remove duplicate songs", err)
+		return
+	}
+
+	api.RespondWithJSON(w, http.StatusOK, result)
+}
+
+// respondWithCopyError maps the errors of the copy, merge and dedupe operations to HTTP responses.
+func (h *PlaylistHandlers) respondWithCopyError(w http.ResponseWriter, errMsg string, err error) {
+	switch {
+	case errors.Is(err, service.ErrPlaylistNotFound):
+		api.LogErrorWithDetails(w, "Playlist not found", err, http.StatusNotFound)
+	case errors.Is(err, service.ErrPlaylistForbidden):
+		api.LogErrorWithDetails(w, "You do not have permission to access this playlist", err, http.StatusForbidden)
+	case errors.Is(err, service.ErrSmartPlaylistReadOnly):
+		api.LogErrorWithDetails(w, err.Error(), err, http.StatusConflict)
+	case errors.Is(err, service.ErrInvalidPlaylistName), errors.Is(err, service.ErrInvalidVisibility),
+		errors.Is(err, service.ErrInvalidMergeSources), errors.Is(err, service.ErrInvalidMergeOrder),
+		errors.Is(err, service.ErrInvalidDedupeMode):
+		api.LogErrorWithDetails(w, err.Error(), err, http.StatusBadRequest)
+	default:
+		api.LogErrorWithDetails(w, errMsg, err, http.StatusInternalServerError)
+	}
+}
@@ -1,30 +1,110 @@
 package handlers
 
 import (
+	"bufio"
 	"errors"
+	"fmt"
+	"log"
+	"mime"
 	"net/http"
+	"path"
+	"strconv"
+	"strings"
 
 	"github.com/gorilla/mux"
 
 	"github.com/kaiohenricunha/go-music-k8s/backend/api"
+	"github.com/kaiohenricunha/go-music-k8s/backend/api/middleware"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/model"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/playlistfile"
 	"github.com/kaiohenricunha/go-music-k8s/backend/internal/service"
 )
 
 // PlaylistHandlers encapsulates handlers for dealing with playlists.
 type PlaylistHandlers struct {
-	playlistService service.PlaylistService
+	playlistService       service.PlaylistService
+	playlistImportService service.PlaylistImportService
 }
 
 // NewPlaylistHandlers creates an instance of PlaylistHandlers.
-func NewPlaylistHandlers(playlistService service.PlaylistService) *PlaylistHandlers {
+func NewPlaylistHandlers(playlistService service.PlaylistService, playlistImportService service.PlaylistImportService) *PlaylistHandlers {
 	return &PlaylistHandlers{
-		playlistService: playlistService,
+		playlistService:       playlistService,
+		playlistImportService: playlistImportService,
 	}
 }
 
-// GetAllPlaylistsHandler handles GET requests to list all playlists.
+// authorizePlaylist checks that the caller has the permission on the playlist, writing the error
+// response and returning false when they do not.
+func (h *PlaylistHandlers) authorizePlaylist(w http.ResponseWriter, r *http.Request, playlistID string, permission service.PlaylistPermission) (uint, bool) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		api.LogErrorAndRespond(w, "Authorization required", http.StatusUnauthorized)
+		return 0, false
+	}
+
+	if err := h.playlistService.AuthorizePlaylist(playlistID, userID, permission); err != nil {
+		switch {
+		case errors.Is(err, service.ErrPlaylistNotFound):
+			api.LogErrorWithDetails(w, "Playlist not found", err, http.StatusNotFound)
+		case errors.Is(err, service.ErrPlaylistForbidden):
+			api.LogErrorWithDetails(w, "You do not have permission to change this playlist", err, http.StatusForbidden)
+		case errors.Is(err, service.ErrSmartPlaylistReadOnly):
+			api.LogErrorWithDetails(w, err.Error(), err, http.StatusConflict)
+		default:
+			api.LogErrorWithDetails(w, "Failed to check playlist permissions", err, http.StatusInternalServerError)
+		}
+		return 0, false
+	}
+	return userID, true
+}
+
+// createPlaylistRequest is the body of the create playlist endpoint.
+type createPlaylistRequest struct {
+	Name             string `json:"playlist_name" validate:"required,max=100"`
+	PlaylistImageURL string `json:"playlist_image_url" validate:"omitempty,http_url,max=2048"`
+	Visibility       string `json:"visibility" validate:"omitempty,oneof=public unlisted private"`
+	// SmartRules makes the playlist a smart playlist whose songs are computed from the rules.
+	SmartRules *model.SmartRules `json:"smart_rules"`
+}
+
+// CreatePlaylistHandler handles POST requests to create a playlist owned by the caller. Static
+// playlists start empty; smart playlists start with the songs their rules select.
+func (h *PlaylistHandlers) CreatePlaylistHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		api.LogErrorAndRespond(w, "Authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	var req createPlaylistRequest
+	if !api.DecodeJSON(w, r, &req) {
+		return
+	}
+
+	playlist := &model.Playlist{Name: req.Name, UserID: userID, PlaylistImageURL: req.PlaylistImageURL, Visibility: req.Visibility, SmartRules: req.SmartRules}
+	if err := h.playlistService.CreatePlaylist(playlist); err != nil {
+		if errors.Is(err, service.ErrInvalidPlaylistName) || errors.Is(err, service.ErrInvalidVisibility) || errors.Is(err, service.ErrInvalidSmartRules) {
+			api.LogErrorWithDetails(w, err.Error(), err, http.StatusBadRequest)
+			return
+		}
+		api.LogErrorWithDetails(w, "Failed to create playlist", err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/api/v1/playlists/%d", playlist.ID))
+	api.RespondWithJSON(w, http.StatusCreated, playlist)
+}
+
+// GetAllPlaylistsHandler handles GET requests to list the playlists the caller may see.
 func (h *PlaylistHandlers) GetAllPlaylistsHandler(w http.ResponseWriter, r *http.Request) {
-	playlists, err := h.playlistService.GetAllPlaylists()
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		api.LogErrorAndRespond(w, "Authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	playlists, err := h.playlistService.GetAllPlaylists(userID)
 	if err != nil {
 		api.LogErrorWithDetails(w, "Failed to retrieve playlists", err, http.StatusInternalServerError)
 		return
@@ -38,6 +118,10 @@ func (h *PlaylistHandlers) GetPlaylistByIDHandler(w http.ResponseWriter, r *http
 	vars := mux.Vars(r)
 	playlistID := vars["playlistID"]
 
+	if _, ok := h.authorizePlaylist(w, r, playlistID, service.PermissionView); !ok {
+		return
+	}
+
 	playlist, err := h.playlistService.GetPlaylistByID(playlistID)
 	if err != nil {
 		api.LogErrorWithDetails(w, "Failed to retrieve playlist", err, http.StatusInternalServerError)
@@ -48,23 +132,43 @@ func (h *PlaylistHandlers) GetPlaylistByIDHandler(w http.ResponseWriter, r *http
 }
 
 // AddSongToPlaylistHandler handles POST requests to add a song to a playlist.
+// The optional "position" query parameter inserts the song at that zero-based index instead of appending it.
 func (h *PlaylistHandlers) AddSongToPlaylistHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	playlistID := vars["playlistID"]
-	songID := vars["songID"] // Assuming the song ID is passed as a path parameter or you could choose to receive it in the request body.
+	songID := vars["songID"]
+
+	userID, ok := h.authorizePlaylist(w, r, playlistID, service.PermissionEditSongs)
+	if !ok {
+		return
+	}
+
+	position := -1
+	if value := r.URL.Query().Get("position"); value != "" {
+		var err error
+		position, err = strconv.Atoi(value)
+		if err != nil || position < 0 {
+			api.LogErrorWithDetails(w, "Invalid position", err, http.StatusBadRequest)
+			return
+		}
+	}
 
 	// Call the service method to add the song to the playlist
-	err := h.playlistService.AddSongToPlaylist(playlistID, songID)
+	entry, err := h.playlistService.AddSongToPlaylist(playlistID, songID, position, userID)
 	if err != nil {
 		if errors.Is(err, service.ErrPlaylistNotFound) || errors.Is(err, service.ErrSongNotFound) {
 			api.LogErrorWithDetails(w, "Playlist or song not found", err, http.StatusNotFound)
 			return
 		}
+		if errors.Is(err, service.ErrInvalidPosition) {
+			api.LogErrorWithDetails(w, "Position is out of range", err, http.StatusBadRequest)
+			return
+		}
 		api.LogErrorWithDetails(w, "Failed to add song to playlist", err, http.StatusInternalServerError)
 		return
 	}
 
-	api.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Song added to playlist successfully"})
+	api.RespondWithJSON(w, http.StatusOK, map[string]interface{}{"message": "Song added to playlist successfully", "entry": entry})
 }
 
 // RemoveSongFromPlaylistHandler handles DELETE requests to remove a song from a playlist.
@@ -73,8 +177,13 @@ func (h *PlaylistHandlers) RemoveSongFromPlaylistHandler(w http.ResponseWriter,
 	playlistID := vars["playlistID"]
 	songID := vars["songID"] // Assuming the song ID is passed as a path parameter.
 
+	userID, ok := h.authorizePlaylist(w, r, playlistID, service.PermissionEditSongs)
+	if !ok {
+		return
+	}
+
 	// Call the service method to remove the song from the playlist
-	err := h.playlistService.RemoveSongFromPlaylist(playlistID, songID)
+	err := h.playlistService.RemoveSongFromPlaylist(playlistID, songID, userID)
 	if err != nil {
 		if errors.Is(err, service.ErrPlaylistNotFound) || errors.Is(err, service.ErrSongNotFound) {
 			api.LogErrorWithDetails(w, "Playlist or song not found", err, http.StatusNotFound)
@@ -86,3 +195,323 @@ func (h *PlaylistHandlers) RemoveSongFromPlaylistHandler(w http.ResponseWriter,
 
 	api.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Song removed from playlist successfully"})
 }
+
+// songRefsRequest is the body of the bulk add and remove endpoints.
+type songRefsRequest struct {
+	Songs []model.SongRef `json:"songs" validate:"required,max=100"`
+}
+
+// AddSongsToPlaylistHandler handles POST requests to add several songs to a playlist at once.
+func (h *PlaylistHandlers) AddSongsToPlaylistHandler(w http.ResponseWriter, r *http.Request) {
+	playlistID := mux.Vars(r)["playlistID"]
+
+	userID, ok := h.authorizePlaylist(w, r, playlistID, service.PermissionEditSongs)
+	if !ok {
+		return
+	}
+
+	var req songRefsRequest
+	if !api.DecodeJSON(w, r, &req) {
+		return
+	}
+
+	results, err := h.playlistService.AddSongsToPlaylist(playlistID, req.Songs, userID)
+	if err != nil {
+		h.respondWithBulkError(w, "Failed to add songs to playlist", err)
+		return
+	}
+
+	api.RespondWithJSON(w, http.StatusOK, map[string]interface{}{"results": results})
+}
+
+// RemoveSongsFromPlaylistHandler handles DELETE requests to remove several songs from a playlist at once.
+func (h *PlaylistHandlers) RemoveSongsFromPlaylistHandler(w http.ResponseWriter, r *http.Request) {
+	playlistID := mux.Vars(r)["playlistID"]
+
+	userID, ok := h.authorizePlaylist(w, r, playlistID, service.PermissionEditSongs)
+	if !ok {
+		return
+	}
+
+	var req songRefsRequest
+	if !api.DecodeJSON(w, r, &req) {
+		return
+	}
+
+	results, err := h.playlistService.RemoveSongsFromPlaylist(playlistID, req.Songs, userID)
+	if err != nil {
+		h.respondWithBulkError(w, "Failed to remove songs from playlist", err)
+		return
+	}
+
+	api.RespondWithJSON(w, http.StatusOK, map[string]interface{}{"results": results})
+}
+
+// respondWithBulkError maps the errors of the bulk playlist operations to HTTP responses.
+func (h *PlaylistHandlers) respondWithBulkError(w http.ResponseWriter, errMsg string, err error) {
+	switch {
+	case errors.Is(err, service.ErrPlaylistNotFound):
+		api.LogErrorWithDetails(w, "Playlist not found", err, http.StatusNotFound)
+	case errors.Is(err, service.ErrInvalidSongRefs), errors.Is(err, service.ErrTooManySongRefs):
+		api.LogErrorWithDetails(w, err.Error(), err, http.StatusBadRequest)
+	default:
+		api.LogErrorWithDetails(w, errMsg, err, http.StatusInternalServerError)
+	}
+}
+
+// RemovePlaylistEntryHandler handles DELETE requests to remove a single entry from a playlist.
+func (h *PlaylistHandlers) RemovePlaylistEntryHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	playlistID := vars["playlistID"]
+	entryID, err := strconv.ParseUint(vars["entryID"], 10, 64)
+	if err != nil {
+		api.LogErrorWithDetails(w, "Invalid entry ID", err, http.StatusBadRequest)
+		return
+	}
+
+	userID, ok := h.authorizePlaylist(w, r, playlistID, service.PermissionEditSongs)
+	if !ok {
+		return
+	}
+
+	err = h.playlistService.RemovePlaylistEntry(playlistID, uint(entryID), userID)
+	if err != nil {
+		if errors.Is(err, service.ErrPlaylistNotFound) || errors.Is(err, service.ErrPlaylistEntryNotFound) {
+			api.LogErrorWithDetails(w, "Playlist or entry not found", err, http.StatusNotFound)
+			return
+		}
+		api.LogErrorWithDetails(w, "Failed to remove entry from playlist", err, http.StatusInternalServerError)
+		return
+	}
+
+	api.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Entry removed from playlist successfully"})
+}
+
+type movePlaylistEntriesRequest struct {
+	RangeStart   int `json:"range_start" validate:"gte=0"`
+	RangeLength  int `json:"range_length" validate:"gte=0"`
+	InsertBefore int `json:"insert_before" validate:"gte=0"`
+}
+
+// MovePlaylistEntriesHandler handles POST requests to move a range of entries within a playlist.
+func (h *PlaylistHandlers) MovePlaylistEntriesHandler(w http.ResponseWriter, r *http.Request) {
+	playlistID := mux.Vars(r)["playlistID"]
+
+	userID, ok := h.authorizePlaylist(w, r, playlistID, service.PermissionEditSongs)
+	if !ok {
+		return
+	}
+
+	var req movePlaylistEntriesRequest
+	if !api.DecodeJSON(w, r, &req) {
+		return
+	}
+
+	move := service.PlaylistMove{RangeStart: req.RangeStart, RangeLength: req.RangeLength, InsertBefore: req.InsertBefore}
+	err := h.playlistService.MovePlaylistEntries(playlistID, move, userID)
+	if err != nil {
+		if errors.Is(err, service.ErrPlaylistNotFound) {
+			api.LogErrorWithDetails(w, "Playlist not found", err, http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, service.ErrInvalidPosition) {
+			api.LogErrorWithDetails(w, "Range or position is out of bounds", err, http.StatusBadRequest)
+			return
+		}
+		api.LogErrorWithDetails(w, "Failed to move playlist entries", err, http.StatusInternalServerError)
+		return
+	}
+
+	h.respondWithPlaylist(w, playlistID)
+}
+
+// ReplacePlaylistSongsHandler handles PUT requests to replace the contents of a playlist.
+func (h *PlaylistHandlers) ReplacePlaylistSongsHandler(w http.ResponseWriter, r *http.Request) {
+	playlistID := mux.Vars(r)["playlistID"]
+
+	userID, ok := h.authorizePlaylist(w, r, playlistID, service.PermissionEditSongs)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		SongIDs []uint `json:"song_ids" validate:"dive,gt=0"`
+	}
+	if !api.DecodeJSON(w, r, &req) {
+		return
+	}
+
+	err := h.playlistService.ReplacePlaylistSongs(playlistID, req.SongIDs, userID)
+	if err != nil {
+		if errors.Is(err, service.ErrPlaylistNotFound) || errors.Is(err, service.ErrSongNotFound) {
+			api.LogErrorWithDetails(w, "Playlist or song not found", err, http.StatusNotFound)
+			return
+		}
+		api.LogErrorWithDetails(w, "Failed to replace playlist songs", err, http.StatusInternalServerError)
+		return
+	}
+
+	h.respondWithPlaylist(w, playlistID)
+}
+
+// respondWithPlaylist writes the current state of a playlist after it was modified.
+func (h *PlaylistHandlers) respondWithPlaylist(w http.ResponseWriter, playlistID string) {
+	playlist, err := h.playlistService.GetPlaylistByID(playlistID)
+	if err != nil {
+		api.LogErrorWithDetails(w, "Failed to retrieve playlist", err, http.StatusInternalServerError)
+		return
+	}
+
+	api.RespondWithJSON(w, http.StatusOK, playlist)
+}
+
+// ExportPlaylistHandler handles GET requests to download a playlist as an M3U, XSPF, CSV or JSON file.
+func (h *PlaylistHandlers) ExportPlaylistHandler(w http.ResponseWriter, r *http.Request) {
+	playlistID := mux.Vars(r)["playlistID"]
+
+	formatName := r.URL.Query().Get("format")
+	if formatName == "" {
+		formatName = "json"
+	}
+	format, err := playlistfile.LookupFormat(formatName)
+	if err != nil {
+		api.LogErrorWithDetails(w, "Format must be one of m3u, xspf, csv or json", err, http.StatusBadRequest)
+		return
+	}
+
+	if _, ok := h.authorizePlaylist(w, r, playlistID, service.PermissionView); !ok {
+		return
+	}
+
+	playlist, err := h.playlistService.GetPlaylistByID(playlistID)
+	if err != nil {
+		if errors.Is(err, service.ErrPlaylistNotFound) {
+			api.LogErrorWithDetails(w, "Playlist not found", err, http.StatusNotFound)
+			return
+		}
+		api.LogErrorWithDetails(w, "Failed to retrieve playlist", err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", format.ContentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": format.Filename(playlist)}))
+	if err := format.Write(w, playlist); err != nil {
+		// The status line has already been sent, so the error can only be logged.
+		log.Printf("Failed to export playlist %s as %s: %v", playlistID, format.Name, err)
+	}
+}
+
+type importPlaylistRequest struct {
+	Source string `json:"source" validate:"required,max=512"`
+	Type   string `json:"type" validate:"omitempty,oneof=playlist album"`
+	Name   string `json:"name" validate:"max=100"`
+}
+
+// ImportPlaylistHandler handles POST requests to import a Spotify playlist or album as a new playlist.
+// The import runs in the background; the response points to the job to poll.
+func (h *PlaylistHandlers) ImportPlaylistHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		api.LogErrorAndRespond(w, "Authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	var req importPlaylistRequest
+	if !api.DecodeJSON(w, r, &req) {
+		return
+	}
+
+	job, err := h.playlistImportService.StartImport(userID, service.PlaylistImportRequest{Source: req.Source, Type: req.Type, Name: req.Name})
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidImportSource) {
+			api.LogErrorWithDetails(w, "Invalid Spotify playlist or album", err, http.StatusBadRequest)
+			return
+		}
+		api.LogErrorWithDetails(w, "Failed to start playlist import", err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/api/v1/playlists/import/%s", job.ID))
+	api.RespondWithJSON(w, http.StatusAccepted, job)
+}
+
+// GetImportJobHandler handles GET requests to poll the status of a playlist import.
+func (h *PlaylistHandlers) GetImportJobHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		api.LogErrorAndRespond(w, "Authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	job, err := h.playlistImportService.GetImportJob(userID, mux.Vars(r)["jobID"])
+	if err != nil {
+		if errors.Is(err, service.ErrImportJobNotFound) {
+			api.LogErrorWithDetails(w, "Import job not found", err, http.StatusNotFound)
+			return
+		}
+		api.LogErrorWithDetails(w, "Failed to retrieve import job", err, http.StatusInternalServerError)
+		return
+	}
+
+	api.RespondWithJSON(w, http.StatusOK, job)
+}
+
+// maxPlaylistFileSize limits the size of uploaded playlist files.
+const maxPlaylistFileSize = 1 << 20
+
+// ImportPlaylistFileHandler handles multipart POST requests that upload an M3U, XSPF, CSV or JSON
+// playlist file. The optional "format" field overrides detection from the file's name and content,
+// and "name" overrides the playlist name taken from the file name.
+func (h *PlaylistHandlers) ImportPlaylistFileHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		api.LogErrorAndRespond(w, "Authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxPlaylistFileSize)
+	if err := r.ParseMultipartForm(maxPlaylistFileSize); err != nil {
+		api.LogErrorWithDetails(w, "Invalid multipart upload", err, http.StatusBadRequest)
+		return
+	}
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		api.LogErrorWithDetails(w, "Playlist file is required", err, http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	content := bufio.NewReader(file)
+	var format *playlistfile.Format
+	if name := r.FormValue("format"); name != "" {
+		format, err = playlistfile.LookupFormat(name)
+	} else {
+		head, _ := content.Peek(512)
+		format, err = playlistfile.DetectFormat(header.Filename, head)
+	}
+	if err != nil {
+		api.LogErrorWithDetails(w, "Unsupported playlist format", err, http.StatusBadRequest)
+		return
+	}
+
+	name := strings.TrimSpace(r.FormValue("name"))
+	if name == "" {
+		name = strings.TrimSuffix(path.Base(header.Filename), path.Ext(header.Filename))
+	}
+
+	report, err := h.playlistImportService.ImportFile(userID, name, format, content)
+	if err != nil {
+		switch {
+		case errors.Is(err, playlistfile.ErrInvalidFile),
+			errors.Is(err, service.ErrEmptyPlaylistFile),
+			errors.Is(err, service.ErrTooManyFileEntries):
+			api.LogErrorWithDetails(w, "Invalid playlist file", err, http.StatusBadRequest)
+		default:
+			api.LogErrorWithDetails(w, "Failed to import playlist file", err, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/api/v1/playlists/%d", report.PlaylistID))
+	api.RespondWithJSON(w, http.StatusCreated, report)
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/kaiohenricunha/go-music-k8s/backend/api"
+	"github.com/kaiohenricunha/go-music-k8s/backend/api/middleware"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/service"
+)
+
+// GetPlaylistHistoryHandler handles GET requests for the revisions of a playlist. The optional
+// "limit" query parameter sets the page size and "before" continues from the next_before of a
+// previous page.
+func (h *PlaylistHandlers) GetPlaylistHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	playlistID := mux.Vars(r)["playlistID"]
+
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		api.LogErrorAndRespond(w, "Authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	query := r.URL.Query()
+	var before, limit int
+	var err error
+	if value := query.Get("before"); value != "" {
+		if before, err = strconv.Atoi(value); err != nil || before < 0 {
+			api.LogErrorWithDetails(w, "Invalid before", err, http.StatusBadRequest)
+			return
+		}
+	}
+	if value := query.Get("limit"); value != "" {
+		if limit, err = strconv.Atoi(value); err != nil || limit < 0 {
+			api.LogErrorWithDetails(w, "Invalid limit", err, http.StatusBadRequest)
+			return
+		}
+	}
+
+	history, err := h.playlistService.GetPlaylistHistory(playlistID, userID, before, limit)
+	if err != nil {
+		h.respondWithHistoryError(w, "Failed to retrieve playlist history", err)
+		return
+	}
+
+	api.RespondWithJSON(w, http.StatusOK, history)
+}
+
+// DiffPlaylistRevisionsHandler handles GET requests comparing the revisions of a playlist given by
+// the "from" and "to" query parameters.
+func (h *PlaylistHandlers) DiffPlaylistRevisionsHandler(w http.ResponseWriter, r *http.Request) {
+	playlistID := mux.Vars(r)["playlistID"]
+
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		api.LogErrorAndRespond(w, "Authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	query := r.URL.Query()
+	from, err := strconv.Atoi(query.Get("from"))
+	if err != nil {
+		api.LogErrorWithDetails(w, "Invalid from revision", err, http.StatusBadRequest)
+		return
+	}
+	to, err := strconv.Atoi(query.Get("to"))
+	if err != nil {
+		api.LogErrorWithDetails(w, "Invalid to revision", err, http.StatusBadRequest)
+		return
+	}
+
+	diff, err := h.playlistService.DiffPlaylistRevisions(playlistID, userID, from, to)
+	if err != nil {
+		h.respondWithHistoryError(w, "Failed to compare playlist revisions", err)
+		return
+	}
+
+	api.RespondWithJSON(w, http.StatusOK, diff)
+}
+
+// RestorePlaylistRevisionHandler handles POST requests to roll a playlist's songs back to a revision.
+func (h *PlaylistHandlers) RestorePlaylistRevisionHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	playlistID := vars["playlistID"]
+
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		api.LogErrorAndRespond(w, "Authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	revision, err := strconv.Atoi(vars["revision"])
+	if err != nil {
+		api.LogErrorWithDetails(w, "Revision not found", err, http.StatusNotFound)
+		return
+	}
+
+	playlist, err := h.playlistService.RestorePlaylistRevision(playlistID, userID, revision)
+	if err != nil {
+		h.respondWithHistoryError(w, "Failed to restore playlist revision", err)
+		return
+	}
+
+	api.RespondWithJSON(w, http.StatusOK, playlist)
+}
+
+// respondWithHistoryError maps the errors of the history operations to HTTP responses.
+func (h *PlaylistHandlers) respondWithHistoryError(w http.ResponseWriter, errMsg string, err error) {
+	switch {
+	case errors.Is(err, service.ErrPlaylistNotFound):
+		api.LogErrorWithDetails(w, "Playlist not found", err, http.StatusNotFound)
+	case errors.Is(err, service.ErrRevisionNotFound):
+		api.LogErrorWithDetails(w, "Revision not found", err, http.StatusNotFound)
+	case errors.Is(err, service.ErrPlaylistForbidden):
+		api.LogErrorWithDetails(w, "You do not have permission to change this playlist", err, http.StatusForbidden)
+	case errors.Is(err, service.ErrSmartPlaylistReadOnly):
+		api.LogErrorWithDetails(w, err.Error(), err, http.StatusConflict)
+	default:
+		api.LogErrorWithDetails(w, errMsg, err, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/kaiohenricunha/go-music-k8s/backend/api"
+	"github.com/kaiohenricunha/go-music-k8s/backend/api/middleware"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/model"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/service"
+)
+
+// playlistSharingResponse describes who can see a playlist and, when it is unlisted, its share link.
+type playlistSharingResponse struct {
+	PlaylistID uint   `json:"playlist_id"`
+	Visibility string `json:"visibility"`
+	ShareToken string `json:"share_token,omitempty"`
+	ShareURL   string `json:"share_url,omitempty"`
+}
+
+// SetPlaylistVisibilityHandler handles PUT requests from a playlist's owner to make it public, unlisted or private.
+func (h *PlaylistHandlers) SetPlaylistVisibilityHandler(w http.ResponseWriter, r *http.Request) {
+	playlistID := mux.Vars(r)["playlistID"]
+
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		api.LogErrorAndRespond(w, "Authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	var req struct {
+		Visibility string `json:"visibility" validate:"required,oneof=public unlisted private"`
+	}
+	if !api.DecodeJSON(w, r, &req) {
+		return
+	}
+
+	playlist, err := h.playlistService.SetPlaylistVisibility(playlistID, userID, req.Visibility)
+	if err != nil {
+		h.respondWithSharingError(w, "Failed to change playlist visibility", err)
+		return
+	}
+
+	h.respondWithSharing(w, playlist)
+}
+
+// RotateShareLinkHandler handles POST requests to replace the share link of an unlisted playlist.
+func (h *PlaylistHandlers) RotateShareLinkHandler(w http.ResponseWriter, r *http.Request) {
+	playlistID := mux.Vars(r)["playlistID"]
+
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		api.LogErrorAndRespond(w, "Authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	playlist, err := h.playlistService.RotateShareToken(playlistID, userID)
+	if err != nil {
+		h.respondWithSharingError(w, "Failed to rotate share link", err)
+		return
+	}
+
+	h.respondWithSharing(w, playlist)
+}
+
+// GetSharedPlaylistHandler handles GET requests for an unlisted playlist by its share token.
+// It does not require authentication, so that links can be shared with anyone, and responds with
+// a service.SharedPlaylist, which has no IDs.
+func (h *PlaylistHandlers) GetSharedPlaylistHandler(w http.ResponseWriter, r *http.Request) {
+	playlist, err := h.playlistService.GetSharedPlaylist(mux.Vars(r)["token"])
+	if err != nil {
+		if errors.Is(err, service.ErrPlaylistNotFound) {
+			api.LogErrorWithDetails(w, "Playlist not found", err, http.StatusNotFound)
+			return
+		}
+		api.LogErrorWithDetails(w, "Failed to retrieve playlist", err, http.StatusInternalServerError)
+		return
+	}
+
+	api.RespondWithJSON(w, http.StatusOK, playlist)
+}
+
+func (h *PlaylistHandlers) respondWithSharing(w http.ResponseWriter, playlist *model.Playlist) {
+	resp := playlistSharingResponse{PlaylistID: playlist.ID, Visibility: playlist.Visibility}
+	if playlist.ShareToken != nil {
+		resp.ShareToken = *playlist.ShareToken
+		resp.ShareURL = "/api/v1/shared/playlists/" + *playlist.ShareToken
+	}
+	api.RespondWithJSON(w, http.StatusOK, resp)
+}
+
+// respondWithSharingError maps the errors of the visibility operations to HTTP responses.
+func (h *PlaylistHandlers) respondWithSharingError(w http.ResponseWriter, errMsg string, err error) {
+	switch {
+	case errors.Is(err, service.ErrPlaylistNotFound):
+		api.LogErrorWithDetails(w, "Playlist not found", err, http.StatusNotFound)
+	case errors.Is(err, service.ErrPlaylistForbidden):
+		api.LogErrorWithDetails(w, "Only the playlist owner can change its visibility", err, http.StatusForbidden)
+	case errors.Is(err, service.ErrInvalidVisibility), errors.Is(err, service.ErrPlaylistNotShared):
+		api.LogErrorWithDetails(w, err.Error(), err, http.StatusBadRequest)
+	default:
+		api.LogErrorWithDetails(w, errMsg, err, http.StatusInternalServerError)
+	}
+}
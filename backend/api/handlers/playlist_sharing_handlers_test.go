@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/dao/mocks"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/model"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/service"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestGetSharedPlaylistHidesIDs(t *testing.T) {
+	mockDAO := new(mocks.MusicDAO)
+	h := NewPlaylistHandlers(service.NewPlaylistService(mockDAO), nil)
+	token := "secret-share-token"
+	song := model.Song{Model: gorm.Model{ID: 5}, SpotifyID: "sp1", Name: "Song", Artist: "Artist"}
+	mockDAO.On("GetPlaylistByShareToken", token).Return(&model.Playlist{
+		Model: gorm.Model{ID: 3}, Name: "Road Trip", UserID: 10, Visibility: model.VisibilityUnlisted, ShareToken: &token,
+		Entries: []model.PlaylistEntry{{ID: 8, PlaylistID: 3, SongID: 5, Song: song}},
+		Songs:   []model.Song{song},
+		Ratings: []model.Rating{{Model: gorm.Model{ID: 9}, PlaylistID: "3", UserID: "11", Score: 4}},
+	}, nil)
+	mockDAO.On("GetUserByID", uint(10)).Return(&model.User{Model: gorm.Model{ID: 10}, Username: "alice", Email: "alice@example.com"}, nil)
+
+	w := httptest.NewRecorder()
+	r := mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/api/v1/shared/playlists/"+token, nil), map[string]string{"token": token})
+	h.GetSharedPlaylistHandler(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var body map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "Road Trip", body["playlist_name"])
+	assert.Equal(t, "alice", body["owner"])
+	assert.Equal(t, 4.0, body["average_rating"])
+	assert.Equal(t, 1.0, body["rating_count"])
+	for _, field := range []string{"ID", "id", "user_id", "share_token", "entries", "ratings", "visibility"} {
+		assert.NotContains(t, body, field)
+	}
+	songs := body["songs"].([]interface{})
+	if assert.Len(t, songs, 1) {
+		assert.NotContains(t, songs[0], "ID")
+		assert.NotContains(t, songs[0], "id")
+	}
+	for _, leaked := range []string{token, `"playlist_id"`, `"user_id"`, "alice@example.com"} {
+		assert.NotContains(t, w.Body.String(), leaked)
+	}
+}
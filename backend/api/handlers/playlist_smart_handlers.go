@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/kaiohenricunha/go-music-k8s/backend/api"
+	"github.com/kaiohenricunha/go-music-k8s/backend/api/middleware"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/model"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/service"
+)
+
+// SetSmartRulesHandler handles PUT requests replacing the rules of a playlist. A null
+// "smart_rules" turns a smart playlist back into a static one.
+func (h *PlaylistHandlers) SetSmartRulesHandler(w http.ResponseWriter, r *http.Request) {
+	playlistID := mux.Vars(r)["playlistID"]
+
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		api.LogErrorAndRespond(w, "Authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	var req struct {
+		SmartRules *model.SmartRules `json:"smart_rules"`
+	}
+	if !api.DecodeJSON(w, r, &req) {
+		return
+	}
+
+	playlist, err := h.playlistService.SetSmartRules(playlistID, userID, req.SmartRules)
+	if err != nil {
+		h.respondWithSmartPlaylistError(w, "Failed to update smart playlist rules", err)
+		return
+	}
+
+	api.RespondWithJSON(w, http.StatusOK, playlist)
+}
+
+// RefreshSmartPlaylistHandler handles POST requests to recompute the songs of a smart playlist now.
+func (h *PlaylistHandlers) RefreshSmartPlaylistHandler(w http.ResponseWriter, r *http.Request) {
+	playlistID := mux.Vars(r)["playlistID"]
+
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		api.LogErrorAndRespond(w, "Authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	playlist, err := h.playlistService.RefreshSmartPlaylist(playlistID, userID)
+	if err != nil {
+		h.respondWithSmartPlaylistError(w, "Failed to refresh smart playlist", err)
+		return
+	}
+
+	api.RespondWithJSON(w, http.StatusOK, playlist)
+}
+
+// respondWithSmartPlaylistError maps the errors of the smart playlist operations to HTTP responses.
+func (h *PlaylistHandlers) respondWithSmartPlaylistError(w http.ResponseWriter, errMsg string, err error) {
+	switch {
+	case errors.Is(err, service.ErrPlaylistNotFound):
+		api.LogErrorWithDetails(w, "Playlist not found", err, http.StatusNotFound)
+	case errors.Is(err, service.ErrPlaylistForbidden):
+		api.LogErrorWithDetails(w, "You do not have permission to change this playlist", err, http.StatusForbidden)
+	case errors.Is(err, service.ErrInvalidSmartRules):
+		api.LogErrorWithDetails(w, err.Error(), err, http.StatusBadRequest)
+	case errors.Is(err, service.ErrNotSmartPlaylist):
+		api.LogErrorWithDetails(w, err.Error(), err, http.StatusConflict)
+	default:
+		api.LogErrorWithDetails(w, errMsg, err, http.StatusInternalServerError)
+	}
+}
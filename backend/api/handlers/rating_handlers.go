@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/kaiohenricunha/go-music-k8s/backend/api"
+	"github.com/kaiohenricunha/go-music-k8s/backend/api/middleware"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/service"
+)
+
+// RatingHandlers encapsulates handlers for rating playlists.
+type RatingHandlers struct {
+	ratingService service.RatingService
+}
+
+// NewRatingHandlers creates an instance of RatingHandlers.
+func NewRatingHandlers(ratingService service.RatingService) *RatingHandlers {
+	return &RatingHandlers{ratingService: ratingService}
+}
+
+// RatePlaylistHandler handles PUT requests to set the caller's score for a playlist.
+func (h *RatingHandlers) RatePlaylistHandler(w http.ResponseWriter, r *http.Request) {
+	playlistID := mux.Vars(r)["playlistID"]
+
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		api.LogErrorAndRespond(w, "Authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	var req struct {
+		Score int `json:"score" validate:"gte=1,lte=5"`
+	}
+	if !api.DecodeJSON(w, r, &req) {
+		return
+	}
+
+	rating, err := h.ratingService.RatePlaylist(playlistID, userID, req.Score)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrPlaylistNotFound):
+			api.LogErrorWithDetails(w, "Playlist not found", err, http.StatusNotFound)
+		case errors.Is(err, service.ErrInvalidScore):
+			api.LogErrorWithDetails(w, err.Error(), err, http.StatusBadRequest)
+		default:
+			api.LogErrorWithDetails(w, "Failed to rate playlist", err, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	api.RespondWithJSON(w, http.StatusOK, rating)
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/kaiohenricunha/go-music-k8s/backend/api"
+	"github.com/kaiohenricunha/go-music-k8s/backend/api/middleware"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/service"
+)
+
+// RecommendationHandlers encapsulates handlers for song recommendations.
+type RecommendationHandlers struct {
+	recommendationService service.RecommendationService
+}
+
+// NewRecommendationHandlers creates an instance of RecommendationHandlers.
+func NewRecommendationHandlers(recommendationService service.RecommendationService) *RecommendationHandlers {
+	return &RecommendationHandlers{recommendationService: recommendationService}
+}
+
+// GetPlaylistRecommendationsHandler handles GET requests for songs to add to a playlist. The
+// optional "limit" query parameter sets how many songs are suggested.
+func (h *RecommendationHandlers) GetPlaylistRecommendationsHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		api.LogErrorAndRespond(w, "Authorization required", http.StatusUnauthorized)
+		return
+	}
+	limit, ok := recommendationLimit(w, r)
+	if !ok {
+		return
+	}
+
+	recommendations, err := h.recommendationService.RecommendForPlaylist(mux.Vars(r)["playlistID"], userID, limit)
+	if err != nil {
+		if errors.Is(err, service.ErrPlaylistNotFound) {
+			api.LogErrorWithDetails(w, "Playlist not found", err, http.StatusNotFound)
+			return
+		}
+		api.LogErrorWithDetails(w, "Failed to compute recommendations", err, http.StatusInternalServerError)
+		return
+	}
+
+	api.RespondWithJSON(w, http.StatusOK, recommendations)
+}
+
+// GetMyRecommendationsHandler handles GET requests for songs suggested from the caller's liked
+// songs and listening history. The optional "limit" query parameter sets how many songs are suggested.
+func (h *RecommendationHandlers) GetMyRecommendationsHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		api.LogErrorAndRespond(w, "Authorization required", http.StatusUnauthorized)
+		return
+	}
+	limit, ok := recommendationLimit(w, r)
+	if !ok {
+		return
+	}
+
+	recommendations, err := h.recommendationService.RecommendForUser(userID, limit)
+	if err != nil {
+		api.LogErrorWithDetails(w, "Failed to compute recommendations", err, http.StatusInternalServerError)
+		return
+	}
+
+	api.RespondWithJSON(w, http.StatusOK, recommendations)
+}
+
+// recommendationLimit parses the optional "limit" query parameter, responding with an error when it is invalid.
+func recommendationLimit(w http.ResponseWriter, r *http.Request) (int, bool) {
+	value := r.URL.Query().Get("limit")
+	if value == "" {
+		return 0, true
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 0 {
+		api.LogErrorWithDetails(w, "Invalid limit", err, http.StatusBadRequest)
+		return 0, false
+	}
+	return limit, true
+}
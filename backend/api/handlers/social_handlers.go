@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/kaiohenricunha/go-music-k8s/backend/api"
+	"github.com/kaiohenricunha/go-music-k8s/backend/api/middleware"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/service"
+)
+
+// SocialHandlers encapsulates handlers for following users and playlists and the activity feed.
+type SocialHandlers struct {
+	socialService service.SocialService
+}
+
+// NewSocialHandlers creates an instance of SocialHandlers.
+func NewSocialHandlers(socialService service.SocialService) *SocialHandlers {
+	return &SocialHandlers{socialService: socialService}
+}
+
+// FollowUserHandler handles POST requests to follow a user.
+func (h *SocialHandlers) FollowUserHandler(w http.ResponseWriter, r *http.Request) {
+	h.changeFollow(w, r, func(userID uint) error {
+		return h.socialService.FollowUser(userID, mux.Vars(r)["username"])
+	}, "User followed successfully")
+}
+
+// UnfollowUserHandler handles DELETE requests to stop following a user.
+func (h *SocialHandlers) UnfollowUserHandler(w http.ResponseWriter, r *http.Request) {
+	h.changeFollow(w, r, func(userID uint) error {
+		return h.socialService.UnfollowUser(userID, mux.Vars(r)["username"])
+	}, "User unfollowed successfully")
+}
+
+// FollowPlaylistHandler handles POST requests to follow a playlist.
+func (h *SocialHandlers) FollowPlaylistHandler(w http.ResponseWriter, r *http.Request) {
+	h.changeFollow(w, r, func(userID uint) error {
+		return h.socialService.FollowPlaylist(userID, mux.Vars(r)["playlistID"])
+	}, "Playlist followed successfully")
+}
+
+// UnfollowPlaylistHandler handles DELETE requests to stop following a playlist.
+func (h *SocialHandlers) UnfollowPlaylistHandler(w http.ResponseWriter, r *http.Request) {
+	h.changeFollow(w, r, func(userID uint) error {
+		return h.socialService.UnfollowPlaylist(userID, mux.Vars(r)["playlistID"])
+	}, "Playlist unfollowed successfully")
+}
+
+func (h *SocialHandlers) changeFollow(w http.ResponseWriter, r *http.Request, change func(userID uint) error, message string) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		api.LogErrorAndRespond(w, "Authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	if err := change(userID); err != nil {
+		h.respondWithSocialError(w, "Failed to update follow", err)
+		return
+	}
+
+	api.RespondWithJSON(w, http.StatusOK, map[string]string{"message": message})
+}
+
+// GetUserFollowCountsHandler handles GET requests for a user's follower and following counts.
+func (h *SocialHandlers) GetUserFollowCountsHandler(w http.ResponseWriter, r *http.Request) {
+	counts, err := h.socialService.GetUserFollowCounts(mux.Vars(r)["username"])
+	if err != nil {
+		h.respondWithSocialError(w, "Failed to count followers", err)
+		return
+	}
+
+	api.RespondWithJSON(w, http.StatusOK, counts)
+}
+
+// GetPlaylistFollowCountsHandler handles GET requests for a playlist's follower count.
+func (h *SocialHandlers) GetPlaylistFollowCountsHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		api.LogErrorAndRespond(w, "Authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	counts, err := h.socialService.GetPlaylistFollowCounts(userID, mux.Vars(r)["playlistID"])
+	if err != nil {
+		h.respondWithSocialError(w, "Failed to count followers", err)
+		return
+	}
+
+	api.RespondWithJSON(w, http.StatusOK, counts)
+}
+
+// GetFeedHandler handles GET requests for the caller's activity feed. The optional "limit" query
+// parameter sets the page size and "before" continues from the next_before of a previous page.
+func (h *SocialHandlers) GetFeedHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		api.LogErrorAndRespond(w, "Authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	query := r.URL.Query()
+	var before uint64
+	var limit int
+	var err error
+	if value := query.Get("before"); value != "" {
+		if before, err = strconv.ParseUint(value, 10, 64); err != nil {
+			api.LogErrorWithDetails(w, "Invalid before", err, http.StatusBadRequest)
+			return
+		}
+	}
+	if value := query.Get("limit"); value != "" {
+		if limit, err = strconv.Atoi(value); err != nil || limit < 0 {
+			api.LogErrorWithDetails(w, "Invalid limit", err, http.StatusBadRequest)
+			return
+		}
+	}
+
+	feed, err := h.socialService.GetFeed(userID, uint(before), limit)
+	if err != nil {
+		api.LogErrorWithDetails(w, "Failed to retrieve feed", err, http.StatusInternalServerError)
+		return
+	}
+
+	api.RespondWithJSON(w, http.StatusOK, feed)
+}
+
+// respondWithSocialError maps the errors of the follow operations to HTTP responses.
+func (h *SocialHandlers) respondWithSocialError(w http.ResponseWriter, errMsg string, err error) {
+	switch {
+	case errors.Is(err, service.ErrUserNotFound):
+		api.LogErrorWithDetails(w, "User not found", err, http.StatusNotFound)
+	case errors.Is(err, service.ErrPlaylistNotFound):
+		api.LogErrorWithDetails(w, "Playlist not found", err, http.StatusNotFound)
+	case errors.Is(err, service.ErrCannotFollowSelf):
+		api.LogErrorWithDetails(w, err.Error(), err, http.StatusBadRequest)
+	default:
+		api.LogErrorWithDetails(w, errMsg, err, http.StatusInternalServerError)
+	}
+}
@@ -1,45 +1,75 @@
 package handlers
 
 import (
-	"encoding/json"
+	"errors"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/gorilla/mux"
 	"github.com/kaiohenricunha/go-music-k8s/backend/api"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/auth"
 	"github.com/kaiohenricunha/go-music-k8s/backend/internal/model"
 	"github.com/kaiohenricunha/go-music-k8s/backend/internal/service"
 )
 
 // UserHandlers encapsulates handlers related to user operations.
 type UserHandlers struct {
-	userService service.UserService
+	userService    service.UserService
+	loginService   service.LoginService
+	accountService service.AccountService
+	tokens         auth.TokenConfig
 }
 
 // NewUserHandlers creates a new instance of UserHandlers.
-func NewUserHandlers(userService service.UserService) *UserHandlers {
+func NewUserHandlers(userService service.UserService, loginService service.LoginService, accountService service.AccountService, tokens auth.TokenConfig) *UserHandlers {
 	return &UserHandlers{
-		userService: userService,
+		userService:    userService,
+		loginService:   loginService,
+		accountService: accountService,
+		tokens:         tokens,
 	}
 }
 
+// registerUserRequest is the body of the registration endpoint. Only these fields can be set by
+// the client; the role and everything else are decided by the server.
+type registerUserRequest struct {
+	Username string `json:"username" validate:"required,min=3,max=32,username"`
+	Email    string `json:"email" validate:"required,max=254,email"`
+	Password string `json:"password" validate:"required,min=8,max=72,password"`
+	FullName string `json:"full_name" validate:"max=100"`
+}
+
 // RegisterUserHandler handles the user registration requests.
 func (h *UserHandlers) RegisterUserHandler(w http.ResponseWriter, r *http.Request) {
-	var user model.User
-	if err := json.NewDecoder(r.Body).Decode(&user); err != nil {
-		api.LogErrorWithDetails(w, "Invalid request body", err, http.StatusBadRequest)
+	var req registerUserRequest
+	if !api.DecodeJSON(w, r, &req) {
 		return
 	}
 
+	user := model.User{Username: req.Username, Email: req.Email, Password: req.Password, FullName: strings.TrimSpace(req.FullName)}
 	err := h.userService.RegisterUser(&user)
 	if err != nil {
-		if err == service.ErrUsernameOrEmailTaken {
-			api.LogErrorWithDetails(w, "Username or email already taken", err, http.StatusConflict) // Use HTTP 409 Conflict for duplicate username
-		} else {
+		switch {
+		case errors.Is(err, service.ErrUsernameTaken):
+			api.RespondWithRequestError(w, http.StatusConflict, api.RequestError{
+				Error: "Username already taken", Code: "username_taken",
+				Fields: []api.FieldError{{Field: "username", Message: "is already taken"}},
+			}, err)
+		case errors.Is(err, service.ErrEmailTaken):
+			api.RespondWithRequestError(w, http.StatusConflict, api.RequestError{
+				Error: "Email already registered", Code: "email_taken",
+				Fields: []api.FieldError{{Field: "email", Message: "is already registered"}},
+			}, err)
+		default:
 			api.LogErrorWithDetails(w, "Failed to register user", err, http.StatusInternalServerError)
 		}
 		return
 	}
 
+	sendInBackground("verification email", func() error { return h.accountService.RequestEmailVerification(user.ID) })
+
 	api.RespondWithJSON(w, http.StatusCreated, map[string]string{"message": "User registered successfully"})
 }
 
@@ -98,7 +128,8 @@ func (h *UserHandlers) GetUserByUsername(w http.ResponseWriter, r *http.Request)
 	api.RespondWithJSON(w, http.StatusOK, userMap)
 }
 
-// UserLoginHandler handles the user login requests.
+// UserLoginHandler handles the user login requests. Repeated failures for a username or from a
+// client IP are answered with 429 and a Retry-After header until the lock expires.
 func (h *UserHandlers) UserLoginHandler(w http.ResponseWriter, r *http.Request) {
 	username, password, ok := r.BasicAuth()
 	if !ok {
@@ -106,14 +137,23 @@ func (h *UserHandlers) UserLoginHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	userID, valid := h.userService.ValidateUser(username, password)
-	if !valid {
-		http.Error(w, "Invalid credentials", http.StatusUnauthorized)
+	userID, err := h.loginService.Login(username, password, api.ClientIP(r))
+	if err != nil {
+		var throttled *service.LoginThrottledError
+		switch {
+		case errors.As(err, &throttled):
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+			api.LogErrorWithDetails(w, "Too many failed login attempts, try again later", err, http.StatusTooManyRequests)
+		case errors.Is(err, service.ErrInvalidCredentials):
+			http.Error(w, "Invalid credentials", http.StatusUnauthorized)
+		default:
+			api.LogErrorWithDetails(w, "Failed to log in", err, http.StatusInternalServerError)
+		}
 		return
 	}
 
 	// Generate JWT for the user
-	token, err := api.GenerateJWT(userID)
+	token, err := h.tokens.Issue(userID)
 	if err != nil {
 		http.Error(w, "Failed to generate token", http.StatusInternalServerError)
 		return
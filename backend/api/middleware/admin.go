@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/auth"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/model"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/service"
+)
+
+// AdminRole is the role granting access to administrative routes.
+const AdminRole = "admin"
+
+// AdminOnlyMiddleware rejects requests from authenticated users that do not have the admin role,
+// and requests made with an API token without the admin scope. It must run after JWTAuthMiddleware.
+func AdminOnlyMiddleware(userService service.UserService) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID, ok := UserIDFromContext(r.Context())
+			if !ok {
+				http.Error(w, "Authorization required", http.StatusUnauthorized)
+				return
+			}
+
+			user, err := userService.GetUserByID(userID)
+			if err != nil || user.Role != AdminRole {
+				http.Error(w, "Forbidden", http.StatusForbidden)
+				return
+			}
+			if claims, _ := auth.ClaimsFromContext(r.Context()); !claims.HasScope(model.ScopeAdmin) {
+				RespondWithInsufficientScope(w, model.ScopeAdmin)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
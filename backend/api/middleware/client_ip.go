@@ -0,0 +1,17 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/kaiohenricunha/go-music-k8s/backend/api"
+)
+
+// ClientIPMiddleware stores the IP address of each request's client, as told by the trusted
+// proxies, for api.ClientIP.
+func ClientIPMiddleware(proxies api.TrustedProxies) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(api.WithClientIP(r.Context(), proxies.ClientIP(r))))
+		})
+	}
+}
@@ -2,64 +2,134 @@ package middleware
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
-	"os"
 	"strings"
 
-	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/auth"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/model"
 	"github.com/kaiohenricunha/go-music-k8s/backend/internal/service"
 )
 
-// contextKey used to avoid collisions
-type contextKey string
+// authRealm is the realm named in WWW-Authenticate challenges.
+const authRealm = "go-music-k8s"
 
-const userContextKey contextKey = "userID"
-
-// Replace the jwtKey initialization with this
-var jwtKey = []byte(os.Getenv("JWT_SECRET_KEY"))
-
-func JWTAuthMiddleware(userService service.UserService) func(http.Handler) http.Handler {
+// JWTAuthMiddleware accepts requests with a bearer token that auth.TokenConfig.Parse accepts and
+// that was not revoked by a password reset, or with a personal API token whose scopes allow the
+// request, and stores the token's claims in the request context. Other requests get a 401 or 403 response whose WWW-Authenticate header says
+// why, as described in RFC 6750.
+func JWTAuthMiddleware(userService service.UserService, apiTokenService service.APITokenService, tokens auth.TokenConfig) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			tokenString := r.Header.Get("Authorization")
-			if tokenString == "" {
-				w.Header().Set("WWW-Authenticate", `Bearer realm="restricted"`)
+			tokenString, ok := bearerToken(r)
+			if !ok {
+				w.Header().Set("WWW-Authenticate", fmt.Sprintf("Bearer realm=%q", authRealm))
 				http.Error(w, "Authorization required", http.StatusUnauthorized)
 				return
 			}
 
-			claims := &jwt.StandardClaims{}
-			tokenString = strings.TrimPrefix(tokenString, "Bearer ")
-			var err error
-			tkn, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-				// Log the token's content if validation fails
+			var claims *auth.Claims
+			if service.IsAPIToken(tokenString) {
+				apiToken, err := apiTokenService.Authenticate(tokenString)
 				if err != nil {
-					log.Printf("JWT Validation Error: %v. Token: %s", err, tokenString)
+					log.Printf("API token validation error: %v", err)
+					if !errors.Is(err, service.ErrInvalidAPIToken) && !errors.Is(err, service.ErrAPITokenExpired) {
+						http.Error(w, "Failed to check API token", http.StatusInternalServerError)
+						return
+					}
+					respondWithInvalidToken(w, apiTokenErrorDescription(err))
+					return
 				}
-				return jwtKey, nil
-			})
-
-			if err != nil {
-				if err == jwt.ErrSignatureInvalid {
-					http.Error(w, "Invalid token signature", http.StatusUnauthorized)
+				claims = auth.APITokenClaims(apiToken)
+			} else {
+				var err error
+				claims, err = tokens.Parse(tokenString)
+				if err != nil {
+					log.Printf("JWT Validation Error: %v", err)
+					respondWithInvalidToken(w, auth.ErrorDescription(err))
+					return
+				}
+				if err := checkNotRevoked(userService, claims); err != nil {
+					log.Printf("JWT Validation Error: %v", err)
+					if !errors.Is(err, auth.ErrTokenRevoked) {
+						http.Error(w, "Failed to check access token", http.StatusInternalServerError)
+						return
+					}
+					respondWithInvalidToken(w, auth.ErrorDescription(err))
 					return
 				}
-
-				// Log any validation errors in detail
-				log.Printf("JWT Validation Error: %v", err)
-				http.Error(w, "Invalid token", http.StatusBadRequest)
-				return
 			}
 
-			if !tkn.Valid {
-				http.Error(w, "Invalid token", http.StatusUnauthorized)
+			if scope := requiredScope(r); !claims.HasScope(scope) {
+				RespondWithInsufficientScope(w, scope)
 				return
 			}
 
-			// If the token was valid, set the user ID in the context
-			ctx := context.WithValue(r.Context(), userContextKey, claims.Subject)
-			next.ServeHTTP(w, r.WithContext(ctx))
+			next.ServeHTTP(w, r.WithContext(auth.WithClaims(r.Context(), claims)))
 		})
 	}
 }
+
+// checkNotRevoked returns auth.ErrTokenRevoked if the token's user has since reset their password
+// or no longer exists.
+func checkNotRevoked(userService service.UserService, claims *auth.Claims) error {
+	user, err := userService.GetUserByID(claims.UserID())
+	if errors.Is(err, service.ErrUserNotFound) {
+		return fmt.Errorf("%w: user %d not found", auth.ErrTokenRevoked, claims.UserID())
+	}
+	if err != nil {
+		return err
+	}
+	return claims.CheckNotRevoked(user)
+}
+
+// requiredScope returns the API token scope needed for a request: read for requests that only
+// read, write for the rest.
+func requiredScope(r *http.Request) string {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return model.ScopeRead
+	default:
+		return model.ScopeWrite
+	}
+}
+
+func apiTokenErrorDescription(err error) string {
+	if errors.Is(err, service.ErrAPITokenExpired) {
+		return "The API token expired"
+	}
+	return "The API token is invalid or was revoked"
+}
+
+func respondWithInvalidToken(w http.ResponseWriter, description string) {
+	w.Header().Set("WWW-Authenticate",
+		fmt.Sprintf("Bearer realm=%q, error=\"invalid_token\", error_description=%q", authRealm, description))
+	http.Error(w, description, http.StatusUnauthorized)
+}
+
+// RespondWithInsufficientScope refuses a request whose API token lacks the given scope.
+func RespondWithInsufficientScope(w http.ResponseWriter, scope string) {
+	w.Header().Set("WWW-Authenticate",
+		fmt.Sprintf("Bearer realm=%q, error=\"insufficient_scope\", scope=%q", authRealm, scope))
+	http.Error(w, fmt.Sprintf("The API token needs the %s scope", scope), http.StatusForbidden)
+}
+
+// bearerToken returns the token of an "Authorization: Bearer <token>" header.
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+		return "", false
+	}
+	return strings.TrimSpace(token), true
+}
+
+// UserIDFromContext returns the ID of the authenticated user set by JWTAuthMiddleware.
+func UserIDFromContext(ctx context.Context) (uint, bool) {
+	claims, ok := auth.ClaimsFromContext(ctx)
+	if !ok {
+		return 0, false
+	}
+	return claims.UserID(), true
+}
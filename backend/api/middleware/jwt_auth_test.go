@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/auth"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/dao/mocks"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/model"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/service"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func newTestTokenConfig(t *testing.T) auth.TokenConfig {
+	t.Helper()
+	_, private, _ := ed25519.GenerateKey(rand.Reader)
+	key, _ := auth.NewKey("test", private)
+	keys, err := auth.NewKeySet([]*auth.Key{key}, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return auth.TokenConfig{Keys: keys, Issuer: "go-music-k8s", Audience: "go-music-k8s-api", TTL: time.Hour, ClockSkew: time.Minute}
+}
+
+// authenticate sends a request with the given bearer token through JWTAuthMiddleware.
+func authenticate(middleware func(http.Handler) http.Handler, token string) *httptest.ResponseRecorder {
+	handler := middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/playlists", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestJWTAuthRejectsTokensIssuedBeforePasswordReset(t *testing.T) {
+	mockDAO := new(mocks.MusicDAO)
+	tokens := newTestTokenConfig(t)
+	middleware := JWTAuthMiddleware(service.NewUserService(mockDAO), service.NewAPITokenService(mockDAO), tokens)
+	user := &model.User{Model: gorm.Model{ID: 7}, Username: "alice"}
+	mockDAO.On("GetUserByID", uint(7)).Return(user, nil)
+
+	before, err := tokens.Issue(7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, http.StatusNoContent, authenticate(middleware, before).Code)
+
+	resetAt := time.Now()
+	user.PasswordChangedAt = &resetAt
+	rr := authenticate(middleware, before)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	assert.Contains(t, rr.Header().Get("WWW-Authenticate"), `error="invalid_token"`)
+	assert.Contains(t, rr.Body.String(), "The access token was revoked")
+
+	// Tokens issued after the reset, i.e. at a later login, are accepted.
+	earlier := resetAt.Add(-2 * time.Second)
+	user.PasswordChangedAt = &earlier
+	after, err := tokens.Issue(7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, http.StatusNoContent, authenticate(middleware, after).Code)
+}
+
+func TestJWTAuthRejectsTokensOfDeletedUsers(t *testing.T) {
+	mockDAO := new(mocks.MusicDAO)
+	tokens := newTestTokenConfig(t)
+	middleware := JWTAuthMiddleware(service.NewUserService(mockDAO), service.NewAPITokenService(mockDAO), tokens)
+	mockDAO.On("GetUserByID", uint(7)).Return(nil, service.ErrUserNotFound)
+
+	token, err := tokens.Issue(7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, http.StatusUnauthorized, authenticate(middleware, token).Code)
+}
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/kaiohenricunha/go-music-k8s/backend/api"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/ratelimit"
+)
+
+// RateLimitMiddleware limits how often each client calls the routes of a router. A route uses the
+// limit named after the route if there is one and ratelimit.DefaultLimit otherwise. Clients are
+// told apart by user ID when the middleware runs after JWTAuthMiddleware, and otherwise by the IP
+// address that ClientIPMiddleware resolved through the trusted proxies.
+// If the store fails, the request is allowed.
+func RateLimitMiddleware(store ratelimit.Store, limits map[string]ratelimit.Limit) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			name := ratelimit.DefaultLimit
+			if route := mux.CurrentRoute(r); route != nil {
+				if _, ok := limits[route.GetName()]; ok {
+					name = route.GetName()
+				}
+			}
+			limit := limits[name]
+			if limit.Unlimited() {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			client := "ip:" + api.ClientIP(r)
+			if userID, ok := UserIDFromContext(r.Context()); ok {
+				client = fmt.Sprintf("user:%d", userID)
+			}
+			result, err := store.Take(name+":"+client, limit, time.Now())
+			if err != nil {
+				log.Printf("Failed to check rate limit %s for %s, allowing request: %v", name, client, err)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+			w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+			w.Header().Set("X-RateLimit-Reset", ceilSeconds(result.Reset))
+			if !result.Allowed {
+				w.Header().Set("Retry-After", ceilSeconds(result.RetryAfter))
+				api.RespondWithRequestError(w, http.StatusTooManyRequests,
+					api.RequestError{Error: "Too many requests, try again later", Code: "rate_limited"},
+					fmt.Errorf("rate limit %s exceeded by %s", name, client))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ceilSeconds formats d as a whole number of seconds, rounded up.
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/kaiohenricunha/go-music-k8s/backend/api"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/ratelimit"
+	"github.com/stretchr/testify/assert"
+)
+
+// newRateLimitedRouter returns a router with a "login" route limited to two requests a minute,
+// behind a proxy at 10.0.0.1.
+func newRateLimitedRouter() *mux.Router {
+	r := mux.NewRouter()
+	r.Use(ClientIPMiddleware(api.TrustedProxies{netip.MustParsePrefix("10.0.0.1/32")}))
+	r.Use(RateLimitMiddleware(ratelimit.NewMemoryStore(), map[string]ratelimit.Limit{
+		ratelimit.DefaultLimit: {Requests: 100, Per: time.Minute},
+		"login":                {Requests: 2, Per: time.Minute},
+	}))
+	r.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}).Name("login")
+	return r
+}
+
+func sendFrom(router http.Handler, remoteAddr, forwardedFor string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/login", nil)
+	req.RemoteAddr = remoteAddr
+	if forwardedFor != "" {
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+	}
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	router := newRateLimitedRouter()
+
+	for remaining := 1; remaining >= 0; remaining-- {
+		rr := sendFrom(router, "192.0.2.1:1234", "")
+		assert.Equal(t, http.StatusNoContent, rr.Code)
+		assert.Equal(t, "2", rr.Header().Get("X-RateLimit-Limit"))
+		assert.Equal(t, strconv.Itoa(remaining), rr.Header().Get("X-RateLimit-Remaining"))
+		assert.Empty(t, rr.Header().Get("Retry-After"))
+	}
+
+	rr := sendFrom(router, "192.0.2.1:1234", "")
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.Equal(t, "0", rr.Header().Get("X-RateLimit-Remaining"))
+	// One of two tokens a minute comes back every 30 seconds; the bucket is full after a minute.
+	assert.Equal(t, "30", rr.Header().Get("Retry-After"))
+	assert.Equal(t, "60", rr.Header().Get("X-RateLimit-Reset"))
+	var body api.RequestError
+	if assert.NoError(t, json.NewDecoder(rr.Body).Decode(&body)) {
+		assert.Equal(t, "rate_limited", body.Code)
+	}
+
+	// Other clients have buckets of their own.
+	assert.Equal(t, http.StatusNoContent, sendFrom(router, "192.0.2.2:1234", "").Code)
+}
+
+func TestRateLimitMiddlewareTellsClientsApartBehindTrustedProxies(t *testing.T) {
+	router := newRateLimitedRouter()
+
+	for i := 0; i < 2; i++ {
+		assert.Equal(t, http.StatusNoContent, sendFrom(router, "10.0.0.1:1234", "198.51.100.1").Code)
+	}
+	assert.Equal(t, http.StatusTooManyRequests, sendFrom(router, "10.0.0.1:1234", "198.51.100.1").Code)
+	// Another client behind the same proxy is not limited.
+	assert.Equal(t, http.StatusNoContent, sendFrom(router, "10.0.0.1:1234", "198.51.100.2").Code)
+
+	// A client that is not a trusted proxy cannot escape its limit by sending X-Forwarded-For.
+	for i := 0; i < 2; i++ {
+		assert.Equal(t, http.StatusNoContent, sendFrom(router, "192.0.2.1:1234", "198.51.100.3").Code)
+	}
+	assert.Equal(t, http.StatusTooManyRequests, sendFrom(router, "192.0.2.1:1234", "198.51.100.4").Code)
+}
@@ -0,0 +1,249 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// MaxRequestBodySize is the largest JSON body DecodeJSON accepts unless a handler asks for another limit.
+const MaxRequestBodySize = 64 << 10
+
+// FieldError describes why a single field of a request body was rejected.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// RequestError is the JSON body of a response to a request that could not be decoded, validated
+// or carried out. Code is a stable identifier that clients can match on.
+type RequestError struct {
+	Error  string       `json:"error"`
+	Code   string       `json:"code,omitempty"`
+	Fields []FieldError `json:"fields,omitempty"`
+}
+
+var (
+	validate = newValidator()
+
+	usernamePattern = regexp.MustCompile(`^[a-zA-Z0-9_.-]+$`)
+)
+
+// newValidator returns a validator that names fields after their JSON keys and knows the
+// application's own rules.
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
+	mustRegister(v, "username", func(fl validator.FieldLevel) bool {
+		return usernamePattern.MatchString(fl.Field().String())
+	})
+	mustRegister(v, "password", func(fl validator.FieldLevel) bool {
+		return isStrongPassword(fl.Field().String())
+	})
+	return v
+}
+
+func mustRegister(v *validator.Validate, tag string, fn validator.Func) {
+	if err := v.RegisterValidation(tag, fn); err != nil {
+		panic(err)
+	}
+}
+
+// isStrongPassword requires at least one letter and one digit. Length is checked by the min and
+// max rules so that it gets its own message.
+func isStrongPassword(password string) bool {
+	var letter, digit bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLetter(r):
+			letter = true
+		case unicode.IsDigit(r):
+			digit = true
+		}
+	}
+	return letter && digit
+}
+
+// DecodeJSON decodes a JSON request body of at most MaxRequestBodySize bytes into dst and validates it.
+// On failure it responds to the client and returns false.
+func DecodeJSON(w http.ResponseWriter, r *http.Request, dst interface{}) bool {
+	return DecodeJSONWithLimit(w, r, dst, MaxRequestBodySize)
+}
+
+// DecodeJSONWithLimit decodes a JSON request body of at most maxBytes bytes into dst and checks the
+// rules in its validate struct tags. Unknown fields and trailing data are rejected. On failure it
+// responds with a RequestError and returns false.
+func DecodeJSONWithLimit(w http.ResponseWriter, r *http.Request, dst interface{}, maxBytes int64) bool {
+	return decodeJSON(w, r, dst, maxBytes, false)
+}
+
+// DecodeOptionalJSON is like DecodeJSON for endpoints whose body may be left out. A missing or
+// empty body leaves dst as it is, but is still validated.
+func DecodeOptionalJSON(w http.ResponseWriter, r *http.Request, dst interface{}) bool {
+	return decodeJSON(w, r, dst, MaxRequestBodySize, true)
+}
+
+func decodeJSON(w http.ResponseWriter, r *http.Request, dst interface{}, maxBytes int64, optional bool) bool {
+	// Clients may send an optional body chunked, without a Content-Length, so only the absence of
+	// a body or reading nothing from it means there is none.
+	if !optional || (r.Body != nil && r.Body != http.NoBody) {
+		decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBytes))
+		decoder.DisallowUnknownFields()
+
+		err := decoder.Decode(dst)
+		if err == nil && decoder.More() {
+			err = errors.New("body must contain a single JSON value")
+		}
+		if err != nil && !(optional && err == io.EOF) {
+			respondWithDecodeError(w, err, maxBytes)
+			return false
+		}
+	}
+
+	if err := validate.Struct(dst); err != nil {
+		var validationErrors validator.ValidationErrors
+		if !errors.As(err, &validationErrors) {
+			LogErrorWithDetails(w, "Failed to validate request body", err, http.StatusInternalServerError)
+			return false
+		}
+		fields := make([]FieldError, len(validationErrors))
+		for i, fieldErr := range validationErrors {
+			fields[i] = FieldError{Field: fieldPath(fieldErr), Message: fieldMessage(fieldErr)}
+		}
+		RespondWithRequestError(w, http.StatusBadRequest, RequestError{Error: "Invalid request body", Fields: fields}, err)
+		return false
+	}
+	return true
+}
+
+// respondWithDecodeError explains why a body is not valid JSON for the target type.
+func respondWithDecodeError(w http.ResponseWriter, err error, maxBytes int64) {
+	var (
+		maxBytesErr *http.MaxBytesError
+		syntaxErr   *json.SyntaxError
+		typeErr     *json.UnmarshalTypeError
+	)
+	switch {
+	case errors.As(err, &maxBytesErr):
+		RespondWithRequestError(w, http.StatusRequestEntityTooLarge,
+			RequestError{Error: fmt.Sprintf("Request body must not be larger than %d bytes", maxBytes)}, err)
+	case errors.Is(err, io.EOF):
+		RespondWithRequestError(w, http.StatusBadRequest, RequestError{Error: "Request body must not be empty"}, err)
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		RespondWithRequestError(w, http.StatusBadRequest, RequestError{Error: "Request body is not valid JSON"}, err)
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		RespondWithRequestError(w, http.StatusBadRequest, RequestError{
+			Error:  "Invalid request body",
+			Fields: []FieldError{{Field: typeErr.Field, Message: "must be a " + jsonTypeName(typeErr.Type)}},
+		}, err)
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		RespondWithRequestError(w, http.StatusBadRequest, RequestError{
+			Error:  "Invalid request body",
+			Fields: []FieldError{{Field: field, Message: "is not a known field"}},
+		}, err)
+	default:
+		RespondWithRequestError(w, http.StatusBadRequest, RequestError{Error: "Invalid request body"}, err)
+	}
+}
+
+// RespondWithRequestError logs err and responds with the given RequestError.
+func RespondWithRequestError(w http.ResponseWriter, statusCode int, body RequestError, err error) {
+	log.Printf("%s: %v", body.Error, err)
+	RespondWithJSON(w, statusCode, body)
+}
+
+// fieldPath returns the JSON path of a field, without the name of the top-level struct.
+func fieldPath(fieldErr validator.FieldError) string {
+	_, path, _ := strings.Cut(fieldErr.Namespace(), ".")
+	return path
+}
+
+// fieldMessage describes a failed validation rule in words.
+func fieldMessage(fieldErr validator.FieldError) string {
+	param := fieldErr.Param()
+	switch fieldErr.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "url", "http_url":
+		return "must be a valid URL"
+	case "username":
+		return "may only contain letters, digits, '.', '_' and '-'"
+	case "password":
+		return "must contain at least one letter and one digit"
+	case "oneof":
+		return "must be one of: " + strings.Join(strings.Fields(param), ", ")
+	case "min", "gte":
+		if isLengthCheck(fieldErr) {
+			return fmt.Sprintf("must have at least %s %s", param, lengthUnit(fieldErr))
+		}
+		return "must be at least " + param
+	case "max", "lte":
+		if isLengthCheck(fieldErr) {
+			return fmt.Sprintf("must have at most %s %s", param, lengthUnit(fieldErr))
+		}
+		return "must be at most " + param
+	case "gt":
+		return "must be greater than " + param
+	case "len":
+		return fmt.Sprintf("must have exactly %s %s", param, lengthUnit(fieldErr))
+	case "unique":
+		return "must not contain duplicates"
+	default:
+		return "is invalid"
+	}
+}
+
+func isLengthCheck(fieldErr validator.FieldError) bool {
+	switch fieldErr.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return true
+	}
+	return false
+}
+
+func lengthUnit(fieldErr validator.FieldError) string {
+	if fieldErr.Kind() == reflect.String {
+		return "characters"
+	}
+	return "items"
+}
+
+// jsonTypeName names a Go type the way a JSON client would think of it.
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "list"
+	default:
+		return "object"
+	}
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testSignup struct {
+	Username string   `json:"username" validate:"required,min=3,username"`
+	Email    string   `json:"email" validate:"required,email"`
+	Password string   `json:"password" validate:"required,min=8,password"`
+	Tags     []string `json:"tags" validate:"max=2"`
+}
+
+func decodeTestBody(t *testing.T, body string, maxBytes int64) (*httptest.ResponseRecorder, bool) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	var dst testSignup
+	return w, DecodeJSONWithLimit(w, r, &dst, maxBytes)
+}
+
+func TestDecodeJSON(t *testing.T) {
+	w, ok := decodeTestBody(t, `{"username":"ana.b","email":"ana@example.com","password":"s3cretpass"}`, MaxRequestBodySize)
+	assert.True(t, ok)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+		fields []FieldError
+	}{
+		{"empty", ``, http.StatusBadRequest, nil},
+		{"malformed", `{"username":`, http.StatusBadRequest, nil},
+		{"trailing data", `{"username":"ana","email":"a@b.co","password":"s3cretpass"} {}`, http.StatusBadRequest, nil},
+		{"unknown field", `{"username":"ana","role":"admin"}`, http.StatusBadRequest, []FieldError{{Field: "role", Message: "is not a known field"}}},
+		{"wrong type", `{"username":7}`, http.StatusBadRequest, []FieldError{{Field: "username", Message: "must be a string"}}},
+		{"too large", `{"username":"` + strings.Repeat("a", 200) + `"}`, http.StatusRequestEntityTooLarge, nil},
+		{"invalid fields", `{"username":"a b","email":"nope","password":"password","tags":["a","b","c"]}`, http.StatusBadRequest, []FieldError{
+			{Field: "username", Message: "may only contain letters, digits, '.', '_' and '-'"},
+			{Field: "email", Message: "must be a valid email address"},
+			{Field: "password", Message: "must contain at least one letter and one digit"},
+			{Field: "tags", Message: "must have at most 2 items"},
+		}},
+		{"missing fields", `{"password":"short1"}`, http.StatusBadRequest, []FieldError{
+			{Field: "username", Message: "is required"},
+			{Field: "email", Message: "is required"},
+			{Field: "password", Message: "must have at least 8 characters"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, ok := decodeTestBody(t, tt.body, 128)
+			assert.False(t, ok)
+			assert.Equal(t, tt.status, w.Code)
+
+			var resp RequestError
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.NotEmpty(t, resp.Error)
+			assert.Equal(t, tt.fields, resp.Fields)
+		})
+	}
+}
+
+type testOptions struct {
+	Name string `json:"name" validate:"max=5"`
+}
+
+func TestDecodeOptionalJSON(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   io.Reader
+		want   string
+		status int
+	}{
+		{name: "no body", body: nil, status: http.StatusOK},
+		{name: "empty chunked body", body: strings.NewReader(""), status: http.StatusOK},
+		{name: "chunked body", body: strings.NewReader(`{"name":"mix"}`), want: "mix", status: http.StatusOK},
+		{name: "malformed", body: strings.NewReader(`{"name":`), status: http.StatusBadRequest},
+		{name: "invalid", body: strings.NewReader(`{"name":"too long"}`), want: "too long", status: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/", nil)
+			if tt.body != nil {
+				// Without a Content-Length, as when the body is sent chunked.
+				r.Body = io.NopCloser(tt.body)
+				r.ContentLength = -1
+			}
+			var dst testOptions
+			ok := DecodeOptionalJSON(w, r, &dst)
+			assert.Equal(t, tt.status == http.StatusOK, ok)
+			assert.Equal(t, tt.status, w.Code)
+			assert.Equal(t, tt.want, dst.Name)
+		})
+	}
+}
@@ -5,56 +5,145 @@ import (
 
 	goHandlers "github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
+	"github.com/kaiohenricunha/go-music-k8s/backend/api"
 	"github.com/kaiohenricunha/go-music-k8s/backend/api/handlers"
 	"github.com/kaiohenricunha/go-music-k8s/backend/api/middleware"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/auth"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/ratelimit"
 	"github.com/kaiohenricunha/go-music-k8s/backend/internal/service"
 )
 
-func SetupRoutes(userService service.UserService, songService service.SongService, playlistService service.PlaylistService) http.Handler {
+func SetupRoutes(userService service.UserService, songService service.SongService, playlistService service.PlaylistService, playlistImportService service.PlaylistImportService, ratingService service.RatingService, socialService service.SocialService, playService service.PlayService, libraryService service.LibraryService, recommendationService service.RecommendationService, catalogRefreshService service.CatalogRefreshService, loginService service.LoginService, oidcLoginService service.OIDCLoginService, apiTokenService service.APITokenService, accountService service.AccountService, tokens auth.TokenConfig, rateLimitStore ratelimit.Store, rateLimits map[string]ratelimit.Limit, trustedProxies api.TrustedProxies) http.Handler {
 	r := mux.NewRouter()
 
 	// Middleware for JWT Auth
-	jwtMiddleware := middleware.JWTAuthMiddleware(userService)
+	jwtMiddleware := middleware.JWTAuthMiddleware(userService, apiTokenService, tokens)
+	// Rate limits are looked up by route name, so routes with their own limit must be named
+	rateLimitMiddleware := middleware.RateLimitMiddleware(rateLimitStore, rateLimits)
 
 	// Apply global middleware directly
 	r.Use(middleware.LoggingMiddleware)
+	r.Use(middleware.ClientIPMiddleware(trustedProxies))
 
 	// Initialize handlers
-	userHandlers := handlers.NewUserHandlers(userService)
+	userHandlers := handlers.NewUserHandlers(userService, loginService, accountService, tokens)
 	songHandlers := handlers.NewSongHandlers(songService)
-	playlistHandlers := handlers.NewPlaylistHandlers(playlistService)
+	playlistHandlers := handlers.NewPlaylistHandlers(playlistService, playlistImportService)
+	ratingHandlers := handlers.NewRatingHandlers(ratingService)
+	socialHandlers := handlers.NewSocialHandlers(socialService)
+	playHandlers := handlers.NewPlayHandlers(playService)
+	libraryHandlers := handlers.NewLibraryHandlers(libraryService)
+	recommendationHandlers := handlers.NewRecommendationHandlers(recommendationService)
+	adminHandlers := handlers.NewAdminHandlers(catalogRefreshService, loginService)
+	authHandlers := handlers.NewAuthHandlers(tokens)
+	oidcHandlers := handlers.NewOIDCHandlers(oidcLoginService, tokens)
+	apiTokenHandlers := handlers.NewAPITokenHandlers(apiTokenService)
+	accountHandlers := handlers.NewAccountHandlers(accountService)
+
+	// Public keys for verifying access tokens, at the well-known location outside the API prefix
+	r.HandleFunc("/.well-known/jwks.json", authHandlers.JWKSHandler).Methods("GET")
 
 	// Public routes (no auth needed)
 	publicRouter := r.PathPrefix("/api/v1").Subrouter()
-	publicRouter.HandleFunc("/register", userHandlers.RegisterUserHandler).Methods("POST")
+	publicRouter.Use(rateLimitMiddleware) // Clients are identified by IP here
+	publicRouter.HandleFunc("/register", userHandlers.RegisterUserHandler).Methods("POST").Name("register")
 	// The login route itself will handle basic authentication inside its handler
-	publicRouter.HandleFunc("/login", userHandlers.UserLoginHandler).Methods("POST")
+	publicRouter.HandleFunc("/login", userHandlers.UserLoginHandler).Methods("POST").Name("login")
+	// Login with an OpenID Connect provider: redirect to the provider, which redirects back to the callback
+	publicRouter.HandleFunc("/login/oidc", oidcHandlers.StartOIDCLoginHandler).Methods("GET").Name("login_oidc")
+	publicRouter.HandleFunc("/login/oidc/callback", oidcHandlers.OIDCCallbackHandler).Methods("GET").Name("login_oidc_callback")
+	// Links sent by email open pages of the web app, which post their tokens here
+	publicRouter.HandleFunc("/email-verification/confirm", accountHandlers.VerifyEmailHandler).Methods("POST").Name("email_verification_confirm")
+	publicRouter.HandleFunc("/password-reset", accountHandlers.RequestPasswordResetHandler).Methods("POST").Name("password_reset")
+	publicRouter.HandleFunc("/password-reset/confirm", accountHandlers.ResetPasswordHandler).Methods("POST").Name("password_reset_confirm")
+	publicRouter.HandleFunc("/shared/playlists/{token}", playlistHandlers.GetSharedPlaylistHandler).Methods("GET")
 
 	// Protected routes (JWT Auth)
 	protectedRouter := r.PathPrefix("/api/v1").Subrouter()
 	protectedRouter.Use(jwtMiddleware) // Apply JWT middleware here
+	protectedRouter.Use(rateLimitMiddleware)
 
 	// User-specific routes
 	protectedRouter.HandleFunc("/users", userHandlers.ListUsersHandler).Methods("GET")
 	protectedRouter.HandleFunc("/users/{username}", userHandlers.GetUserByUsername).Methods("GET")
+	protectedRouter.HandleFunc("/users/{username}/follow", socialHandlers.FollowUserHandler).Methods("POST")
+	protectedRouter.HandleFunc("/users/{username}/follow", socialHandlers.UnfollowUserHandler).Methods("DELETE")
+	protectedRouter.HandleFunc("/users/{username}/follow-counts", socialHandlers.GetUserFollowCountsHandler).Methods("GET")
 	// TODO: implement a route to update and delete users
 
 	// Song Routes
 	protectedRouter.HandleFunc("/songs", songHandlers.GetAllSongsHandler).Methods("GET")
-	protectedRouter.HandleFunc("/songs/search", songHandlers.SearchSongsFromSpotifyHandler).Methods("GET")
-	protectedRouter.HandleFunc("/songs/{spotifyID}", songHandlers.GetSongFromSpotifyByIDHandler).Methods("GET")
+	protectedRouter.HandleFunc("/songs/search", songHandlers.SearchSongsFromSpotifyHandler).Methods("GET").Name("songs_search")
+	protectedRouter.HandleFunc("/songs/{spotifyID}", songHandlers.GetSongFromSpotifyByIDHandler).Methods("GET").Name("songs_lookup")
+	protectedRouter.HandleFunc("/songs/{songID}/play-count", playHandlers.GetSongPlayCountHandler).Methods("GET")
+
+	// Play event routes
+	protectedRouter.HandleFunc("/plays", playHandlers.RecordPlaysHandler).Methods("POST")
 
 	// Playlist Routes
 	protectedRouter.HandleFunc("/playlists", playlistHandlers.GetAllPlaylistsHandler).Methods("GET")
+	protectedRouter.HandleFunc("/playlists", playlistHandlers.CreatePlaylistHandler).Methods("POST")
+	protectedRouter.HandleFunc("/playlists/import", playlistHandlers.ImportPlaylistHandler).Methods("POST").Name("playlists_import")
+	protectedRouter.HandleFunc("/playlists/import/file", playlistHandlers.ImportPlaylistFileHandler).Methods("POST").Name("playlists_import_file")
+	protectedRouter.HandleFunc("/playlists/import/{jobID}", playlistHandlers.GetImportJobHandler).Methods("GET")
+	protectedRouter.HandleFunc("/playlists/merge", playlistHandlers.MergePlaylistsHandler).Methods("POST")
 	protectedRouter.HandleFunc("/playlists/{playlistID}", playlistHandlers.GetPlaylistByIDHandler).Methods("GET")
+	protectedRouter.HandleFunc("/playlists/{playlistID}/export", playlistHandlers.ExportPlaylistHandler).Methods("GET")
+	protectedRouter.HandleFunc("/playlists/{playlistID}/songs", playlistHandlers.AddSongsToPlaylistHandler).Methods("POST")
+	protectedRouter.HandleFunc("/playlists/{playlistID}/songs", playlistHandlers.RemoveSongsFromPlaylistHandler).Methods("DELETE")
 	protectedRouter.HandleFunc("/playlists/{playlistID}/songs/{songID}", playlistHandlers.AddSongToPlaylistHandler).Methods("POST")
 	protectedRouter.HandleFunc("/playlists/{playlistID}/songs/{songID}", playlistHandlers.RemoveSongFromPlaylistHandler).Methods("DELETE")
+	protectedRouter.HandleFunc("/playlists/{playlistID}/entries", playlistHandlers.ReplacePlaylistSongsHandler).Methods("PUT")
+	protectedRouter.HandleFunc("/playlists/{playlistID}/entries/move", playlistHandlers.MovePlaylistEntriesHandler).Methods("POST")
+	protectedRouter.HandleFunc("/playlists/{playlistID}/entries/{entryID}", playlistHandlers.RemovePlaylistEntryHandler).Methods("DELETE")
+	protectedRouter.HandleFunc("/playlists/{playlistID}/visibility", playlistHandlers.SetPlaylistVisibilityHandler).Methods("PUT")
+	protectedRouter.HandleFunc("/playlists/{playlistID}/share-link", playlistHandlers.RotateShareLinkHandler).Methods("POST")
+	protectedRouter.HandleFunc("/playlists/{playlistID}/rules", playlistHandlers.SetSmartRulesHandler).Methods("PUT")
+	protectedRouter.HandleFunc("/playlists/{playlistID}/refresh", playlistHandlers.RefreshSmartPlaylistHandler).Methods("POST")
+	protectedRouter.HandleFunc("/playlists/{playlistID}/history", playlistHandlers.GetPlaylistHistoryHandler).Methods("GET")
+	protectedRouter.HandleFunc("/playlists/{playlistID}/history/diff", playlistHandlers.DiffPlaylistRevisionsHandler).Methods("GET")
+	protectedRouter.HandleFunc("/playlists/{playlistID}/restore/{revision}", playlistHandlers.RestorePlaylistRevisionHandler).Methods("POST")
+	protectedRouter.HandleFunc("/playlists/{playlistID}/copy", playlistHandlers.CopyPlaylistHandler).Methods("POST")
+	protectedRouter.HandleFunc("/playlists/{playlistID}/dedupe", playlistHandlers.DedupePlaylistHandler).Methods("POST")
+	protectedRouter.HandleFunc("/playlists/{playlistID}/collaborators", playlistHandlers.GetPlaylistCollaboratorsHandler).Methods("GET")
+	protectedRouter.HandleFunc("/playlists/{playlistID}/collaborators", playlistHandlers.InviteCollaboratorHandler).Methods("POST")
+	protectedRouter.HandleFunc("/playlists/{playlistID}/collaborators/{userID}", playlistHandlers.RemoveCollaboratorHandler).Methods("DELETE")
+	protectedRouter.HandleFunc("/playlists/{playlistID}/invite/accept", playlistHandlers.AcceptInviteHandler).Methods("POST")
+	protectedRouter.HandleFunc("/playlists/{playlistID}/invite/decline", playlistHandlers.DeclineInviteHandler).Methods("POST")
+
+	protectedRouter.HandleFunc("/playlists/{playlistID}/rating", ratingHandlers.RatePlaylistHandler).Methods("PUT")
+	protectedRouter.HandleFunc("/playlists/{playlistID}/follow", socialHandlers.FollowPlaylistHandler).Methods("POST")
+	protectedRouter.HandleFunc("/playlists/{playlistID}/follow", socialHandlers.UnfollowPlaylistHandler).Methods("DELETE")
+	protectedRouter.HandleFunc("/playlists/{playlistID}/follow-counts", socialHandlers.GetPlaylistFollowCountsHandler).Methods("GET")
+	protectedRouter.HandleFunc("/playlists/{playlistID}/recommendations", recommendationHandlers.GetPlaylistRecommendationsHandler).Methods("GET")
+
+	// Activity feed
+	protectedRouter.HandleFunc("/feed", socialHandlers.GetFeedHandler).Methods("GET")
+
+	// Current user routes
+	protectedRouter.HandleFunc("/me/playlist-invites", playlistHandlers.GetPendingInvitesHandler).Methods("GET")
+	protectedRouter.HandleFunc("/me/history", playHandlers.GetHistoryHandler).Methods("GET")
+	protectedRouter.HandleFunc("/me/recommendations", recommendationHandlers.GetMyRecommendationsHandler).Methods("GET")
+	protectedRouter.HandleFunc("/me/library/songs", libraryHandlers.GetLikedSongsHandler).Methods("GET")
+	protectedRouter.HandleFunc("/me/library/songs/{songID}", libraryHandlers.LikeSongHandler).Methods("PUT")
+	protectedRouter.HandleFunc("/me/library/songs/{songID}", libraryHandlers.UnlikeSongHandler).Methods("DELETE")
+	protectedRouter.HandleFunc("/me/email-verification", accountHandlers.RequestEmailVerificationHandler).Methods("POST").Name("email_verification")
+	protectedRouter.HandleFunc("/me/api-tokens", apiTokenHandlers.ListAPITokensHandler).Methods("GET")
+	protectedRouter.HandleFunc("/me/api-tokens", apiTokenHandlers.CreateAPITokenHandler).Methods("POST")
+	protectedRouter.HandleFunc("/me/api-tokens/{tokenID}", apiTokenHandlers.RevokeAPITokenHandler).Methods("DELETE")
+
+	// Admin Routes
+	adminRouter := protectedRouter.PathPrefix("/admin").Subrouter()
+	adminRouter.Use(middleware.AdminOnlyMiddleware(userService))
+	adminRouter.HandleFunc("/catalog-refresh", adminHandlers.GetCatalogRefreshStatusHandler).Methods("GET")
+	adminRouter.HandleFunc("/users/{username}/unlock", adminHandlers.UnlockUserHandler).Methods("POST")
 
 	// Wrap the entire router with CORS middleware
 	corsMiddleware := goHandlers.CORS(
 		goHandlers.AllowedOrigins([]string{"http://localhost:3000"}),
 		goHandlers.AllowedMethods([]string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}),
 		goHandlers.AllowedHeaders([]string{"X-Requested-With", "Content-Type", "Authorization"}),
+		goHandlers.ExposedHeaders([]string{"Retry-After", "X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset"}),
 		goHandlers.AllowCredentials(),
 	)
 
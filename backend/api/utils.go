@@ -2,13 +2,8 @@ package api
 
 import (
 	"encoding/json"
-	"fmt"
 	"log"
 	"net/http"
-	"os"
-	"time"
-
-	"github.com/dgrijalva/jwt-go"
 )
 
 // RespondWithJSON takes a payload, marshals it to JSON, and writes it to the response writer with the given status code.
@@ -37,27 +32,3 @@ func LogErrorWithDetails(w http.ResponseWriter, errMsg string, err error, status
 	log.Printf("%s: %v", errMsg, err)
 	http.Error(w, errMsg, statusCode)
 }
-
-// GenerateJWT generates a new JWT token for a given user ID.
-func GenerateJWT(userID uint) (string, error) {
-	// Fetch the JWT secret key from the environment
-	jwtKey := []byte(os.Getenv("JWT_SECRET_KEY"))
-
-	// Define token claims
-	claims := &jwt.StandardClaims{
-		ExpiresAt: time.Now().Add(24 * time.Hour).Unix(), // Token expires in 1 day
-		Issuer:    "go-music-k8s",
-		Subject:   fmt.Sprintf("%d", userID),
-	}
-
-	// Create token
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-	// Sign token
-	tokenString, err := token.SignedString(jwtKey)
-	if err != nil {
-		return "", err
-	}
-
-	return tokenString, nil
-}
@@ -5,13 +5,14 @@ import (
 	"log"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/kaiohenricunha/go-music-k8s/backend/internal/model"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 )
 
-// InitDB initializes the database and seeds it with initial data.
+// InitDB connects to the database, creating it if needed, and migrates the schema.
 func InitDB(dsn string) (*gorm.DB, error) {
 	db := connectDB(dsn)
 
@@ -25,10 +26,6 @@ func InitDB(dsn string) (*gorm.DB, error) {
 		return nil, fmt.Errorf("failed to migrate schema: %w", err)
 	}
 
-	if err := seedData(db); err != nil {
-		return nil, fmt.Errorf("failed to seed data: %w", err)
-	}
-
 	return db, nil
 }
 
@@ -84,39 +81,65 @@ func createDatabase(baseDSN string, dbName string) {
 
 // migrateSchema auto-migrates the database schema using GORM's AutoMigrate.
 func migrateSchema(db *gorm.DB) error {
-	return db.AutoMigrate(&model.User{}, &model.Song{}, &model.Playlist{}, &model.Rating{})
-}
-
-// seedData seeds the database with initial data if necessary.
-func seedData(db *gorm.DB) error {
-	if err := seedUsers(db); err != nil {
+	if err := db.AutoMigrate(&model.User{}, &model.Song{}, &model.Playlist{}, &model.PlaylistEntry{}, &model.PlaylistRevision{}, &model.PlaylistCollaborator{}, &model.Follow{}, &model.Activity{}, &model.PlayEvent{}, &model.SongPlayCount{}, &model.LikedSong{}, &model.Rating{}, &model.LoginThrottle{}, &model.AuditEvent{}, &model.RateLimitBucket{}, &model.UserIdentity{}, &model.APIToken{}); err != nil {
 		return err
 	}
 
-	return nil
+	return migratePlaylistSongs(db)
 }
 
-// seedUsers seeds the Users table with initial data.
-func seedUsers(db *gorm.DB) error {
-	var count int64
-	db.Model(&model.User{}).Count(&count)
-	if count == 0 {
-		users := []model.User{
-			{Username: "admin", Password: "admin"},
-			{Username: "user2", Password: "user2"},
+// migratePlaylistSongs moves the rows of the legacy playlist_songs join table into playlist_entries
+// and drops it. The legacy table had no order, so songs are positioned by ID. The copy and the drop
+// run in one transaction, but MySQL commits DDL implicitly, so playlists that already have entries
+// are skipped in case an earlier run copied them and then failed to drop the table.
+func migratePlaylistSongs(db *gorm.DB) error {
+	if !db.Migrator().HasTable("playlist_songs") {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		var rows []struct {
+			PlaylistID uint
+			SongID     uint
+			UserID     uint
 		}
-		for _, user := range users {
-			if err := db.Create(&user).Error; err != nil {
+		migrated := tx.Model(&model.PlaylistEntry{}).Distinct("playlist_id")
+		err := tx.Table("playlist_songs").
+			Select("playlist_songs.playlist_id, playlist_songs.song_id, playlists.user_id").
+			Joins("JOIN playlists ON playlists.id = playlist_songs.playlist_id").
+			Where("playlist_songs.playlist_id NOT IN (?)", migrated).
+			Order("playlist_songs.playlist_id, playlist_songs.song_id").
+			Scan(&rows).Error
+		if err != nil {
+			return err
+		}
+
+		if len(rows) > 0 {
+			now := time.Now()
+			positions := make(map[uint]int)
+			entries := make([]model.PlaylistEntry, len(rows))
+			for i, row := range rows {
+				entries[i] = model.PlaylistEntry{
+					PlaylistID: row.PlaylistID,
+					SongID:     row.SongID,
+					Position:   positions[row.PlaylistID],
+					AddedByID:  row.UserID,
+					AddedAt:    now,
+				}
+				positions[row.PlaylistID]++
+			}
+			if err := tx.CreateInBatches(&entries, 500).Error; err != nil {
 				return err
 			}
+			log.Printf("Migrated %d playlist songs to playlist entries", len(entries))
 		}
-		log.Println("Seeded Users successfully")
-	}
-	return nil
+
+		return tx.Migrator().DropTable("playlist_songs")
+	})
 }
 
 // dropAllTables drops all tables in the database.
 func dropAllTables(db *gorm.DB) error {
 	// Assuming you want to drop all tables, adjust accordingly
-	return db.Migrator().DropTable(&model.User{}, &model.Song{}, &model.Playlist{}, &model.Rating{})
+	return db.Migrator().DropTable(&model.User{}, &model.Song{}, &model.Playlist{}, &model.PlaylistEntry{}, &model.PlaylistRevision{}, &model.PlaylistCollaborator{}, &model.Follow{}, &model.Activity{}, &model.PlayEvent{}, &model.SongPlayCount{}, &model.LikedSong{}, &model.Rating{}, &model.LoginThrottle{}, &model.AuditEvent{}, &model.RateLimitBucket{}, &model.UserIdentity{}, &model.APIToken{})
 }
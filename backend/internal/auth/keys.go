@@ -0,0 +1,227 @@
+// Package auth issues and verifies the API's access tokens and holds the keys that sign them.
+//
+// Keys are PEM files in a directory, one per key, named <kid>.pem. A file holding a PKCS #8
+// private key can sign tokens and verify them; a file holding only a PKIX public key verifies
+// tokens signed before the key was retired. RSA keys sign with RS256 and Ed25519 keys with EdDSA.
+//
+// To rotate keys:
+//  1. Add the new private key to the directory. It is published in the JWKS but not used yet,
+//     giving other verifiers time to fetch it.
+//  2. Point the signing key ID at the new key. New tokens are signed with it, while tokens signed
+//     with the old key stay valid.
+//  3. Once the longest-lived token signed with the old key has expired, replace the old private
+//     key with its public key, and remove that in turn when it is no longer needed.
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Signing algorithms, as named in the alg header of a token.
+const (
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+// minRSAKeyBits is the smallest RSA modulus accepted for signing keys.
+const minRSAKeyBits = 2048
+
+var (
+	ErrNoSigningKey     = errors.New("no JWT signing key configured")
+	ErrUnsupportedKey   = errors.New("unsupported JWT key type, want RSA or Ed25519")
+	ErrDuplicateKeyID   = errors.New("duplicate JWT key ID")
+	ErrSigningKeyPublic = errors.New("JWT signing key has no private key")
+)
+
+// Key is a key that verifies tokens, and signs them if its private key is known.
+type Key struct {
+	ID        string
+	Algorithm string
+	Public    crypto.PublicKey
+
+	private crypto.PrivateKey
+}
+
+// NewKey wraps an RSA or Ed25519 private or public key.
+func NewKey(id string, key interface{}) (*Key, error) {
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		if k.N.BitLen() < minRSAKeyBits {
+			return nil, fmt.Errorf("JWT key %s: RSA keys must have at least %d bits", id, minRSAKeyBits)
+		}
+		return &Key{ID: id, Algorithm: AlgorithmRS256, Public: &k.PublicKey, private: k}, nil
+	case *rsa.PublicKey:
+		return &Key{ID: id, Algorithm: AlgorithmRS256, Public: k}, nil
+	case ed25519.PrivateKey:
+		return &Key{ID: id, Algorithm: AlgorithmEdDSA, Public: k.Public(), private: k}, nil
+	case ed25519.PublicKey:
+		return &Key{ID: id, Algorithm: AlgorithmEdDSA, Public: k}, nil
+	default:
+		return nil, fmt.Errorf("JWT key %s: %w", id, ErrUnsupportedKey)
+	}
+}
+
+// CanSign reports whether the private key is known.
+func (k *Key) CanSign() bool {
+	return k.private != nil
+}
+
+// SigningMethod returns the JWT signing method of the key's algorithm.
+func (k *Key) SigningMethod() jwt.SigningMethod {
+	return jwt.GetSigningMethod(k.Algorithm)
+}
+
+// SigningKey returns the private key in the form SigningMethod expects.
+func (k *Key) SigningKey() crypto.PrivateKey {
+	return k.private
+}
+
+// KeySet is the set of keys tokens are verified with, and the one new tokens are signed with.
+type KeySet struct {
+	signing *Key
+	keys    map[string]*Key
+}
+
+// NewKeySet returns a key set signing with the key with ID signingKeyID. If signingKeyID is empty,
+// the only key that can sign is used.
+func NewKeySet(keys []*Key, signingKeyID string) (*KeySet, error) {
+	set := &KeySet{keys: make(map[string]*Key, len(keys))}
+	var signers []*Key
+	for _, key := range keys {
+		if _, ok := set.keys[key.ID]; ok {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicateKeyID, key.ID)
+		}
+		set.keys[key.ID] = key
+		if key.CanSign() {
+			signers = append(signers, key)
+		}
+	}
+
+	if signingKeyID == "" {
+		if len(signers) != 1 {
+			return nil, fmt.Errorf("%w: found %d private keys, choose one by ID", ErrNoSigningKey, len(signers))
+		}
+		set.signing = signers[0]
+		return set, nil
+	}
+	signing, ok := set.keys[signingKeyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: no key with ID %s", ErrNoSigningKey, signingKeyID)
+	}
+	if !signing.CanSign() {
+		return nil, fmt.Errorf("%w: %s", ErrSigningKeyPublic, signingKeyID)
+	}
+	set.signing = signing
+	return set, nil
+}
+
+// LoadKeySet reads the keys in dir, see the package documentation, and signs with the key with ID
+// signingKeyID. It fails when dir holds no key that can sign.
+func LoadKeySet(dir, signingKeyID string) (*KeySet, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("%w: the keys directory is not set", ErrNoSigningKey)
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]*Key, 0, len(paths))
+	for _, path := range paths {
+		key, err := readKey(path)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return NewKeySet(keys, signingKeyID)
+}
+
+// readKey reads a PEM file holding a PKCS #8 private key or a PKIX public key.
+func readKey(path string) (*Key, error) {
+	id := strings.TrimSuffix(filepath.Base(path), ".pem")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWT key %s: %w", id, err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("JWT key %s is not PEM encoded", id)
+	}
+
+	var key interface{}
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("JWT key %s: unsupported PEM block %q, want PRIVATE KEY or PUBLIC KEY", id, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse JWT key %s: %w", id, err)
+	}
+	return NewKey(id, key)
+}
+
+// SigningKey returns the key new tokens are signed with.
+func (s *KeySet) SigningKey() *Key {
+	return s.signing
+}
+
+// Key returns the key with the given ID.
+func (s *KeySet) Key(id string) (*Key, bool) {
+	key, ok := s.keys[id]
+	return key, ok
+}
+
+// JWK is the public part of a key in JSON Web Key form (RFC 7517).
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+}
+
+// JWKS is a JSON Web Key Set.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public keys of the set, ordered by ID.
+func (s *KeySet) JWKS() JWKS {
+	jwks := JWKS{Keys: make([]JWK, 0, len(s.keys))}
+	for _, key := range s.keys {
+		jwk := JWK{KeyID: key.ID, Use: "sig", Algorithm: key.Algorithm}
+		switch public := key.Public.(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+	sort.Slice(jwks.Keys, func(i, j int) bool { return jwks.Keys[i].KeyID < jwks.Keys[j].KeyID })
+	return jwks
+}
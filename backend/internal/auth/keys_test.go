@@ -0,0 +1,105 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writePEM(t *testing.T, dir, id, blockType string, der []byte) {
+	t.Helper()
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	if err := os.WriteFile(filepath.Join(dir, id+".pem"), data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writePrivateKey(t *testing.T, dir, id string, key interface{}) {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePEM(t, dir, id, "PRIVATE KEY", der)
+}
+
+func writePublicKey(t *testing.T, dir, id string, key interface{}) {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePEM(t, dir, id, "PUBLIC KEY", der)
+}
+
+func TestLoadKeySet(t *testing.T) {
+	dir := t.TempDir()
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	edPublic, edPrivate, _ := ed25519.GenerateKey(rand.Reader)
+	_, retiredPrivate, _ := ed25519.GenerateKey(rand.Reader)
+	writePrivateKey(t, dir, "2024-02", edPrivate)
+	writePrivateKey(t, dir, "2024-01", rsaKey)
+	writePublicKey(t, dir, "2023-12", retiredPrivate.Public())
+
+	keys, err := LoadKeySet(dir, "2024-02")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "2024-02", keys.SigningKey().ID)
+	assert.Equal(t, AlgorithmEdDSA, keys.SigningKey().Algorithm)
+	assert.Equal(t, edPublic, keys.SigningKey().Public)
+
+	retired, ok := keys.Key("2023-12")
+	assert.True(t, ok)
+	assert.False(t, retired.CanSign())
+	rsaEntry, _ := keys.Key("2024-01")
+	assert.Equal(t, AlgorithmRS256, rsaEntry.Algorithm)
+
+	jwks := keys.JWKS()
+	if !assert.Len(t, jwks.Keys, 3) {
+		return
+	}
+	assert.Equal(t, JWK{KeyType: "OKP", KeyID: "2023-12", Use: "sig", Algorithm: "EdDSA", Curve: "Ed25519", X: jwks.Keys[0].X}, jwks.Keys[0])
+	assert.Equal(t, "RSA", jwks.Keys[1].KeyType)
+	assert.Equal(t, "AQAB", jwks.Keys[1].E)
+	assert.NotEmpty(t, jwks.Keys[1].N)
+	assert.Equal(t, "2024-02", jwks.Keys[2].KeyID)
+
+	// Several private keys need an explicit choice of signing key.
+	_, err = LoadKeySet(dir, "")
+	assert.ErrorIs(t, err, ErrNoSigningKey)
+	_, err = LoadKeySet(dir, "2023-12")
+	assert.ErrorIs(t, err, ErrSigningKeyPublic)
+	_, err = LoadKeySet(dir, "2025-01")
+	assert.ErrorIs(t, err, ErrNoSigningKey)
+}
+
+func TestLoadKeySetFailsWithoutKeys(t *testing.T) {
+	_, err := LoadKeySet("", "")
+	assert.ErrorIs(t, err, ErrNoSigningKey)
+
+	dir := t.TempDir()
+	_, err = LoadKeySet(dir, "")
+	assert.ErrorIs(t, err, ErrNoSigningKey)
+
+	_, private, _ := ed25519.GenerateKey(rand.Reader)
+	writePublicKey(t, dir, "old", private.Public())
+	_, err = LoadKeySet(dir, "")
+	assert.ErrorIs(t, err, ErrNoSigningKey)
+}
+
+func TestNewKeyRejectsWeakAndUnsupportedKeys(t *testing.T) {
+	small, _ := rsa.GenerateKey(rand.Reader, 1024)
+	_, err := NewKey("small", small)
+	assert.Error(t, err)
+
+	_, err = NewKey("hmac", []byte("secret"))
+	assert.ErrorIs(t, err, ErrUnsupportedKey)
+}
@@ -0,0 +1,206 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/model"
+)
+
+// TokenConfig describes the access tokens the API issues and accepts.
+type TokenConfig struct {
+	Keys      *KeySet
+	Issuer    string        // iss claim of issued tokens; other issuers are rejected.
+	Audience  string        // aud claim of issued tokens; tokens for other audiences are rejected.
+	TTL       time.Duration // How long issued tokens are valid.
+	ClockSkew time.Duration // Leeway when checking exp, nbf and iat against the clock of another server.
+}
+
+// Claims are the claims of an access token. The subject is the ID of the user it was issued to.
+type Claims struct {
+	jwt.RegisteredClaims
+
+	// APIToken is set when the request was authenticated with a personal API token instead of a
+	// JWT. Its scopes limit what the request may do.
+	APIToken *model.APIToken `json:"-"`
+}
+
+// APITokenClaims returns the claims of a request authenticated with a personal API token.
+func APITokenClaims(token *model.APIToken) *Claims {
+	return &Claims{
+		RegisteredClaims: jwt.RegisteredClaims{Subject: strconv.FormatUint(uint64(token.UserID), 10)},
+		APIToken:         token,
+	}
+}
+
+// HasScope reports whether the request may do what scope allows. JWTs issued at login allow everything.
+func (c *Claims) HasScope(scope string) bool {
+	return c.APIToken == nil || c.APIToken.HasScope(scope)
+}
+
+// UserID returns the ID of the user the token was issued to.
+func (c *Claims) UserID() uint {
+	userID, _ := strconv.ParseUint(c.Subject, 10, 64)
+	return uint(userID)
+}
+
+// Validate rejects tokens whose subject is not a user ID. The registered claims are checked by the parser.
+func (c *Claims) Validate() error {
+	if userID, err := strconv.ParseUint(c.Subject, 10, 64); err != nil || userID == 0 {
+		return fmt.Errorf("%w: subject is not a user ID", jwt.ErrTokenInvalidSubject)
+	}
+	return nil
+}
+
+// ErrTokenRevoked is returned for access tokens issued before the user's password last changed.
+var ErrTokenRevoked = errors.New("token revoked")
+
+// CheckNotRevoked returns ErrTokenRevoked if the token was issued before user's password last
+// changed, so that resetting a password signs the user out everywhere. The iat claim is in whole
+// seconds, so tokens issued in the same second as the change are revoked too.
+func (c *Claims) CheckNotRevoked(user *model.User) error {
+	if user.PasswordChangedAt == nil {
+		return nil
+	}
+	if c.IssuedAt == nil || !c.IssuedAt.After(user.PasswordChangedAt.Truncate(time.Second)) {
+		return ErrTokenRevoked
+	}
+	return nil
+}
+
+// Issue returns a token for the given user, signed with the current signing key.
+func (c TokenConfig) Issue(userID uint) (string, error) {
+	return c.sign(&Claims{RegisteredClaims: c.registeredClaims(userID, c.Audience, c.TTL)})
+}
+
+// Parse verifies a token and returns its claims. The token must be signed by a known key with that
+// key's algorithm, come from the configured issuer for the configured audience, and be within its
+// validity period give or take ClockSkew.
+func (c TokenConfig) Parse(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	if err := c.parse(tokenString, claims, c.Audience); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// Purposes of the single-use tokens sent to users by email.
+const (
+	PurposeVerifyEmail   = "verify_email"
+	PurposeResetPassword = "reset_password"
+)
+
+// ActionClaims are the claims of a token that lets its holder take one action for a user, such as
+// resetting their password. State fingerprints what the action changes, so that the token stops
+// being valid once the action has been taken.
+type ActionClaims struct {
+	Claims
+	State string `json:"state"`
+}
+
+// IssueAction returns a token for an action on behalf of the given user, valid for ttl. It is
+// issued for an audience of its own per purpose, so it is never accepted as an access token or as
+// a token for another purpose.
+func (c TokenConfig) IssueAction(purpose string, userID uint, state string, ttl time.Duration) (string, error) {
+	return c.sign(&ActionClaims{
+		Claims: Claims{RegisteredClaims: c.registeredClaims(userID, c.actionAudience(purpose), ttl)},
+		State:  state,
+	})
+}
+
+// ParseAction verifies a token returned by IssueAction for the same purpose and returns its claims.
+func (c TokenConfig) ParseAction(purpose, tokenString string) (*ActionClaims, error) {
+	claims := &ActionClaims{}
+	if err := c.parse(tokenString, claims, c.actionAudience(purpose)); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+func (c TokenConfig) actionAudience(purpose string) string {
+	return c.Audience + "/" + purpose
+}
+
+func (c TokenConfig) registeredClaims(userID uint, audience string, ttl time.Duration) jwt.RegisteredClaims {
+	now := time.Now()
+	return jwt.RegisteredClaims{
+		Issuer:    c.Issuer,
+		Subject:   strconv.FormatUint(uint64(userID), 10),
+		Audience:  jwt.ClaimStrings{audience},
+		ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		NotBefore: jwt.NewNumericDate(now),
+		IssuedAt:  jwt.NewNumericDate(now),
+	}
+}
+
+// sign signs claims with the current signing key.
+func (c TokenConfig) sign(claims jwt.Claims) (string, error) {
+	// The kid header tells verifiers which key signed the token
+	key := c.Keys.SigningKey()
+	token := jwt.NewWithClaims(key.SigningMethod(), claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.SigningKey())
+}
+
+func (c TokenConfig) parse(tokenString string, claims jwt.Claims, audience string) error {
+	_, err := jwt.ParseWithClaims(tokenString, claims, c.keyFunc,
+		jwt.WithValidMethods([]string{AlgorithmRS256, AlgorithmEdDSA}),
+		jwt.WithIssuer(c.Issuer),
+		jwt.WithAudience(audience),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(c.ClockSkew),
+	)
+	return err
+}
+
+// keyFunc selects the key named by the kid header, which must have signed with its own algorithm.
+func (c TokenConfig) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := c.Keys.Key(kid)
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	if token.Method.Alg() != key.Algorithm {
+		return nil, fmt.Errorf("unexpected signing method %s for key %q", token.Method.Alg(), kid)
+	}
+	return key.Public, nil
+}
+
+// ErrorDescription explains in a sentence why Parse rejected a token, for the error_description
+// of a WWW-Authenticate header. It does not reveal more than the client can tell from the token.
+func ErrorDescription(err error) string {
+	switch {
+	case errors.Is(err, jwt.ErrTokenMalformed):
+		return "The access token is malformed"
+	case errors.Is(err, jwt.ErrTokenExpired):
+		return "The access token expired"
+	case errors.Is(err, jwt.ErrTokenNotValidYet), errors.Is(err, jwt.ErrTokenUsedBeforeIssued):
+		return "The access token is not valid yet"
+	case errors.Is(err, jwt.ErrTokenInvalidIssuer), errors.Is(err, jwt.ErrTokenInvalidAudience):
+		return "The access token was not issued for this API"
+	case errors.Is(err, jwt.ErrTokenRequiredClaimMissing), errors.Is(err, jwt.ErrTokenInvalidSubject):
+		return "The access token is missing required claims"
+	case errors.Is(err, ErrTokenRevoked):
+		return "The access token was revoked"
+	default:
+		return "The access token signature is invalid"
+	}
+}
+
+type contextKey struct{}
+
+// WithClaims returns a copy of ctx carrying the claims of the request's access token.
+func WithClaims(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, contextKey{}, claims)
+}
+
+// ClaimsFromContext returns the claims stored by WithClaims.
+func ClaimsFromContext(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(contextKey{}).(*Claims)
+	return claims, ok
+}
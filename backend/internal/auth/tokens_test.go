@@ -0,0 +1,215 @@
+package auth
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestTokenConfig(t *testing.T) TokenConfig {
+	t.Helper()
+	_, edPrivate, _ := ed25519.GenerateKey(rand.Reader)
+	rsaPrivate, _ := rsa.GenerateKey(rand.Reader, 2048)
+	edKey, _ := NewKey("ed", edPrivate)
+	rsaKey, _ := NewKey("rsa", rsaPrivate)
+	keys, err := NewKeySet([]*Key{edKey, rsaKey}, "ed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return TokenConfig{Keys: keys, Issuer: "go-music-k8s", Audience: "go-music-k8s-api", TTL: time.Hour, ClockSkew: time.Minute}
+}
+
+// signClaims signs claims with the key with the given ID, bypassing Issue.
+func signClaims(t *testing.T, config TokenConfig, kid string, claims jwt.RegisteredClaims) string {
+	t.Helper()
+	key, _ := config.Keys.Key(kid)
+	token := jwt.NewWithClaims(key.SigningMethod(), &Claims{RegisteredClaims: claims})
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key.SigningKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func TestIssueAndParse(t *testing.T) {
+	config := newTestTokenConfig(t)
+
+	token, err := config.Issue(42)
+	assert.NoError(t, err)
+	claims, err := config.Parse(token)
+	if assert.NoError(t, err) {
+		assert.Equal(t, uint(42), claims.UserID())
+		assert.Equal(t, "go-music-k8s", claims.Issuer)
+	}
+
+	// After rotating to the RSA key, tokens signed with the Ed25519 key stay valid.
+	config.Keys, _ = NewKeySet([]*Key{config.Keys.keys["ed"], config.Keys.keys["rsa"]}, "rsa")
+	rotated, err := config.Issue(43)
+	assert.NoError(t, err)
+	for _, token := range []string{token, rotated} {
+		_, err := config.Parse(token)
+		assert.NoError(t, err)
+	}
+}
+
+func TestCheckNotRevoked(t *testing.T) {
+	config := newTestTokenConfig(t)
+	now := time.Now()
+	issued := func(at time.Time) *Claims {
+		return &Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "42", IssuedAt: jwt.NewNumericDate(at)}}
+	}
+
+	user := &model.User{}
+	assert.NoError(t, issued(now).CheckNotRevoked(user))
+
+	changedAt := now.Add(-time.Minute)
+	user.PasswordChangedAt = &changedAt
+	assert.NoError(t, issued(now).CheckNotRevoked(user))
+	assert.ErrorIs(t, issued(changedAt.Add(-time.Second)).CheckNotRevoked(user), ErrTokenRevoked)
+	// iat is in whole seconds, so a token from the same second may predate the change.
+	assert.ErrorIs(t, issued(changedAt).CheckNotRevoked(user), ErrTokenRevoked)
+	assert.ErrorIs(t, (&Claims{}).CheckNotRevoked(user), ErrTokenRevoked)
+
+	token, err := config.Issue(42)
+	assert.NoError(t, err)
+	claims, err := config.Parse(token)
+	if assert.NoError(t, err) {
+		changedAt = time.Now()
+		assert.ErrorIs(t, claims.CheckNotRevoked(user), ErrTokenRevoked)
+	}
+}
+
+func TestActionTokensAreBoundToTheirPurpose(t *testing.T) {
+	config := newTestTokenConfig(t)
+
+	token, err := config.IssueAction(PurposeResetPassword, 42, "fingerprint", time.Hour)
+	assert.NoError(t, err)
+	claims, err := config.ParseAction(PurposeResetPassword, token)
+	if assert.NoError(t, err) {
+		assert.Equal(t, uint(42), claims.UserID())
+		assert.Equal(t, "fingerprint", claims.State)
+	}
+
+	_, err = config.ParseAction(PurposeVerifyEmail, token)
+	assert.ErrorIs(t, err, jwt.ErrTokenInvalidAudience)
+	_, err = config.Parse(token)
+	assert.ErrorIs(t, err, jwt.ErrTokenInvalidAudience)
+
+	access, err := config.Issue(42)
+	assert.NoError(t, err)
+	_, err = config.ParseAction(PurposeResetPassword, access)
+	assert.ErrorIs(t, err, jwt.ErrTokenInvalidAudience)
+
+	expired, err := config.IssueAction(PurposeVerifyEmail, 42, "fingerprint", -time.Hour)
+	assert.NoError(t, err)
+	_, err = config.ParseAction(PurposeVerifyEmail, expired)
+	assert.ErrorIs(t, err, jwt.ErrTokenExpired)
+}
+
+func TestParseRejectsInvalidTokens(t *testing.T) {
+	config := newTestTokenConfig(t)
+	now := time.Now()
+	valid := jwt.RegisteredClaims{
+		Issuer:    config.Issuer,
+		Subject:   "42",
+		Audience:  jwt.ClaimStrings{config.Audience},
+		ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+		IssuedAt:  jwt.NewNumericDate(now),
+	}
+	with := func(change func(c *jwt.RegisteredClaims)) jwt.RegisteredClaims {
+		claims := valid
+		change(&claims)
+		return claims
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		want  error
+	}{
+		{"malformed", "not.a.token", jwt.ErrTokenMalformed},
+		{"expired", signClaims(t, config, "ed", with(func(c *jwt.RegisteredClaims) {
+			c.ExpiresAt = jwt.NewNumericDate(now.Add(-2 * time.Minute))
+		})), jwt.ErrTokenExpired},
+		{"no expiry", signClaims(t, config, "ed", with(func(c *jwt.RegisteredClaims) { c.ExpiresAt = nil })), jwt.ErrTokenRequiredClaimMissing},
+		{"not yet valid", signClaims(t, config, "ed", with(func(c *jwt.RegisteredClaims) {
+			c.NotBefore = jwt.NewNumericDate(now.Add(2 * time.Minute))
+		})), jwt.ErrTokenNotValidYet},
+		{"issued in the future", signClaims(t, config, "ed", with(func(c *jwt.RegisteredClaims) {
+			c.IssuedAt = jwt.NewNumericDate(now.Add(2 * time.Minute))
+		})), jwt.ErrTokenUsedBeforeIssued},
+		{"other issuer", signClaims(t, config, "ed", with(func(c *jwt.RegisteredClaims) { c.Issuer = "someone-else" })), jwt.ErrTokenInvalidIssuer},
+		{"other audience", signClaims(t, config, "ed", with(func(c *jwt.RegisteredClaims) {
+			c.Audience = jwt.ClaimStrings{"other-api"}
+		})), jwt.ErrTokenInvalidAudience},
+		{"subject not a user", signClaims(t, config, "ed", with(func(c *jwt.RegisteredClaims) { c.Subject = "admin" })), jwt.ErrTokenInvalidSubject},
+	}
+	for _, tt := range tests {
+		_, err := config.Parse(tt.token)
+		assert.ErrorIs(t, err, tt.want, tt.name)
+	}
+
+	// Clocks a little apart are tolerated.
+	skewed := signClaims(t, config, "ed", with(func(c *jwt.RegisteredClaims) {
+		c.NotBefore = jwt.NewNumericDate(now.Add(30 * time.Second))
+		c.IssuedAt = jwt.NewNumericDate(now.Add(30 * time.Second))
+	}))
+	_, err := config.Parse(skewed)
+	assert.NoError(t, err)
+}
+
+func TestParseEnforcesAlgorithm(t *testing.T) {
+	config := newTestTokenConfig(t)
+	now := time.Now()
+	claims := &Claims{RegisteredClaims: jwt.RegisteredClaims{
+		Issuer: config.Issuer, Subject: "42", Audience: jwt.ClaimStrings{config.Audience},
+		ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)), IssuedAt: jwt.NewNumericDate(now),
+	}}
+
+	// An HMAC token keyed with the public key must not verify.
+	edKey, _ := config.Keys.Key("ed")
+	hmac := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	hmac.Header["kid"] = "ed"
+	signed, _ := hmac.SignedString([]byte(edKey.Public.(ed25519.PublicKey)))
+	_, err := config.Parse(signed)
+	assert.ErrorIs(t, err, jwt.ErrTokenSignatureInvalid)
+
+	// Nor may a key sign with another key's algorithm or be unknown.
+	rsaKey, _ := config.Keys.Key("rsa")
+	mismatched := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	mismatched.Header["kid"] = "ed"
+	signed, _ = mismatched.SignedString(rsaKey.SigningKey())
+	_, err = config.Parse(signed)
+	assert.ErrorIs(t, err, jwt.ErrTokenUnverifiable)
+
+	unknown := jwt.NewWithClaims(edKey.SigningMethod(), claims)
+	unknown.Header["kid"] = "retired"
+	signed, _ = unknown.SignedString(edKey.SigningKey())
+	_, err = config.Parse(signed)
+	assert.ErrorIs(t, err, jwt.ErrTokenUnverifiable)
+}
+
+func TestErrorDescription(t *testing.T) {
+	assert.Equal(t, "The access token expired", ErrorDescription(jwt.ErrTokenExpired))
+	assert.Equal(t, "The access token was not issued for this API", ErrorDescription(jwt.ErrTokenInvalidAudience))
+	assert.Equal(t, "The access token signature is invalid", ErrorDescription(errors.New("unknown signing key")))
+}
+
+func TestClaimsContext(t *testing.T) {
+	_, ok := ClaimsFromContext(context.Background())
+	assert.False(t, ok)
+
+	claims := &Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "7"}}
+	got, ok := ClaimsFromContext(WithClaims(context.Background(), claims))
+	assert.True(t, ok)
+	assert.Equal(t, uint(7), got.UserID())
+}
@@ -2,9 +2,15 @@ package config
 
 import (
 	"fmt"
+	"net/netip"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/kaiohenricunha/go-music-k8s/backend/db" // Adjust import path as necessary
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/auth"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/ratelimit"
 	"gorm.io/gorm"
 )
 
@@ -15,6 +21,70 @@ type Config struct {
 	DbUser     string
 	DB         *gorm.DB
 	ServerPort string
+
+	// Catalog refresh worker settings
+	CatalogRefreshInterval  time.Duration
+	CatalogRefreshMaxAge    time.Duration
+	CatalogRefreshBatchSize int
+
+	// Smart playlists not refreshed within this interval are recomputed; zero disables the worker.
+	SmartPlaylistRefreshInterval time.Duration
+
+	// Bootstrap admin created at startup if missing; no admin is created when the username is empty.
+	BootstrapAdminUsername string
+	BootstrapAdminEmail    string
+	BootstrapAdminPassword string
+	BootstrapAdminRole     string
+
+	// SeedDemoData adds sample songs and playlists to an empty catalog.
+	SeedDemoData bool
+
+	// DevMode allows settings that are only safe for local development, such as writing emails to the log.
+	DevMode bool
+
+	// Access tokens are signed with keys read from CONFIG_JWT_KEYS_DIR; see package auth for how to rotate them.
+	Tokens auth.TokenConfig
+
+	// OpenID Connect provider users may log in with; OIDC login is disabled when the issuer URL is empty.
+	OIDCIssuerURL    string
+	OIDCClientID     string
+	OIDCClientSecret string
+	OIDCRedirectURL  string
+
+	// AppURL is the base URL of the web app, which opens the links in emails sent to users.
+	AppURL string
+	// MailSender is "smtp" to send emails, or "file" or "log" to keep them local in DevMode.
+	MailSender   string
+	MailFrom     string
+	MailDir      string // Where the file sender writes emails.
+	SMTPAddr     string // host:port of the SMTP server.
+	SMTPUsername string
+	SMTPPassword string
+
+	// Request rate limits per client by route name; routes without a limit use ratelimit.DefaultLimit.
+	RateLimits map[string]ratelimit.Limit
+	// RateLimitStore is "memory" to enforce limits per replica or "database" to share them across replicas.
+	RateLimitStore string
+	// TrustedProxies are the networks of the proxies whose X-Forwarded-For and X-Real-IP headers
+	// tell the client's IP address; without any, the client is the peer.
+	TrustedProxies []netip.Prefix
+}
+
+// defaultRateLimits protect the routes that call Spotify or are attractive to abuse.
+var defaultRateLimits = map[string]ratelimit.Limit{
+	ratelimit.DefaultLimit:       {Requests: 300, Per: time.Minute},
+	"login":                      {Requests: 20, Per: time.Minute},
+	"login_oidc":                 {Requests: 20, Per: time.Minute},
+	"login_oidc_callback":        {Requests: 20, Per: time.Minute},
+	"register":                   {Requests: 10, Per: time.Hour},
+	"email_verification":         {Requests: 5, Per: time.Hour},
+	"email_verification_confirm": {Requests: 20, Per: time.Minute},
+	"password_reset":             {Requests: 5, Per: time.Hour},
+	"password_reset_confirm":     {Requests: 20, Per: time.Minute},
+	"songs_search":               {Requests: 30, Per: time.Minute},
+	"songs_lookup":               {Requests: 60, Per: time.Minute},
+	"playlists_import":           {Requests: 20, Per: time.Hour},
+	"playlists_import_file":      {Requests: 20, Per: time.Hour},
 }
 
 func NewConfig() (*Config, error) {
@@ -24,10 +94,101 @@ func NewConfig() (*Config, error) {
 		DbPass:     getEnv("CONFIG_DBPASS", "secret"),
 		DbUser:     getEnv("CONFIG_DBUSER", "root"),
 		ServerPort: getEnv("CONFIG_SERVER_PORT", "8081"),
+
+		BootstrapAdminUsername: getEnv("CONFIG_BOOTSTRAP_ADMIN_USERNAME", ""),
+		BootstrapAdminEmail:    getEnv("CONFIG_BOOTSTRAP_ADMIN_EMAIL", ""),
+		BootstrapAdminRole:     getEnv("CONFIG_BOOTSTRAP_ADMIN_ROLE", "admin"),
+
+		OIDCIssuerURL:   getEnv("CONFIG_OIDC_ISSUER_URL", ""),
+		OIDCClientID:    getEnv("CONFIG_OIDC_CLIENT_ID", ""),
+		OIDCRedirectURL: getEnv("CONFIG_OIDC_REDIRECT_URL", ""),
+
+		AppURL:       getEnv("CONFIG_APP_URL", "http://localhost:3000"),
+		MailSender:   getEnv("CONFIG_MAIL_SENDER", ""),
+		MailFrom:     getEnv("CONFIG_MAIL_FROM", "Music API <noreply@localhost>"),
+		MailDir:      getEnv("CONFIG_MAIL_DIR", "mail"),
+		SMTPAddr:     getEnv("CONFIG_SMTP_ADDR", ""),
+		SMTPUsername: getEnv("CONFIG_SMTP_USERNAME", ""),
+
+		RateLimitStore: getEnv("CONFIG_RATE_LIMIT_STORE", "memory"),
 	}
 
-	dsn := fmt.Sprintf("%s:%s@(%s)/%s?charset=utf8&parseTime=True&loc=Local", cfg.DbUser, cfg.DbPass, cfg.DbHost, cfg.DbName)
 	var err error
+	if cfg.CatalogRefreshInterval, err = getEnvDuration("CONFIG_CATALOG_REFRESH_INTERVAL", time.Hour); err != nil {
+		return nil, err
+	}
+	if cfg.CatalogRefreshMaxAge, err = getEnvDuration("CONFIG_CATALOG_REFRESH_MAX_AGE", 7*24*time.Hour); err != nil {
+		return nil, err
+	}
+	if cfg.CatalogRefreshBatchSize, err = getEnvInt("CONFIG_CATALOG_REFRESH_BATCH_SIZE", 50); err != nil {
+		return nil, err
+	}
+	if cfg.SmartPlaylistRefreshInterval, err = getEnvDuration("CONFIG_SMART_PLAYLIST_REFRESH_INTERVAL", time.Hour); err != nil {
+		return nil, err
+	}
+
+	if cfg.BootstrapAdminPassword, err = getSecret("CONFIG_BOOTSTRAP_ADMIN_PASSWORD"); err != nil {
+		return nil, err
+	}
+	if cfg.SeedDemoData, err = getEnvBool("CONFIG_SEED_DEMO_DATA", false); err != nil {
+		return nil, err
+	}
+	if cfg.DevMode, err = getEnvBool("CONFIG_DEV_MODE", false); err != nil {
+		return nil, err
+	}
+
+	if cfg.Tokens.Keys, err = auth.LoadKeySet(getEnv("CONFIG_JWT_KEYS_DIR", ""), getEnv("CONFIG_JWT_SIGNING_KEY_ID", "")); err != nil {
+		return nil, fmt.Errorf("failed to load JWT keys: %w", err)
+	}
+	cfg.Tokens.Issuer = getEnv("CONFIG_JWT_ISSUER", "go-music-k8s")
+	cfg.Tokens.Audience = getEnv("CONFIG_JWT_AUDIENCE", "go-music-k8s-api")
+	if cfg.Tokens.TTL, err = getEnvDuration("CONFIG_JWT_TOKEN_TTL", 24*time.Hour); err != nil {
+		return nil, err
+	}
+	if cfg.Tokens.ClockSkew, err = getEnvDuration("CONFIG_JWT_CLOCK_SKEW", time.Minute); err != nil {
+		return nil, err
+	}
+
+	if cfg.OIDCClientSecret, err = getSecret("CONFIG_OIDC_CLIENT_SECRET"); err != nil {
+		return nil, err
+	}
+	if cfg.OIDCIssuerURL != "" && (cfg.OIDCClientID == "" || cfg.OIDCRedirectURL == "") {
+		return nil, fmt.Errorf("CONFIG_OIDC_CLIENT_ID and CONFIG_OIDC_REDIRECT_URL must be set when CONFIG_OIDC_ISSUER_URL is")
+	}
+
+	if cfg.SMTPPassword, err = getSecret("CONFIG_SMTP_PASSWORD"); err != nil {
+		return nil, err
+	}
+	if cfg.MailSender == "" && cfg.DevMode {
+		cfg.MailSender = "log"
+	}
+	switch cfg.MailSender {
+	case "log", "file":
+		// Whoever can read the log or the files could follow the links sent to users.
+		if !cfg.DevMode {
+			return nil, fmt.Errorf("CONFIG_MAIL_SENDER %s is only allowed when CONFIG_DEV_MODE is true", cfg.MailSender)
+		}
+	case "smtp":
+		if cfg.SMTPAddr == "" {
+			return nil, fmt.Errorf("CONFIG_SMTP_ADDR must be set when CONFIG_MAIL_SENDER is smtp")
+		}
+	case "":
+		return nil, fmt.Errorf("CONFIG_MAIL_SENDER must be set to smtp, or to file or log when CONFIG_DEV_MODE is true")
+	default:
+		return nil, fmt.Errorf("invalid CONFIG_MAIL_SENDER %q: want smtp, file or log", cfg.MailSender)
+	}
+
+	if cfg.RateLimits, err = getEnvRateLimits("CONFIG_RATE_LIMITS", defaultRateLimits); err != nil {
+		return nil, err
+	}
+	if cfg.RateLimitStore != "memory" && cfg.RateLimitStore != "database" {
+		return nil, fmt.Errorf("invalid CONFIG_RATE_LIMIT_STORE %q: want memory or database", cfg.RateLimitStore)
+	}
+	if cfg.TrustedProxies, err = getEnvPrefixes("CONFIG_TRUSTED_PROXIES"); err != nil {
+		return nil, err
+	}
+
+	dsn := fmt.Sprintf("%s:%s@(%s)/%s?charset=utf8&parseTime=True&loc=Local", cfg.DbUser, cfg.DbPass, cfg.DbHost, cfg.DbName)
 	cfg.DB, err = db.InitDB(dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize database: %w", err)
@@ -43,3 +204,115 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getEnvDuration retrieves a duration such as "30m" from the environment or returns a default value.
+func getEnvDuration(key string, defaultValue time.Duration) (time.Duration, error) {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration for %s: %w", key, err)
+	}
+	return d, nil
+}
+
+// getEnvInt retrieves an integer from the environment or returns a default value.
+func getEnvInt(key string, defaultValue int) (int, error) {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid integer for %s: %w", key, err)
+	}
+	return n, nil
+}
+
+// getEnvBool retrieves a boolean such as "true" or "0" from the environment or returns a default value.
+func getEnvBool(key string, defaultValue bool) (bool, error) {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid boolean for %s: %w", key, err)
+	}
+	return b, nil
+}
+
+// getEnvRateLimits retrieves rate limits such as "songs_search=10/m,register=off" from the
+// environment. Listed limits replace the defaults with the same name; "off" removes the limit.
+func getEnvRateLimits(key string, defaults map[string]ratelimit.Limit) (map[string]ratelimit.Limit, error) {
+	limits := make(map[string]ratelimit.Limit, len(defaults))
+	for name, limit := range defaults {
+		limits[name] = limit
+	}
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return limits, nil
+	}
+	for _, entry := range strings.Split(value, ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		name, spec, ok := strings.Cut(entry, "=")
+		name, spec = strings.TrimSpace(name), strings.TrimSpace(spec)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid entry %q in %s: want <route>=<limit>", entry, key)
+		}
+		if spec == "off" {
+			// An explicit zero limit keeps the route from falling back to the default limit.
+			limits[name] = ratelimit.Limit{}
+			continue
+		}
+		limit, err := ratelimit.ParseLimit(spec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", key, err)
+		}
+		limits[name] = limit
+	}
+	return limits, nil
+}
+
+// getEnvPrefixes retrieves networks such as "10.0.0.0/8,192.0.2.1" from the environment. A bare
+// address stands for itself.
+func getEnvPrefixes(key string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, entry := range strings.Split(os.Getenv(key), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			addr, err := netip.ParseAddr(entry)
+			if err != nil {
+				return nil, fmt.Errorf("invalid address %q in %s: %w", entry, key, err)
+			}
+			prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid network %q in %s: %w", entry, key, err)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+// getSecret retrieves a secret from the file named by key+"_FILE", such as a mounted Kubernetes
+// secret, or else from the environment variable key. Surrounding whitespace is removed.
+func getSecret(key string) (string, error) {
+	if path, exists := os.LookupEnv(key + "_FILE"); exists {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s_FILE: %w", key, err)
+		}
+		return strings.TrimSpace(string(data)), nil
+	}
+	return strings.TrimSpace(os.Getenv(key)), nil
+}
@@ -1,6 +1,8 @@
 package dao
 
 import (
+	"time"
+
 	"github.com/kaiohenricunha/go-music-k8s/backend/internal/model"
 )
 
@@ -9,17 +11,89 @@ type MusicDAO interface {
 	GetAllUsers() ([]model.User, error)
 	GetUserByUsername(username string) (*model.User, error)
 	GetUserByID(userID uint) (*model.User, error)
+	UsernameExists(username string) (bool, error)
+	EmailExists(email string) (bool, error)
+	GetUserByEmail(email string) (*model.User, error)
+	GetUserIdentity(issuer, subject string) (*model.UserIdentity, error)
+	CreateUserIdentity(identity *model.UserIdentity) error
+	VerifyUserEmail(userID uint, email string, at time.Time) error
+	ResetUserPassword(userID uint, oldHash, newHash string, at time.Time) ([]model.APIToken, error)
+
+	GetLoginThrottles(keys []string) ([]model.LoginThrottle, error)
+	RecordLoginFailure(key string, at, resetBefore time.Time) (*model.LoginThrottle, error)
+	LockLogin(key string, until time.Time) error
+	DeleteLoginThrottle(key string) error
+	CreateAuditEvent(event *model.AuditEvent) error
+
+	CreateAPIToken(token *model.APIToken) error
+	GetAPITokens(userID uint) ([]model.APIToken, error)
+	GetAPITokenByHash(tokenHash string) (*model.APIToken, error)
+	RevokeAPIToken(userID, tokenID uint, at time.Time) error
+	UpdateAPITokenLastUsed(tokenID uint, at time.Time) error
+
+	UpdateRateLimitBucket(initial *model.RateLimitBucket, update func(bucket *model.RateLimitBucket)) error
+	DeleteRateLimitBuckets(refilledBefore time.Time) (int64, error)
 
 	CreateSong(song *model.Song) error
 	GetAllSongs() ([]model.Song, error)
 	GetSongByID(songID string) (*model.Song, error)
 	GetSongBySpotifyID(spotifyID string) (*model.Song, error)
 	GetSongByNameAndArtist(songName, artistName string) (*model.Song, error)
+	FindSongCandidates(title string, limit int) ([]model.Song, error)
 	GetSongFromSpotifyByID(spotifyID string) (*model.Song, error)
 	SearchSongsFromSpotify(trackName, artistName string) ([]model.Song, error)
+	GetStaleSongs(refreshedBefore time.Time, limit int) ([]model.Song, error)
+	UpdateSong(song *model.Song) error
+	UpsertSongs(songs []*model.Song) error
 
+	CreatePlaylist(playlist *model.Playlist) error
 	GetAllPlaylists() ([]model.Playlist, error)
+	GetVisiblePlaylists(userID uint) ([]model.Playlist, error)
 	GetPlaylistByID(playlistID string) (*model.Playlist, error)
-	AddSongToPlaylist(playlistID, songID string) error
-	RemoveSongFromPlaylist(playlistID, songID string) error
+	GetPlaylistByShareToken(token string) (*model.Playlist, error)
+	UpdatePlaylistSharing(playlist *model.Playlist, changedBy uint) error
+	UpdatePlaylistSmartRules(playlist *model.Playlist, changedBy uint) error
+	FindSongsMatchingRules(ownerID uint, rules model.SmartRules, limit int) ([]uint, error)
+	MaterializeSmartPlaylist(playlistID uint, songIDs []uint, materializedAt time.Time, refreshedBy uint) error
+	GetStaleSmartPlaylists(materializedBefore time.Time, excludeIDs []uint, limit int) ([]model.Playlist, error)
+	AddSongToPlaylist(playlistID, songID string, position int, addedBy uint) (*model.PlaylistEntry, error)
+	RemoveSongFromPlaylist(playlistID, songID string, removedBy uint) error
+	AddSongsToPlaylist(playlistID string, refs []model.SongRef, addedBy uint) ([]model.SongRefResult, error)
+	RemoveSongsFromPlaylist(playlistID string, refs []model.SongRef, removedBy uint) ([]model.SongRefResult, error)
+	RemovePlaylistEntry(playlistID string, entryID uint, removedBy uint) error
+	RemoveDuplicateEntries(playlistID string, duplicates func(entries []model.PlaylistEntry) []model.PlaylistEntry, removedBy uint) (int, error)
+	MovePlaylistEntries(playlistID string, rangeStart, rangeLength, insertBefore int, movedBy uint) error
+	ReplacePlaylistEntries(playlistID string, songIDs []uint, addedBy uint) error
+	GetPlaylistRevisions(playlistID uint, before, limit int) ([]model.PlaylistRevision, error)
+	GetPlaylistRevision(playlistID uint, number int) (*model.PlaylistRevision, error)
+	RestorePlaylistRevision(playlistID string, number int, restoredBy uint) error
+
+	GetPlaylistInfo(playlistID string) (*model.Playlist, error)
+	SavePlaylistCollaborator(collaborator *model.PlaylistCollaborator) error
+	GetPlaylistCollaborator(playlistID, userID uint) (*model.PlaylistCollaborator, error)
+	GetPlaylistCollaborators(playlistID uint) ([]model.PlaylistCollaborator, error)
+	GetPendingInvites(userID uint) ([]model.PlaylistCollaborator, error)
+	DeletePlaylistCollaborator(playlistID, userID uint) error
+
+	CreateFollow(follow *model.Follow) error
+	DeleteFollow(followerID uint, targetType string, targetID uint) error
+	CountFollowers(targetType string, targetID uint) (int64, error)
+	CountFollowing(followerID uint) (int64, error)
+	CreateActivity(activity *model.Activity) error
+	GetFeed(userID, beforeID uint, limit int) ([]model.Activity, error)
+
+	SaveRating(rating *model.Rating) error
+
+	GetExistingSongIDs(songIDs []uint) (map[uint]bool, error)
+	CreatePlayEvents(events []model.PlayEvent) error
+	GetPlayHistory(userID, beforeID uint, limit int) ([]model.PlayEvent, error)
+	GetSongPlayCount(songID uint) (*model.SongPlayCount, error)
+
+	LikeSong(userID, songID uint) error
+	UnlikeSong(userID, songID uint) error
+	GetLikedSongs(userID uint, oldestFirst bool, offset, limit int) ([]model.LikedSong, int64, error)
+
+	GetSongsByIDs(songIDs []uint) ([]model.Song, error)
+	GetCoOccurringSongs(userID uint, songIDs []uint, limit int) ([]model.SongOccurrence, error)
+	GetSongsByArtists(artists []string, excludeIDs []uint, limit int) ([]model.Song, error)
 }
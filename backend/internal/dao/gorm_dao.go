@@ -1,12 +1,19 @@
 package dao
 
 import (
+	"encoding/json"
 	"errors"
 	"log"
+	"slices"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/go-sql-driver/mysql"
 	"github.com/kaiohenricunha/go-music-k8s/backend/internal/model"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type GormDAO struct {
@@ -23,28 +30,70 @@ func NewGormDAO(db *gorm.DB) *GormDAO {
 //////////////////////
 
 var (
-	ErrUserNotFound      = errors.New("user not found")
-	ErrSongNotFound      = errors.New("song not found")
-	ErrPlaylistNotFound  = errors.New("playlist not found")
-	ErrRecordNotFound    = gorm.ErrRecordNotFound
-	ErrFailedAssociation = errors.New("failed to associate song with playlist")
+	ErrUserNotFound          = errors.New("user not found")
+	ErrUsernameTaken         = errors.New("username already taken")
+	ErrEmailTaken            = errors.New("email already registered")
+	ErrIdentityNotFound      = errors.New("user identity not found")
+	ErrAPITokenNotFound      = errors.New("API token not found")
+	ErrSongNotFound          = errors.New("song not found")
+	ErrPlaylistNotFound      = errors.New("playlist not found")
+	ErrRecordNotFound        = gorm.ErrRecordNotFound
+	ErrFailedAssociation     = errors.New("failed to associate song with playlist")
+	ErrInvalidPosition       = errors.New("invalid playlist position")
+	ErrPlaylistEntryNotFound = errors.New("playlist entry not found")
+	ErrCollaboratorNotFound  = errors.New("collaborator not found")
+	ErrPlayEventNotFound     = errors.New("play event not found")
+	ErrRevisionNotFound      = errors.New("playlist revision not found")
 )
 
-// CreateUser checks for a soft-deleted user with the same username and permanently deletes it before creating a new one.
+// mysqlDuplicateEntry is the MySQL error number for a unique index violation.
+const mysqlDuplicateEntry = 1062
+
+// CreateUser permanently deletes soft-deleted users with the same username or email before creating
+// a new one. A username or email taken by a live user, for instance by a concurrent registration,
+// is reported as ErrUsernameTaken or ErrEmailTaken.
 func (g *GormDAO) CreateUser(user *model.User) error {
-	var existingUser model.User
-	// Check for a soft-deleted user with the same username.
-	result := g.DB.Unscoped().Where("username = ?", user.Username).First(&existingUser)
-	if result.Error == nil && existingUser.DeletedAt.Valid {
-		// If a soft-deleted record exists, permanently delete it to free up the username.
-		if err := g.DB.Unscoped().Delete(&existingUser).Error; err != nil {
-			return err
-		}
-	} else if result.Error != gorm.ErrRecordNotFound {
-		return result.Error
+	// Free the username and email held by soft-deleted users.
+	err := g.DB.Unscoped().
+		Where("deleted_at IS NOT NULL AND (LOWER(username) = LOWER(?) OR LOWER(email) = LOWER(?))", user.Username, user.Email).
+		Delete(&model.User{}).Error
+	if err != nil {
+		return err
 	}
 
-	return g.DB.Create(user).Error
+	return translateUserConflict(g.DB.Create(user).Error)
+}
+
+// translateUserConflict turns the duplicate-key error of the users table's unique indexes into
+// ErrUsernameTaken or ErrEmailTaken.
+func translateUserConflict(err error) error {
+	var mysqlErr *mysql.MySQLError
+	if !errors.As(err, &mysqlErr) || mysqlErr.Number != mysqlDuplicateEntry {
+		return err
+	}
+	// The message names the violated key, e.g. "Duplicate entry 'a@b.c' for key 'users.email'".
+	_, key, _ := strings.Cut(mysqlErr.Message, " for key ")
+	switch {
+	case strings.Contains(key, "email"):
+		return ErrEmailTaken
+	case strings.Contains(key, "username"):
+		return ErrUsernameTaken
+	}
+	return err
+}
+
+// UsernameExists reports whether a user has the username, ignoring case.
+func (g *GormDAO) UsernameExists(username string) (bool, error) {
+	var count int64
+	err := g.DB.Model(&model.User{}).Where("LOWER(username) = LOWER(?)", username).Count(&count).Error
+	return count > 0, err
+}
+
+// EmailExists reports whether a user has registered the email, ignoring case.
+func (g *GormDAO) EmailExists(email string) (bool, error) {
+	var count int64
+	err := g.DB.Model(&model.User{}).Where("LOWER(email) = LOWER(?)", email).Count(&count).Error
+	return count > 0, err
 }
 
 // GetUserByID retrieves a single user by ID.
@@ -57,10 +106,10 @@ func (g *GormDAO) GetUserByID(userID uint) (*model.User, error) {
 	return &user, err
 }
 
-// GetAllUsers retrieves all users with their playlists.
+// GetAllUsers retrieves all users with their public playlists.
 func (g *GormDAO) GetAllUsers() ([]model.User, error) {
 	var users []model.User
-	err := g.DB.Preload("Playlists").Find(&users).Error
+	err := g.DB.Preload("Playlists", "visibility = ?", model.VisibilityPublic).Find(&users).Error
 	return users, err
 }
 
@@ -74,6 +123,206 @@ func (g *GormDAO) GetUserByUsername(username string) (*model.User, error) {
 	return &user, err
 }
 
+// GetUserByEmail retrieves a single user by email, in any letter case.
+func (g *GormDAO) GetUserByEmail(email string) (*model.User, error) {
+	var user model.User
+	err := g.DB.Where("LOWER(email) = LOWER(?)", email).First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrUserNotFound
+	}
+	return &user, err
+}
+
+// GetUserIdentity retrieves the link to the account with the given subject at an OpenID Connect provider.
+func (g *GormDAO) GetUserIdentity(issuer, subject string) (*model.UserIdentity, error) {
+	var identity model.UserIdentity
+	err := g.DB.Where("issuer = ? AND subject = ?", issuer, subject).First(&identity).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrIdentityNotFound
+	}
+	return &identity, err
+}
+
+// CreateUserIdentity links a user to an account at an OpenID Connect provider.
+func (g *GormDAO) CreateUserIdentity(identity *model.UserIdentity) error {
+	return g.DB.Create(identity).Error
+}
+
+// VerifyUserEmail records that a user verified their email at the given time. It returns
+// ErrUserNotFound unless the user still has that email and has not verified it yet, so that a
+// verification link is only used once.
+func (g *GormDAO) VerifyUserEmail(userID uint, email string, at time.Time) error {
+	result := g.DB.Model(&model.User{}).
+		Where("id = ? AND email = ? AND email_verified_at IS NULL", userID, email).
+		Update("email_verified_at", at)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// ResetUserPassword replaces a user's password hash, records that it changed at the given time and
+// revokes all of the user's API tokens, returning the tokens it revoked. It returns ErrUserNotFound
+// unless the current hash is still oldHash, so that concurrent resets with the same link cannot
+// both succeed.
+func (g *GormDAO) ResetUserPassword(userID uint, oldHash, newHash string, at time.Time) ([]model.APIToken, error) {
+	var tokens []model.APIToken
+	err := g.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.User{}).
+			Where("id = ? AND password = ?", userID, oldHash).
+			Updates(map[string]interface{}{"password": newHash, "password_changed_at": at})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrUserNotFound
+		}
+
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ? AND revoked_at IS NULL", userID).Order("id DESC").Find(&tokens).Error
+		if err != nil || len(tokens) == 0 {
+			return err
+		}
+		ids := make([]uint, len(tokens))
+		for i := range tokens {
+			ids[i] = tokens[i].ID
+			tokens[i].RevokedAt = &at
+		}
+		return tx.Model(&model.APIToken{}).Where("id IN ?", ids).Update("revoked_at", at).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+//////////////////////
+// LOGIN METHODS //
+//////////////////////
+
+// GetLoginThrottles retrieves the failed login counters with the given keys. Keys without failures are omitted.
+func (g *GormDAO) GetLoginThrottles(keys []string) ([]model.LoginThrottle, error) {
+	var throttles []model.LoginThrottle
+	err := g.DB.Where("`key` IN ?", keys).Find(&throttles).Error
+	return throttles, err
+}
+
+// RecordLoginFailure counts a failed login for key at the given time and returns the updated
+// counter. Failures older than resetBefore are forgotten first. Concurrent failures are all counted.
+func (g *GormDAO) RecordLoginFailure(key string, at, resetBefore time.Time) (*model.LoginThrottle, error) {
+	throttle := model.LoginThrottle{Key: key, Failures: 1, LastFailureAt: at}
+	err := g.DB.Transaction(func(tx *gorm.DB) error {
+		// The failures assignment must come first so that it compares the previous last_failure_at.
+		err := tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "key"}},
+			DoUpdates: clause.Set{
+				{Column: clause.Column{Name: "failures"}, Value: gorm.Expr("CASE WHEN login_throttles.last_failure_at < ? THEN 1 ELSE login_throttles.failures + 1 END", resetBefore)},
+				{Column: clause.Column{Name: "last_failure_at"}, Value: at},
+			},
+		}).Create(&throttle).Error
+		if err != nil {
+			return err
+		}
+		return tx.Where("`key` = ?", key).First(&throttle).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &throttle, nil
+}
+
+// LockLogin refuses logins for key until the given time.
+func (g *GormDAO) LockLogin(key string, until time.Time) error {
+	return g.DB.Model(&model.LoginThrottle{}).Where("`key` = ?", key).Update("locked_until", until).Error
+}
+
+// DeleteLoginThrottle forgets the failed logins for key, lifting any lock.
+func (g *GormDAO) DeleteLoginThrottle(key string) error {
+	return g.DB.Where("`key` = ?", key).Delete(&model.LoginThrottle{}).Error
+}
+
+// CreateAuditEvent appends an event to the audit log.
+func (g *GormDAO) CreateAuditEvent(event *model.AuditEvent) error {
+	return g.DB.Create(event).Error
+}
+
+//////////////////////
+// API TOKEN METHODS //
+//////////////////////
+
+// CreateAPIToken inserts a new API token.
+func (g *GormDAO) CreateAPIToken(token *model.APIToken) error {
+	return g.DB.Create(token).Error
+}
+
+// GetAPITokens retrieves the API tokens of a user that have not been revoked, newest first.
+// Expired tokens are included.
+func (g *GormDAO) GetAPITokens(userID uint) ([]model.APIToken, error) {
+	var tokens []model.APIToken
+	err := g.DB.Where("user_id = ? AND revoked_at IS NULL", userID).Order("id DESC").Find(&tokens).Error
+	return tokens, err
+}
+
+// GetAPITokenByHash retrieves the API token with the given hash that has not been revoked.
+func (g *GormDAO) GetAPITokenByHash(tokenHash string) (*model.APIToken, error) {
+	var token model.APIToken
+	err := g.DB.Where("token_hash = ? AND revoked_at IS NULL", tokenHash).First(&token).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrAPITokenNotFound
+	}
+	return &token, err
+}
+
+// RevokeAPIToken revokes an API token of the given user. It returns ErrAPITokenNotFound if the
+// user has no such token or it is already revoked.
+func (g *GormDAO) RevokeAPIToken(userID, tokenID uint, at time.Time) error {
+	result := g.DB.Model(&model.APIToken{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", tokenID, userID).
+		Update("revoked_at", at)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrAPITokenNotFound
+	}
+	return nil
+}
+
+// UpdateAPITokenLastUsed records when an API token was last used.
+func (g *GormDAO) UpdateAPITokenLastUsed(tokenID uint, at time.Time) error {
+	return g.DB.Model(&model.APIToken{}).Where("id = ?", tokenID).Update("last_used_at", at).Error
+}
+
+//////////////////////
+// RATE LIMIT METHODS //
+//////////////////////
+
+// UpdateRateLimitBucket applies update to the rate limit bucket with the key of initial, creating
+// it from initial first if it does not exist. The row is locked until update returns, so requests
+// counted concurrently by different replicas are all counted.
+func (g *GormDAO) UpdateRateLimitBucket(initial *model.RateLimitBucket, update func(bucket *model.RateLimitBucket)) error {
+	return g.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(initial).Error; err != nil {
+			return err
+		}
+		var bucket model.RateLimitBucket
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("`key` = ?", initial.Key).First(&bucket).Error; err != nil {
+			return err
+		}
+		update(&bucket)
+		return tx.Model(&bucket).Select("tokens", "refilled_at").Updates(&bucket).Error
+	})
+}
+
+// DeleteRateLimitBuckets deletes the buckets not used since refilledBefore and returns how many there were.
+func (g *GormDAO) DeleteRateLimitBuckets(refilledBefore time.Time) (int64, error) {
+	result := g.DB.Where("refilled_at < ?", refilledBefore).Delete(&model.RateLimitBucket{})
+	return result.RowsAffected, result.Error
+}
+
 //////////////////////
 // SONG METHODS //
 //////////////////////
@@ -125,6 +374,14 @@ func (g *GormDAO) GetSongByNameAndArtist(songName, artistName string) (*model.So
 	return &song, nil
 }
 
+// FindSongCandidates retrieves songs whose name contains the given title, for fuzzy matching.
+func (g *GormDAO) FindSongCandidates(title string, limit int) ([]model.Song, error) {
+	var songs []model.Song
+	pattern := "%" + strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(title) + "%"
+	err := g.DB.Where("song_name LIKE ?", pattern).Limit(limit).Find(&songs).Error
+	return songs, err
+}
+
 // GetSongFromSpotifyByID retrieves a single song by Spotify ID.
 func (g *GormDAO) GetSongFromSpotifyByID(spotifyID string) (*model.Song, error) {
 	var song model.Song
@@ -141,14 +398,98 @@ func (g *GormDAO) SearchSongsFromSpotify(trackName, artistName string) ([]model.
 	return songs, err
 }
 
+// GetStaleSongs retrieves Spotify-backed songs that were never refreshed or were last refreshed
+// before the given time, oldest first.
+func (g *GormDAO) GetStaleSongs(refreshedBefore time.Time, limit int) ([]model.Song, error) {
+	var songs []model.Song
+	err := g.DB.Where("spotify_id <> ''").
+		Where("refreshed_at IS NULL OR refreshed_at < ?", refreshedBefore).
+		Order("refreshed_at").
+		Limit(limit).
+		Find(&songs).Error
+	return songs, err
+}
+
+// UpdateSong saves all fields of an existing song.
+func (g *GormDAO) UpdateSong(song *model.Song) error {
+	return g.DB.Save(song).Error
+}
+
+// UpsertSongs stores songs matched by Spotify ID in a single transaction, creating the ones
+// that are missing and overwriting the metadata of the ones that exist. The stored IDs are set
+// on the given songs.
+func (g *GormDAO) UpsertSongs(songs []*model.Song) error {
+	return g.DB.Transaction(func(tx *gorm.DB) error {
+		for _, song := range songs {
+			var existing model.Song
+			err := tx.Where("spotify_id = ?", song.SpotifyID).First(&existing).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				if err := tx.Create(song).Error; err != nil {
+					return err
+				}
+				continue
+			}
+			if err != nil {
+				return err
+			}
+
+			song.Model = existing.Model
+			if err := tx.Save(song).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 //////////////////////
 // PLAYLIST METHODS //
 //////////////////////
 
-// GetPlaylistByID retrieves a single playlist by ID.
+// CreatePlaylist inserts a new playlist along with its entries, and records them as its first revision.
+func (g *GormDAO) CreatePlaylist(playlist *model.Playlist) error {
+	return g.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(playlist).Error; err != nil {
+			return err
+		}
+		_, err := recordRevision(tx, playlist.ID, model.PlaylistRevision{AuthorID: playlist.UserID, Action: model.RevisionCreated}, nil)
+		return err
+	})
+}
+
+// preloadEntries loads playlist entries in order along with their songs.
+func preloadEntries(db *gorm.DB) *gorm.DB {
+	return db.Preload("Entries", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position, id")
+	}).Preload("Entries.Song")
+}
+
+// fillSongs derives the ordered Songs list of a playlist from its entries.
+func fillSongs(playlist *model.Playlist) {
+	playlist.Songs = make([]model.Song, len(playlist.Entries))
+	for i, entry := range playlist.Entries {
+		playlist.Songs[i] = entry.Song
+	}
+}
+
+// GetPlaylistByID retrieves a single playlist by ID, with its songs in playlist order.
 func (g *GormDAO) GetPlaylistByID(playlistID string) (*model.Playlist, error) {
 	var playlist model.Playlist
-	err := g.DB.Preload("Songs").Preload("Ratings").Where("id = ?", playlistID).First(&playlist).Error
+	err := preloadEntries(g.DB).Preload("Ratings").Where("id = ?", playlistID).First(&playlist).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrPlaylistNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	fillSongs(&playlist)
+	return &playlist, nil
+}
+
+// GetPlaylistInfo retrieves a playlist's own fields, without its entries or ratings.
+func (g *GormDAO) GetPlaylistInfo(playlistID string) (*model.Playlist, error) {
+	var playlist model.Playlist
+	err := g.DB.Where("id = ?", playlistID).First(&playlist).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, ErrPlaylistNotFound
 	}
@@ -158,54 +499,1092 @@ func (g *GormDAO) GetPlaylistByID(playlistID string) (*model.Playlist, error) {
 // GetAllPlaylists retrieves all playlists from the database.
 func (g *GormDAO) GetAllPlaylists() ([]model.Playlist, error) {
 	var playlists []model.Playlist
-	err := g.DB.Preload("Songs").Preload("Ratings").Find(&playlists).Error
+	err := preloadEntries(g.DB).Preload("Ratings").Find(&playlists).Error
 	if err != nil {
 		return nil, err
 	}
+	for i := range playlists {
+		fillSongs(&playlists[i])
+	}
 	return playlists, nil
 }
 
-func (g *GormDAO) AddSongToPlaylist(playlistID, songID string) error {
-	// Convert IDs from string to their respective types, handling errors as needed.
-	pID, _ := strconv.ParseUint(playlistID, 10, 64)
-	sID, _ := strconv.ParseUint(songID, 10, 64)
+// visiblePlaylistIDs is a subquery selecting the IDs of the playlists a user may see.
+func visiblePlaylistIDs(db *gorm.DB, userID uint) *gorm.DB {
+	collaborations := db.Model(&model.PlaylistCollaborator{}).Select("playlist_id").
+		Where("user_id = ? AND status = ?", userID, model.InviteAccepted)
+	return db.Model(&model.Playlist{}).Select("id").
+		Where("visibility = ? OR user_id = ? OR id IN (?)", model.VisibilityPublic, userID, collaborations)
+}
+
+// sourcePlaylistIDs returns a subquery selecting the playlists whose songs and ratings may feed
+// results computed across playlists for a user: public and unlisted ones, and the user's own.
+// Other users' private playlists are left out so that such results do not reveal what is in them.
+func sourcePlaylistIDs(db *gorm.DB, userID uint) *gorm.DB {
+	return db.Model(&model.Playlist{}).Select("id").
+		Where("visibility IN ? OR user_id = ?", []string{model.VisibilityPublic, model.VisibilityUnlisted}, userID)
+}
+
+// GetVisiblePlaylists retrieves the playlists a user may see: public ones, their own and those they collaborate on.
+func (g *GormDAO) GetVisiblePlaylists(userID uint) ([]model.Playlist, error) {
+	var playlists []model.Playlist
+	err := preloadEntries(g.DB).Preload("Ratings").
+		Where("id IN (?)", visiblePlaylistIDs(g.DB, userID)).
+		Find(&playlists).Error
+	if err != nil {
+		return nil, err
+	}
+	for i := range playlists {
+		fillSongs(&playlists[i])
+	}
+	return playlists, nil
+}
 
-	// Assuming a many-to-many relationship is set up between playlists and songs,
-	// you can use GORM's Association method to append the song to the playlist.
+// GetPlaylistByShareToken retrieves the unlisted playlist with the given share token, with its songs in playlist order.
+func (g *GormDAO) GetPlaylistByShareToken(token string) (*model.Playlist, error) {
 	var playlist model.Playlist
-	if err := g.DB.First(&playlist, pID).Error; err != nil {
-		return ErrPlaylistNotFound
+	err := preloadEntries(g.DB).Preload("Ratings").
+		Where("share_token = ? AND visibility = ?", token, model.VisibilityUnlisted).
+		First(&playlist).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrPlaylistNotFound
+	}
+	if err != nil {
+		return nil, err
 	}
+	fillSongs(&playlist)
+	return &playlist, nil
+}
 
-	var song model.Song
-	if err := g.DB.First(&song, sID).Error; err != nil {
-		return ErrSongNotFound
+// UpdatePlaylistSharing saves the visibility and share token of a playlist, recording the change
+// as a revision by changedBy. The share token is not recorded, since it grants access.
+func (g *GormDAO) UpdatePlaylistSharing(playlist *model.Playlist, changedBy uint) error {
+	return g.changeSettings(playlist.ID, func(tx *gorm.DB, stored *model.Playlist) (*model.PlaylistRevision, error) {
+		var revision *model.PlaylistRevision
+		switch {
+		case stored.Visibility != playlist.Visibility:
+			revision = &model.PlaylistRevision{AuthorID: changedBy, Action: model.RevisionVisibilityChanged, Detail: playlist.Visibility}
+		case !equalStrings(stored.ShareToken, playlist.ShareToken):
+			revision = &model.PlaylistRevision{AuthorID: changedBy, Action: model.RevisionShareLinkRotated}
+		}
+		err := tx.Model(stored).Select("visibility", "share_token").
+			Updates(map[string]interface{}{"visibility": playlist.Visibility, "share_token": playlist.ShareToken}).Error
+		return revision, err
+	})
+}
+
+func equalStrings(a, b *string) bool {
+	return (a == nil && b == nil) || (a != nil && b != nil && *a == *b)
+}
+
+// lockPlaylist loads a playlist with a row lock so that concurrent changes to its entries are serialized.
+func lockPlaylist(tx *gorm.DB, playlistID string) (*model.Playlist, error) {
+	var playlist model.Playlist
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", playlistID).First(&playlist).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrPlaylistNotFound
+	}
+	return &playlist, err
+}
+
+// orderedEntries loads the entries of a playlist in order.
+func orderedEntries(tx *gorm.DB, playlistID uint) ([]model.PlaylistEntry, error) {
+	var entries []model.PlaylistEntry
+	err := tx.Where("playlist_id = ?", playlistID).Order("position, id").Find(&entries).Error
+	return entries, err
+}
+
+// savePositions renumbers entries to match their order in the slice, writing only the ones that moved.
+func savePositions(tx *gorm.DB, entries []model.PlaylistEntry) error {
+	for i := range entries {
+		if entries[i].Position == i {
+			continue
+		}
+		entries[i].Position = i
+		if err := tx.Model(&entries[i]).Update("position", i).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// latestRevision loads the most recent revision of a playlist, or nil if it has none.
+func latestRevision(tx *gorm.DB, playlistID uint) (*model.PlaylistRevision, error) {
+	var revision model.PlaylistRevision
+	err := tx.Where("playlist_id = ?", playlistID).Order("number DESC").Limit(1).Find(&revision).Error
+	if err != nil || revision.ID == 0 {
+		return nil, err
+	}
+	return &revision, nil
+}
+
+// recordRevision snapshots the current songs of a playlist as a new revision following latest,
+// unless they are the same as in latest and the revision does not change the playlist's settings.
+// It returns the revision that is now the latest.
+func recordRevision(tx *gorm.DB, playlistID uint, revision model.PlaylistRevision, latest *model.PlaylistRevision) (*model.PlaylistRevision, error) {
+	entries, err := orderedEntries(tx, playlistID)
+	if err != nil {
+		return nil, err
+	}
+	songIDs := make([]uint, len(entries))
+	for i, entry := range entries {
+		songIDs[i] = entry.SongID
+	}
+	if latest != nil && slices.Equal(latest.SongIDs, songIDs) && !revision.ChangesSettings() {
+		return latest, nil
+	}
+
+	revision.PlaylistID = playlistID
+	revision.Number = 1
+	if latest != nil {
+		revision.Number = latest.Number + 1
+	}
+	revision.SongIDs = songIDs
+	revision.SongCount = len(songIDs)
+	if err := tx.Create(&revision).Error; err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
+
+// changeEntries locks a playlist, applies change to its entries and records the result as a new
+// revision described by revision. Playlists created before history was recorded first get a
+// baseline revision of their contents, so that the change can be told apart and undone.
+func (g *GormDAO) changeEntries(playlistID string, revision model.PlaylistRevision, change func(tx *gorm.DB, playlist *model.Playlist) error) error {
+	return g.DB.Transaction(func(tx *gorm.DB) error {
+		playlist, err := lockPlaylist(tx, playlistID)
+		if err != nil {
+			return err
+		}
+		latest, err := latestOrBaselineRevision(tx, playlist)
+		if err != nil {
+			return err
+		}
+
+		if err := change(tx, playlist); err != nil {
+			return err
+		}
+		_, err = recordRevision(tx, playlist.ID, revision, latest)
+		return err
+	})
+}
+
+// changeSettings locks a playlist and applies change to its settings. change returns the revision
+// that describes what it changed, which is recorded with the playlist's songs, or nil if it
+// changed nothing.
+func (g *GormDAO) changeSettings(playlistID uint, change func(tx *gorm.DB, playlist *model.Playlist) (*model.PlaylistRevision, error)) error {
+	return g.DB.Transaction(func(tx *gorm.DB) error {
+		playlist, err := lockPlaylist(tx, strconv.FormatUint(uint64(playlistID), 10))
+		if err != nil {
+			return err
+		}
+		revision, err := change(tx, playlist)
+		if err != nil || revision == nil {
+			return err
+		}
+
+		latest, err := latestOrBaselineRevision(tx, playlist)
+		if err != nil {
+			return err
+		}
+		_, err = recordRevision(tx, playlist.ID, *revision, latest)
+		return err
+	})
+}
+
+// latestOrBaselineRevision loads the most recent revision of a playlist, first recording a
+// baseline revision of its contents if it has none.
+func latestOrBaselineRevision(tx *gorm.DB, playlist *model.Playlist) (*model.PlaylistRevision, error) {
+	latest, err := latestRevision(tx, playlist.ID)
+	if err != nil || latest != nil {
+		return latest, err
+	}
+	return recordRevision(tx, playlist.ID, model.PlaylistRevision{AuthorID: playlist.UserID, Action: model.RevisionBaseline}, nil)
+}
+
+// AddSongToPlaylist inserts a song at the given position, shifting the following entries down.
+// A negative position appends the song to the end of the playlist.
+func (g *GormDAO) AddSongToPlaylist(playlistID, songID string, position int, addedBy uint) (*model.PlaylistEntry, error) {
+	var entry *model.PlaylistEntry
+	revision := model.PlaylistRevision{AuthorID: addedBy, Action: model.RevisionSongsAdded}
+	err := g.changeEntries(playlistID, revision, func(tx *gorm.DB, playlist *model.Playlist) error {
+		var song model.Song
+		if err := tx.Where("id = ?", songID).First(&song).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrSongNotFound
+			}
+			return err
+		}
+
+		var count int64
+		if err := tx.Model(&model.PlaylistEntry{}).Where("playlist_id = ?", playlist.ID).Count(&count).Error; err != nil {
+			return err
+		}
+		if position < 0 {
+			position = int(count)
+		} else if position > int(count) {
+			return ErrInvalidPosition
+		}
+
+		err := tx.Model(&model.PlaylistEntry{}).
+			Where("playlist_id = ? AND position >= ?", playlist.ID, position).
+			Update("position", gorm.Expr("position + 1")).Error
+		if err != nil {
+			return err
+		}
+
+		entry = &model.PlaylistEntry{
+			PlaylistID: playlist.ID,
+			SongID:     song.ID,
+			Position:   position,
+			AddedByID:  addedBy,
+			AddedAt:    time.Now(),
+		}
+		return tx.Create(entry).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// RemoveSongFromPlaylist removes every entry of a song from a playlist and closes the gaps.
+func (g *GormDAO) RemoveSongFromPlaylist(playlistID, songID string, removedBy uint) error {
+	revision := model.PlaylistRevision{AuthorID: removedBy, Action: model.RevisionSongsRemoved}
+	return g.changeEntries(playlistID, revision, func(tx *gorm.DB, playlist *model.Playlist) error {
+		if err := tx.Where("playlist_id = ? AND song_id = ?", playlist.ID, songID).Delete(&model.PlaylistEntry{}).Error; err != nil {
+			return err
+		}
+
+		entries, err := orderedEntries(tx, playlist.ID)
+		if err != nil {
+			return err
+		}
+		return savePositions(tx, entries)
+	})
+}
+
+// resolveSongRefs looks up the songs referenced by ID or Spotify ID. The result is aligned with refs
+// and holds nil for references that match no song.
+func resolveSongRefs(tx *gorm.DB, refs []model.SongRef) ([]*model.Song, error) {
+	var ids []uint
+	var spotifyIDs []string
+	for _, ref := range refs {
+		if ref.SongID != 0 {
+			ids = append(ids, ref.SongID)
+		} else if ref.SpotifyID != "" {
+			spotifyIDs = append(spotifyIDs, ref.SpotifyID)
+		}
+	}
+
+	byID := make(map[uint]*model.Song)
+	bySpotifyID := make(map[string]*model.Song)
+	if len(ids) > 0 {
+		var songs []model.Song
+		if err := tx.Where("id IN ?", ids).Find(&songs).Error; err != nil {
+			return nil, err
+		}
+		for i := range songs {
+			byID[songs[i].ID] = &songs[i]
+		}
+	}
+	if len(spotifyIDs) > 0 {
+		var songs []model.Song
+		if err := tx.Where("spotify_id IN ?", spotifyIDs).Find(&songs).Error; err != nil {
+			return nil, err
+		}
+		for i := range songs {
+			bySpotifyID[songs[i].SpotifyID] = &songs[i]
+		}
+	}
+
+	resolved := make([]*model.Song, len(refs))
+	for i, ref := range refs {
+		if ref.SongID != 0 {
+			resolved[i] = byID[ref.SongID]
+		} else {
+			resolved[i] = bySpotifyID[ref.SpotifyID]
+		}
+	}
+	return resolved, nil
+}
+
+// playlistSongIDs returns the set of songs that have at least one entry in the playlist.
+func playlistSongIDs(tx *gorm.DB, playlistID uint) (map[uint]bool, error) {
+	var songIDs []uint
+	if err := tx.Model(&model.PlaylistEntry{}).Where("playlist_id = ?", playlistID).Distinct().Pluck("song_id", &songIDs).Error; err != nil {
+		return nil, err
+	}
+	present := make(map[uint]bool, len(songIDs))
+	for _, id := range songIDs {
+		present[id] = true
+	}
+	return present, nil
+}
+
+// AddSongsToPlaylist appends several songs to a playlist in one transaction. Songs that do not exist
+// or are already in the playlist are skipped and reported in the per-song results.
+func (g *GormDAO) AddSongsToPlaylist(playlistID string, refs []model.SongRef, addedBy uint) ([]model.SongRefResult, error) {
+	results := make([]model.SongRefResult, len(refs))
+	revision := model.PlaylistRevision{AuthorID: addedBy, Action: model.RevisionSongsAdded}
+	err := g.changeEntries(playlistID, revision, func(tx *gorm.DB, playlist *model.Playlist) error {
+		songs, err := resolveSongRefs(tx, refs)
+		if err != nil {
+			return err
+		}
+		present, err := playlistSongIDs(tx, playlist.ID)
+		if err != nil {
+			return err
+		}
+
+		var count int64
+		if err := tx.Model(&model.PlaylistEntry{}).Where("playlist_id = ?", playlist.ID).Count(&count).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		var entries []*model.PlaylistEntry
+		for i, song := range songs {
+			results[i].SongRef = refs[i]
+			switch {
+			case song == nil:
+				results[i].Status = model.SongRefNotFound
+			case present[song.ID]:
+				results[i].Status = model.SongRefAlreadyPresent
+			default:
+				present[song.ID] = true
+				entry := &model.PlaylistEntry{
+					PlaylistID: playlist.ID,
+					SongID:     song.ID,
+					Position:   int(count) + len(entries),
+					AddedByID:  addedBy,
+					AddedAt:    now,
+				}
+				entries = append(entries, entry)
+				results[i].Status = model.SongRefAdded
+			}
+		}
+
+		if len(entries) == 0 {
+			return nil
+		}
+		if err := tx.Create(&entries).Error; err != nil {
+			return err
+		}
+
+		// Report the IDs of the created entries, which are only known after the insert.
+		next := 0
+		for i := range results {
+			if results[i].Status == model.SongRefAdded {
+				results[i].EntryID = entries[next].ID
+				next++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// RemoveSongsFromPlaylist removes every entry of several songs from a playlist in one transaction
+// and closes the gaps. Songs that do not exist or are not in the playlist are reported in the results.
+func (g *GormDAO) RemoveSongsFromPlaylist(playlistID string, refs []model.SongRef, removedBy uint) ([]model.SongRefResult, error) {
+	results := make([]model.SongRefResult, len(refs))
+	revision := model.PlaylistRevision{AuthorID: removedBy, Action: model.RevisionSongsRemoved}
+	err := g.changeEntries(playlistID, revision, func(tx *gorm.DB, playlist *model.Playlist) error {
+		songs, err := resolveSongRefs(tx, refs)
+		if err != nil {
+			return err
+		}
+		present, err := playlistSongIDs(tx, playlist.ID)
+		if err != nil {
+			return err
+		}
+
+		var removeIDs []uint
+		for i, song := range songs {
+			results[i].SongRef = refs[i]
+			switch {
+			case song == nil:
+				results[i].Status = model.SongRefNotFound
+			case !present[song.ID]:
+				results[i].Status = model.SongRefNotPresent
+			default:
+				delete(present, song.ID)
+				removeIDs = append(removeIDs, song.ID)
+				results[i].Status = model.SongRefRemoved
+			}
+		}
+
+		if len(removeIDs) == 0 {
+			return nil
+		}
+		if err := tx.Where("playlist_id = ? AND song_id IN ?", playlist.ID, removeIDs).Delete(&model.PlaylistEntry{}).Error; err != nil {
+			return err
+		}
+
+		entries, err := orderedEntries(tx, playlist.ID)
+		if err != nil {
+			return err
+		}
+		return savePositions(tx, entries)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// RemovePlaylistEntry removes a single entry from a playlist, shifting the following entries up.
+func (g *GormDAO) RemovePlaylistEntry(playlistID string, entryID uint, removedBy uint) error {
+	revision := model.PlaylistRevision{AuthorID: removedBy, Action: model.RevisionSongsRemoved}
+	return g.changeEntries(playlistID, revision, func(tx *gorm.DB, playlist *model.Playlist) error {
+		var entry model.PlaylistEntry
+		if err := tx.Where("id = ? AND playlist_id = ?", entryID, playlist.ID).First(&entry).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrPlaylistEntryNotFound
+			}
+			return err
+		}
+
+		if err := tx.Delete(&entry).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&model.PlaylistEntry{}).
+			Where("playlist_id = ? AND position > ?", playlist.ID, entry.Position).
+			Update("position", gorm.Expr("position - 1")).Error
+	})
+}
+
+// RemoveDuplicateEntries removes the entries that duplicates picks from a playlist's entries, in
+// order and with their songs loaded, and returns how many it removed. The entries are read and
+// removed under the playlist's lock, so that concurrent changes cannot make it remove the wrong ones.
+func (g *GormDAO) RemoveDuplicateEntries(playlistID string, duplicates func(entries []model.PlaylistEntry) []model.PlaylistEntry, removedBy uint) (int, error) {
+	removed := 0
+	revision := model.PlaylistRevision{AuthorID: removedBy, Action: model.RevisionSongsRemoved}
+	err := g.changeEntries(playlistID, revision, func(tx *gorm.DB, playlist *model.Playlist) error {
+		entries, err := orderedEntries(tx.Preload("Song"), playlist.ID)
+		if err != nil {
+			return err
+		}
+		duplicateEntries := duplicates(entries)
+		if len(duplicateEntries) == 0 {
+			return nil
+		}
+		entryIDs := make([]uint, len(duplicateEntries))
+		for i, entry := range duplicateEntries {
+			entryIDs[i] = entry.ID
+		}
+		result := tx.Where("playlist_id = ? AND id IN ?", playlist.ID, entryIDs).Delete(&model.PlaylistEntry{})
+		if result.Error != nil {
+			return result.Error
+		}
+		removed = int(result.RowsAffected)
+
+		entries, err = orderedEntries(tx, playlist.ID)
+		if err != nil {
+			return err
+		}
+		return savePositions(tx, entries)
+	})
+	if err != nil {
+		return 0, err
 	}
+	return removed, nil
+}
+
+// MovePlaylistEntries moves rangeLength entries starting at rangeStart so that they are placed
+// before the entry currently at insertBefore. An insertBefore equal to the playlist length moves
+// the range to the end.
+func (g *GormDAO) MovePlaylistEntries(playlistID string, rangeStart, rangeLength, insertBefore int, movedBy uint) error {
+	revision := model.PlaylistRevision{AuthorID: movedBy, Action: model.RevisionSongsMoved}
+	return g.changeEntries(playlistID, revision, func(tx *gorm.DB, playlist *model.Playlist) error {
+		entries, err := orderedEntries(tx, playlist.ID)
+		if err != nil {
+			return err
+		}
+
+		rangeEnd := rangeStart + rangeLength
+		if rangeStart < 0 || rangeLength < 1 || rangeEnd > len(entries) || insertBefore < 0 || insertBefore > len(entries) {
+			return ErrInvalidPosition
+		}
+		if insertBefore >= rangeStart && insertBefore <= rangeEnd {
+			return nil // The range already sits at the requested spot.
+		}
+
+		moved := append([]model.PlaylistEntry(nil), entries[rangeStart:rangeEnd]...)
+		rest := append(append([]model.PlaylistEntry(nil), entries[:rangeStart]...), entries[rangeEnd:]...)
+		if insertBefore > rangeStart {
+			insertBefore -= rangeLength
+		}
+
+		reordered := make([]model.PlaylistEntry, 0, len(entries))
+		reordered = append(reordered, rest[:insertBefore]...)
+		reordered = append(reordered, moved...)
+		reordered = append(reordered, rest[insertBefore:]...)
+		return savePositions(tx, reordered)
+	})
+}
 
-	// Append the song to the playlist's Songs association
-	err := g.DB.Model(&playlist).Association("Songs").Append(&song)
+// ReplacePlaylistEntries replaces the contents of a playlist with the given songs, in order.
+// Songs that were already in the playlist keep who added them and when.
+func (g *GormDAO) ReplacePlaylistEntries(playlistID string, songIDs []uint, addedBy uint) error {
+	revision := model.PlaylistRevision{AuthorID: addedBy, Action: model.RevisionSongsReplaced}
+	return g.changeEntries(playlistID, revision, func(tx *gorm.DB, playlist *model.Playlist) error {
+		distinct := make(map[uint]bool, len(songIDs))
+		for _, id := range songIDs {
+			distinct[id] = true
+		}
+		var found int64
+		if len(distinct) > 0 {
+			if err := tx.Model(&model.Song{}).Where("id IN ?", songIDs).Count(&found).Error; err != nil {
+				return err
+			}
+		}
+		if int(found) != len(distinct) {
+			return ErrSongNotFound
+		}
+		return replaceEntries(tx, playlist.ID, songIDs, addedBy)
+	})
+}
+
+// replaceEntries replaces the entries of a playlist with songIDs in order. Songs that were already
+// in the playlist keep who added them and when.
+func replaceEntries(tx *gorm.DB, playlistID uint, songIDs []uint, addedBy uint) error {
+	existing, err := orderedEntries(tx, playlistID)
 	if err != nil {
-		return ErrFailedAssociation
+		return err
+	}
+	previous := make(map[uint][]model.PlaylistEntry)
+	for _, entry := range existing {
+		previous[entry.SongID] = append(previous[entry.SongID], entry)
+	}
+
+	if err := tx.Where("playlist_id = ?", playlistID).Delete(&model.PlaylistEntry{}).Error; err != nil {
+		return err
+	}
+	if len(songIDs) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	entries := make([]model.PlaylistEntry, len(songIDs))
+	for i, songID := range songIDs {
+		entries[i] = model.PlaylistEntry{PlaylistID: playlistID, SongID: songID, Position: i, AddedByID: addedBy, AddedAt: now}
+		if prev := previous[songID]; len(prev) > 0 {
+			entries[i].AddedByID, entries[i].AddedAt = prev[0].AddedByID, prev[0].AddedAt
+			previous[songID] = prev[1:]
+		}
+	}
+	return tx.CreateInBatches(&entries, 100).Error
+}
+
+/////////////////////////////
+// SMART PLAYLIST METHODS //
+/////////////////////////////
+
+// UpdatePlaylistSmartRules saves the rules of a playlist, recording the change as a revision by
+// changedBy; nil rules make it a static playlist.
+func (g *GormDAO) UpdatePlaylistSmartRules(playlist *model.Playlist, changedBy uint) error {
+	return g.changeSettings(playlist.ID, func(tx *gorm.DB, stored *model.Playlist) (*model.PlaylistRevision, error) {
+		previous, err := json.Marshal(stored.SmartRules)
+		if err != nil {
+			return nil, err
+		}
+		rules, err := json.Marshal(playlist.SmartRules)
+		if err != nil {
+			return nil, err
+		}
+		if err := tx.Model(playlist).Select("smart_rules").Updates(playlist).Error; err != nil {
+			return nil, err
+		}
+		if string(previous) == string(rules) {
+			return nil, nil
+		}
+		revision := &model.PlaylistRevision{AuthorID: changedBy, Action: model.RevisionRulesChanged}
+		if playlist.SmartRules != nil {
+			revision.Detail = string(rules)
+		}
+		return revision, nil
+	})
+}
+
+// FindSongsMatchingRules builds a query from the rules of a smart playlist owned by ownerID and
+// returns the IDs of the available songs that match, newest first. Ratings only count on
+// playlists the owner could use as a source.
+func (g *GormDAO) FindSongsMatchingRules(ownerID uint, rules model.SmartRules, limit int) ([]uint, error) {
+	query := g.DB.Model(&model.Song{}).Where("songs.unavailable = ?", false)
+	if len(rules.Artists) > 0 {
+		query = query.Where("songs.artist_name IN ?", rules.Artists)
+	}
+	if rules.Album != "" {
+		query = query.Where("songs.album_name = ?", rules.Album)
+	}
+	if rules.AddedAfter != nil {
+		query = query.Where("songs.created_at > ?", *rules.AddedAfter)
+	}
+	if rules.RatingAbove != nil {
+		rated := g.DB.Model(&model.Rating{}).Select("playlist_id").
+			Where("playlist_id IN (?)", sourcePlaylistIDs(g.DB, ownerID)).
+			Group("playlist_id").Having("AVG(score) > ?", *rules.RatingAbove)
+		query = query.Where("songs.id IN (?)", g.DB.Model(&model.PlaylistEntry{}).Select("song_id").Where("playlist_id IN (?)", rated))
+	}
+	if rules.MinPlayCount != nil {
+		query = query.Where("songs.id IN (?)", g.DB.Model(&model.SongPlayCount{}).Select("song_id").Where("play_count >= ?", *rules.MinPlayCount))
+	}
+	if rules.LikedByMe {
+		query = query.Where("songs.id IN (?)", g.DB.Model(&model.LikedSong{}).Select("song_id").Where("user_id = ?", ownerID))
+	}
+
+	var songIDs []uint
+	err := query.Order("songs.created_at DESC, songs.id DESC").Limit(limit).Pluck("songs.id", &songIDs).Error
+	return songIDs, err
+}
+
+// MaterializeSmartPlaylist replaces the entries of a smart playlist with songIDs in order, on
+// behalf of refreshedBy, or of the server when zero. Songs that were already in the playlist keep
+// the date they were first added.
+func (g *GormDAO) MaterializeSmartPlaylist(playlistID uint, songIDs []uint, materializedAt time.Time, refreshedBy uint) error {
+	revision := model.PlaylistRevision{AuthorID: refreshedBy, Action: model.RevisionRefreshed}
+	return g.changeEntries(strconv.FormatUint(uint64(playlistID), 10), revision, func(tx *gorm.DB, playlist *model.Playlist) error {
+		if err := replaceEntries(tx, playlist.ID, songIDs, playlist.UserID); err != nil {
+			return err
+		}
+		return tx.Model(playlist).UpdateColumn("materialized_at", materializedAt).Error
+	})
+}
+
+// GetStaleSmartPlaylists retrieves smart playlists that were not materialized since
+// materializedBefore, oldest first, leaving out excludeIDs.
+func (g *GormDAO) GetStaleSmartPlaylists(materializedBefore time.Time, excludeIDs []uint, limit int) ([]model.Playlist, error) {
+	var playlists []model.Playlist
+	query := g.DB.Where("smart_rules IS NOT NULL AND (materialized_at IS NULL OR materialized_at < ?)", materializedBefore)
+	if len(excludeIDs) > 0 {
+		query = query.Where("id NOT IN ?", excludeIDs)
 	}
+	err := query.Order("materialized_at, id").Limit(limit).Find(&playlists).Error
+	return playlists, err
+}
+
+//////////////////////
+// HISTORY METHODS //
+//////////////////////
 
+// fillAuthorUsernames sets the author username of each revision made by a user.
+func fillAuthorUsernames(db *gorm.DB, revisions []model.PlaylistRevision) error {
+	var authorIDs []uint
+	for _, revision := range revisions {
+		if revision.AuthorID != 0 {
+			authorIDs = append(authorIDs, revision.AuthorID)
+		}
+	}
+	if len(authorIDs) == 0 {
+		return nil
+	}
+	var users []model.User
+	if err := db.Select("id", "username").Where("id IN ?", authorIDs).Find(&users).Error; err != nil {
+		return err
+	}
+	usernames := make(map[uint]string, len(users))
+	for _, user := range users {
+		usernames[user.ID] = user.Username
+	}
+	for i := range revisions {
+		revisions[i].AuthorUsername = usernames[revisions[i].AuthorID]
+	}
 	return nil
 }
 
-func (g *GormDAO) RemoveSongFromPlaylist(playlistID, songID string) error {
-	// Convert string IDs to their respective types
-	pID, _ := strconv.ParseUint(playlistID, 10, 64)
-	sID, _ := strconv.ParseUint(songID, 10, 64)
+// GetPlaylistRevisions retrieves up to limit revisions of a playlist numbered below before, newest
+// first. A zero before starts from the latest revision.
+func (g *GormDAO) GetPlaylistRevisions(playlistID uint, before, limit int) ([]model.PlaylistRevision, error) {
+	query := g.DB.Where("playlist_id = ?", playlistID)
+	if before > 0 {
+		query = query.Where("number < ?", before)
+	}
+	var revisions []model.PlaylistRevision
+	if err := query.Order("number DESC").Limit(limit).Find(&revisions).Error; err != nil {
+		return nil, err
+	}
+	return revisions, fillAuthorUsernames(g.DB, revisions)
+}
+
+// GetPlaylistRevision retrieves a revision of a playlist by its number.
+func (g *GormDAO) GetPlaylistRevision(playlistID uint, number int) (*model.PlaylistRevision, error) {
+	var revision model.PlaylistRevision
+	err := g.DB.Where("playlist_id = ? AND number = ?", playlistID, number).First(&revision).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRevisionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	revisions := []model.PlaylistRevision{revision}
+	if err := fillAuthorUsernames(g.DB, revisions); err != nil {
+		return nil, err
+	}
+	return &revisions[0], nil
+}
 
-	var playlist model.Playlist
-	if err := g.DB.First(&playlist, pID).Error; err != nil {
-		return ErrPlaylistNotFound // Custom error
+// RestorePlaylistRevision puts back the songs a playlist had at a revision, recording the restore
+// as a new revision. Songs that no longer exist are left out.
+func (g *GormDAO) RestorePlaylistRevision(playlistID string, number int, restoredBy uint) error {
+	revision := model.PlaylistRevision{AuthorID: restoredBy, Action: model.RevisionRestored, RestoredFrom: number}
+	return g.changeEntries(playlistID, revision, func(tx *gorm.DB, playlist *model.Playlist) error {
+		var restored model.PlaylistRevision
+		err := tx.Where("playlist_id = ? AND number = ?", playlist.ID, number).First(&restored).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrRevisionNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		var existing []uint
+		if len(restored.SongIDs) > 0 {
+			if err := tx.Model(&model.Song{}).Where("id IN ?", restored.SongIDs).Pluck("id", &existing).Error; err != nil {
+				return err
+			}
+		}
+		exists := make(map[uint]bool, len(existing))
+		for _, id := range existing {
+			exists[id] = true
+		}
+		songIDs := make([]uint, 0, len(restored.SongIDs))
+		for _, id := range restored.SongIDs {
+			if exists[id] {
+				songIDs = append(songIDs, id)
+			}
+		}
+		return replaceEntries(tx, playlist.ID, songIDs, restoredBy)
+	})
+}
+
+///////////////////////////
+// COLLABORATOR METHODS //
+///////////////////////////
+
+// SavePlaylistCollaborator creates or updates a collaborator of a playlist.
+func (g *GormDAO) SavePlaylistCollaborator(collaborator *model.PlaylistCollaborator) error {
+	return g.DB.Omit(clause.Associations).Save(collaborator).Error
+}
+
+// GetPlaylistCollaborator retrieves the collaborator record of a user on a playlist, whatever its status.
+func (g *GormDAO) GetPlaylistCollaborator(playlistID, userID uint) (*model.PlaylistCollaborator, error) {
+	var collaborator model.PlaylistCollaborator
+	err := g.DB.Where("playlist_id = ? AND user_id = ?", playlistID, userID).First(&collaborator).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrCollaboratorNotFound
 	}
+	return &collaborator, err
+}
 
-	// Use the Association method to remove the song from the playlist
-	err := g.DB.Model(&playlist).Association("Songs").Delete(&model.Song{Model: gorm.Model{ID: uint(sID)}})
+// GetPlaylistCollaborators retrieves the collaborators of a playlist in the order they were invited.
+func (g *GormDAO) GetPlaylistCollaborators(playlistID uint) ([]model.PlaylistCollaborator, error) {
+	var collaborators []model.PlaylistCollaborator
+	err := g.DB.Preload("User").Where("playlist_id = ?", playlistID).Order("id").Find(&collaborators).Error
 	if err != nil {
-		return err // Handle error
+		return nil, err
+	}
+	for i := range collaborators {
+		collaborators[i].Username = collaborators[i].User.Username
 	}
+	return collaborators, nil
+}
+
+// GetPendingInvites retrieves the invites a user has not answered yet, newest first, with their playlists.
+func (g *GormDAO) GetPendingInvites(userID uint) ([]model.PlaylistCollaborator, error) {
+	var invites []model.PlaylistCollaborator
+	err := g.DB.Preload("Playlist").
+		Where("user_id = ? AND status = ?", userID, model.InvitePending).
+		Order("id DESC").Find(&invites).Error
+	return invites, err
+}
 
+// DeletePlaylistCollaborator removes a user's collaborator record from a playlist.
+func (g *GormDAO) DeletePlaylistCollaborator(playlistID, userID uint) error {
+	result := g.DB.Where("playlist_id = ? AND user_id = ?", playlistID, userID).Delete(&model.PlaylistCollaborator{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrCollaboratorNotFound
+	}
 	return nil
 }
+
+/////////////////////
+// SOCIAL METHODS //
+/////////////////////
+
+// CreateFollow records a follow. Following something twice is not an error.
+func (g *GormDAO) CreateFollow(follow *model.Follow) error {
+	return g.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(follow).Error
+}
+
+// DeleteFollow removes a follow. Unfollowing something that is not followed is not an error.
+func (g *GormDAO) DeleteFollow(followerID uint, targetType string, targetID uint) error {
+	return g.DB.Where("follower_id = ? AND target_type = ? AND target_id = ?", followerID, targetType, targetID).
+		Delete(&model.Follow{}).Error
+}
+
+// CountFollowers counts the followers of a user or playlist.
+func (g *GormDAO) CountFollowers(targetType string, targetID uint) (int64, error) {
+	var count int64
+	err := g.DB.Model(&model.Follow{}).Where("target_type = ? AND target_id = ?", targetType, targetID).Count(&count).Error
+	return count, err
+}
+
+// CountFollowing counts the users and playlists a user follows.
+func (g *GormDAO) CountFollowing(followerID uint) (int64, error) {
+	var count int64
+	err := g.DB.Model(&model.Follow{}).Where("follower_id = ?", followerID).Count(&count).Error
+	return count, err
+}
+
+// CreateActivity appends an entry to the activity log.
+func (g *GormDAO) CreateActivity(activity *model.Activity) error {
+	return g.DB.Omit(clause.Associations).Create(activity).Error
+}
+
+// GetFeed retrieves, newest first, the activity of the users and playlists a user follows, on
+// playlists the user may see. Only activity with an ID below beforeID is returned when it is non-zero.
+func (g *GormDAO) GetFeed(userID, beforeID uint, limit int) ([]model.Activity, error) {
+	followed := func(targetType string) *gorm.DB {
+		return g.DB.Model(&model.Follow{}).Select("target_id").
+			Where("follower_id = ? AND target_type = ?", userID, targetType)
+	}
+
+	query := g.DB.Preload("Actor").Preload("Playlist").
+		Where("actor_id IN (?) OR playlist_id IN (?)", followed(model.FollowUser), followed(model.FollowPlaylist)).
+		Where("actor_id <> ? AND playlist_id IN (?)", userID, visiblePlaylistIDs(g.DB, userID))
+	if beforeID > 0 {
+		query = query.Where("id < ?", beforeID)
+	}
+
+	var activities []model.Activity
+	if err := query.Order("id DESC").Limit(limit).Find(&activities).Error; err != nil {
+		return nil, err
+	}
+	for i := range activities {
+		activities[i].ActorUsername = activities[i].Actor.Username
+		activities[i].PlaylistName = activities[i].Playlist.Name
+	}
+	return activities, nil
+}
+
+/////////////////////
+// RATING METHODS //
+/////////////////////
+
+// SaveRating records a user's rating of a playlist, replacing any earlier rating by the same user.
+func (g *GormDAO) SaveRating(rating *model.Rating) error {
+	return g.DB.Transaction(func(tx *gorm.DB) error {
+		var existing model.Rating
+		err := tx.Where("playlist_id = ? AND user_id = ?", rating.PlaylistID, rating.UserID).First(&existing).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return tx.Create(rating).Error
+		}
+		if err != nil {
+			return err
+		}
+		rating.Model = existing.Model
+		return tx.Save(rating).Error
+	})
+}
+
+///////////////////
+// PLAY METHODS //
+///////////////////
+
+// playEventBatchSize is the number of play events inserted per statement.
+const playEventBatchSize = 100
+
+// GetExistingSongIDs reports which of the given song IDs exist.
+func (g *GormDAO) GetExistingSongIDs(songIDs []uint) (map[uint]bool, error) {
+	existing := make(map[uint]bool, len(songIDs))
+	if len(songIDs) == 0 {
+		return existing, nil
+	}
+	var ids []uint
+	if err := g.DB.Model(&model.Song{}).Where("id IN ?", songIDs).Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		existing[id] = true
+	}
+	return existing, nil
+}
+
+// CreatePlayEvents stores play events and adds them to the per-song play counts.
+func (g *GormDAO) CreatePlayEvents(events []model.PlayEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	counts := make(map[uint]*model.SongPlayCount)
+	var songIDs []uint
+	for _, event := range events {
+		count, ok := counts[event.SongID]
+		if !ok {
+			playedAt := event.PlayedAt
+			count = &model.SongPlayCount{SongID: event.SongID, LastPlayedAt: &playedAt}
+			counts[event.SongID] = count
+			songIDs = append(songIDs, event.SongID)
+		}
+		count.PlayCount++
+		if event.PlayedAt.After(*count.LastPlayedAt) {
+			playedAt := event.PlayedAt
+			count.LastPlayedAt = &playedAt
+		}
+	}
+
+	return g.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).CreateInBatches(events, playEventBatchSize).Error; err != nil {
+			return err
+		}
+		// Update the counts in song order so that concurrent batches lock rows in the same order.
+		sort.Slice(songIDs, func(i, j int) bool { return songIDs[i] < songIDs[j] })
+		for _, songID := range songIDs {
+			count := counts[songID]
+			err := tx.Clauses(clause.OnConflict{
+				Columns: []clause.Column{{Name: "song_id"}},
+				DoUpdates: clause.Set{
+					{Column: clause.Column{Name: "play_count"}, Value: gorm.Expr("song_play_counts.play_count + ?", count.PlayCount)},
+					{Column: clause.Column{Name: "last_played_at"}, Value: gorm.Expr(
+						"CASE WHEN song_play_counts.last_played_at IS NULL OR song_play_counts.last_played_at < ? THEN ? ELSE song_play_counts.last_played_at END",
+						count.LastPlayedAt, count.LastPlayedAt)},
+				},
+			}).Create(count).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetPlayHistory retrieves a user's play events with their songs, most recently played first.
+// When beforeID is non-zero only events played before that event are returned.
+func (g *GormDAO) GetPlayHistory(userID, beforeID uint, limit int) ([]model.PlayEvent, error) {
+	query := g.DB.Preload("Song").Where("user_id = ?", userID)
+	if beforeID > 0 {
+		var cursor model.PlayEvent
+		err := g.DB.Where("id = ? AND user_id = ?", beforeID, userID).First(&cursor).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPlayEventNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("played_at < ? OR (played_at = ? AND id < ?)", cursor.PlayedAt, cursor.PlayedAt, cursor.ID)
+	}
+
+	var events []model.PlayEvent
+	err := query.Order("played_at DESC, id DESC").Limit(limit).Find(&events).Error
+	return events, err
+}
+
+// GetSongPlayCount retrieves the play count of a song. Songs that were never played have a zero count.
+func (g *GormDAO) GetSongPlayCount(songID uint) (*model.SongPlayCount, error) {
+	count := model.SongPlayCount{SongID: songID}
+	err := g.DB.Where("song_id = ?", songID).First(&count).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &count, nil
+	}
+	return &count, err
+}
+
+//////////////////////
+// LIBRARY METHODS //
+//////////////////////
+
+// LikeSong adds a song to a user's library. Liking a song twice keeps the original date.
+func (g *GormDAO) LikeSong(userID, songID uint) error {
+	var count int64
+	if err := g.DB.Model(&model.Song{}).Where("id = ?", songID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrSongNotFound
+	}
+	return g.DB.Clauses(clause.OnConflict{DoNothing: true}).Omit(clause.Associations).
+		Create(&model.LikedSong{UserID: userID, SongID: songID}).Error
+}
+
+// UnlikeSong removes a song from a user's library. Unliking a song that is not liked is not an error.
+func (g *GormDAO) UnlikeSong(userID, songID uint) error {
+	return g.DB.Where("user_id = ? AND song_id = ?", userID, songID).Delete(&model.LikedSong{}).Error
+}
+
+// GetLikedSongs retrieves a page of a user's liked songs ordered by when they were liked, along
+// with the total number of liked songs.
+func (g *GormDAO) GetLikedSongs(userID uint, oldestFirst bool, offset, limit int) ([]model.LikedSong, int64, error) {
+	var total int64
+	if err := g.DB.Model(&model.LikedSong{}).Where("user_id = ?", userID).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	order := "created_at DESC, song_id DESC"
+	if oldestFirst {
+		order = "created_at, song_id"
+	}
+	var liked []model.LikedSong
+	err := g.DB.Preload("Song").Where("user_id = ?", userID).
+		Order(order).Offset(offset).Limit(limit).Find(&liked).Error
+	return liked, total, err
+}
+
+/////////////////////////////
+// RECOMMENDATION METHODS //
+/////////////////////////////
+
+// GetSongsByIDs retrieves the songs with the given IDs, in no particular order.
+func (g *GormDAO) GetSongsByIDs(songIDs []uint) ([]model.Song, error) {
+	var songs []model.Song
+	if len(songIDs) == 0 {
+		return songs, nil
+	}
+	err := g.DB.Where("id IN ?", songIDs).Find(&songs).Error
+	return songs, err
+}
+
+// GetCoOccurringSongs counts, for each available song outside songIDs, the playlists the user may
+// draw on in which it appears together with at least one of songIDs, and returns the most frequent ones.
+func (g *GormDAO) GetCoOccurringSongs(userID uint, songIDs []uint, limit int) ([]model.SongOccurrence, error) {
+	var occurrences []model.SongOccurrence
+	if len(songIDs) == 0 {
+		return occurrences, nil
+	}
+	err := g.DB.Table("playlist_entries AS seed").
+		Select("other.song_id AS song_id, COUNT(DISTINCT other.playlist_id) AS occurrences").
+		Joins("JOIN playlist_entries AS other ON other.playlist_id = seed.playlist_id").
+		Joins("JOIN playlists ON playlists.id = seed.playlist_id AND playlists.deleted_at IS NULL").
+		Joins("JOIN songs ON songs.id = other.song_id AND songs.deleted_at IS NULL AND songs.unavailable = ?", false).
+		Where("seed.song_id IN ? AND other.song_id NOT IN ?", songIDs, songIDs).
+		Where("seed.playlist_id IN (?)", sourcePlaylistIDs(g.DB, userID)).
+		Group("other.song_id").
+		Order("occurrences DESC, other.song_id").
+		Limit(limit).
+		Scan(&occurrences).Error
+	return occurrences, err
+}
+
+// GetSongsByArtists retrieves available songs by any of the given artists, excluding excludeIDs,
+// most played first.
+func (g *GormDAO) GetSongsByArtists(artists []string, excludeIDs []uint, limit int) ([]model.Song, error) {
+	var songs []model.Song
+	if len(artists) == 0 {
+		return songs, nil
+	}
+	query := g.DB.Joins("LEFT JOIN song_play_counts ON song_play_counts.song_id = songs.id").
+		Where("songs.artist_name IN ? AND songs.unavailable = ?", artists, false)
+	if len(excludeIDs) > 0 {
+		query = query.Where("songs.id NOT IN ?", excludeIDs)
+	}
+	err := query.Order("COALESCE(song_play_counts.play_count, 0) DESC, songs.id").Limit(limit).Find(&songs).Error
+	return songs, err
+}
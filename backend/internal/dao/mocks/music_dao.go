@@ -1,6 +1,8 @@
 package mocks
 
 import (
+	"time"
+
 	"github.com/kaiohenricunha/go-music-k8s/backend/internal/model"
 	"github.com/stretchr/testify/mock"
 )
@@ -28,6 +30,48 @@ func (_m *MusicDAO) CreateUser(user *model.User) error {
 	return r0
 }
 
+// UsernameExists mocks the UsernameExists method
+func (_m *MusicDAO) UsernameExists(username string) (bool, error) {
+	ret := _m.Called(username)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(string) bool); ok {
+		r0 = rf(username)
+	} else {
+		r0 = ret.Bool(0)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(username)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// EmailExists mocks the EmailExists method
+func (_m *MusicDAO) EmailExists(email string) (bool, error) {
+	ret := _m.Called(email)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(string) bool); ok {
+		r0 = rf(email)
+	} else {
+		r0 = ret.Bool(0)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(email)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetUserByID mocks the GetUserByID method
 func (_m *MusicDAO) GetUserByID(userID uint) (*model.User, error) {
 	ret := _m.Called(userID)
@@ -97,40 +141,45 @@ func (_m *MusicDAO) GetUserByUsername(username string) (*model.User, error) {
 	return r0, r1
 }
 
-////////////////////////////////
-// SONG METHODS //
-////////////////////////////////
+// GetUserByEmail mocks the GetUserByEmail method
+func (_m *MusicDAO) GetUserByEmail(email string) (*model.User, error) {
+	ret := _m.Called(email)
 
-// CreateSong mocks the CreateSong method
-func (_m *MusicDAO) CreateSong(song *model.Song) error {
-	ret := _m.Called(song)
+	var r0 *model.User
+	if rf, ok := ret.Get(0).(func(string) *model.User); ok {
+		r0 = rf(email)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.User)
+		}
+	}
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(*model.Song) error); ok {
-		r0 = rf(song)
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(email)
 	} else {
-		r0 = ret.Error(0)
+		r1 = ret.Error(1)
 	}
 
-	return r0
+	return r0, r1
 }
 
-// GetAllSongs mocks the GetAllSongs method
-func (_m *MusicDAO) GetAllSongs() ([]model.Song, error) {
-	ret := _m.Called()
+// GetUserIdentity mocks the GetUserIdentity method
+func (_m *MusicDAO) GetUserIdentity(issuer, subject string) (*model.UserIdentity, error) {
+	ret := _m.Called(issuer, subject)
 
-	var r0 []model.Song
-	if rf, ok := ret.Get(0).(func() []model.Song); ok {
-		r0 = rf()
+	var r0 *model.UserIdentity
+	if rf, ok := ret.Get(0).(func(string, string) *model.UserIdentity); ok {
+		r0 = rf(issuer, subject)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]model.Song)
+			r0 = ret.Get(0).(*model.UserIdentity)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func() error); ok {
-		r1 = rf()
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(issuer, subject)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -138,45 +187,50 @@ func (_m *MusicDAO) GetAllSongs() ([]model.Song, error) {
 	return r0, r1
 }
 
-// GetSongByID mocks the GetSongByID method
-func (_m *MusicDAO) GetSongByID(songID string) (*model.Song, error) {
-	ret := _m.Called(songID)
+// CreateUserIdentity mocks the CreateUserIdentity method
+func (_m *MusicDAO) CreateUserIdentity(identity *model.UserIdentity) error {
+	ret := _m.Called(identity)
 
-	var r0 *model.Song
-	if rf, ok := ret.Get(0).(func(string) *model.Song); ok {
-		r0 = rf(songID)
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*model.UserIdentity) error); ok {
+		r0 = rf(identity)
 	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*model.Song)
-		}
+		r0 = ret.Error(0)
 	}
 
-	var r1 error
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(songID)
+	return r0
+}
+
+// VerifyUserEmail mocks the VerifyUserEmail method
+func (_m *MusicDAO) VerifyUserEmail(userID uint, email string, at time.Time) error {
+	ret := _m.Called(userID, email, at)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uint, string, time.Time) error); ok {
+		r0 = rf(userID, email, at)
 	} else {
-		r1 = ret.Error(1)
+		r0 = ret.Error(0)
 	}
 
-	return r0, r1
+	return r0
 }
 
-// GetSongByNameAndArtist mocks the GetSongByNameAndArtist method
-func (_m *MusicDAO) GetSongByNameAndArtist(songName, artistName string) (*model.Song, error) {
-	ret := _m.Called(songName, artistName)
+// ResetUserPassword mocks the ResetUserPassword method
+func (_m *MusicDAO) ResetUserPassword(userID uint, oldHash, newHash string, at time.Time) ([]model.APIToken, error) {
+	ret := _m.Called(userID, oldHash, newHash, at)
 
-	var r0 *model.Song
-	if rf, ok := ret.Get(0).(func(string, string) *model.Song); ok {
-		r0 = rf(songName, artistName)
+	var r0 []model.APIToken
+	if rf, ok := ret.Get(0).(func(uint, string, string, time.Time) []model.APIToken); ok {
+		r0 = rf(userID, oldHash, newHash, at)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*model.Song)
+			r0 = ret.Get(0).([]model.APIToken)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string, string) error); ok {
-		r1 = rf(songName, artistName)
+	if rf, ok := ret.Get(1).(func(uint, string, string, time.Time) error); ok {
+		r1 = rf(userID, oldHash, newHash, at)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -184,22 +238,26 @@ func (_m *MusicDAO) GetSongByNameAndArtist(songName, artistName string) (*model.
 	return r0, r1
 }
 
-// GetSongFromSpotifyByID mocks the GetSongFromSpotifyByID method
-func (_m *MusicDAO) GetSongFromSpotifyByID(spotifyID string) (*model.Song, error) {
-	ret := _m.Called(spotifyID)
+////////////////////////////////
+// LOGIN METHODS //
+////////////////////////////////
 
-	var r0 *model.Song
-	if rf, ok := ret.Get(0).(func(string) *model.Song); ok {
-		r0 = rf(spotifyID)
+// GetLoginThrottles mocks the GetLoginThrottles method
+func (_m *MusicDAO) GetLoginThrottles(keys []string) ([]model.LoginThrottle, error) {
+	ret := _m.Called(keys)
+
+	var r0 []model.LoginThrottle
+	if rf, ok := ret.Get(0).(func([]string) []model.LoginThrottle); ok {
+		r0 = rf(keys)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*model.Song)
+			r0 = ret.Get(0).([]model.LoginThrottle)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(spotifyID)
+	if rf, ok := ret.Get(1).(func([]string) error); ok {
+		r1 = rf(keys)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -207,22 +265,22 @@ func (_m *MusicDAO) GetSongFromSpotifyByID(spotifyID string) (*model.Song, error
 	return r0, r1
 }
 
-// SearchSongsFromSpotify mocks the SearchSongsFromSpotify method
-func (_m *MusicDAO) SearchSongsFromSpotify(trackName, artistName string) ([]model.Song, error) {
-	ret := _m.Called(trackName, artistName)
+// RecordLoginFailure mocks the RecordLoginFailure method
+func (_m *MusicDAO) RecordLoginFailure(key string, at, resetBefore time.Time) (*model.LoginThrottle, error) {
+	ret := _m.Called(key, at, resetBefore)
 
-	var r0 []model.Song
-	if rf, ok := ret.Get(0).(func(string, string) []model.Song); ok {
-		r0 = rf(trackName, artistName)
+	var r0 *model.LoginThrottle
+	if rf, ok := ret.Get(0).(func(string, time.Time, time.Time) *model.LoginThrottle); ok {
+		r0 = rf(key, at, resetBefore)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]model.Song)
+			r0 = ret.Get(0).(*model.LoginThrottle)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string, string) error); ok {
-		r1 = rf(trackName, artistName)
+	if rf, ok := ret.Get(1).(func(string, time.Time, time.Time) error); ok {
+		r1 = rf(key, at, resetBefore)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -230,26 +288,82 @@ func (_m *MusicDAO) SearchSongsFromSpotify(trackName, artistName string) ([]mode
 	return r0, r1
 }
 
+// LockLogin mocks the LockLogin method
+func (_m *MusicDAO) LockLogin(key string, until time.Time) error {
+	ret := _m.Called(key, until)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, time.Time) error); ok {
+		r0 = rf(key, until)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteLoginThrottle mocks the DeleteLoginThrottle method
+func (_m *MusicDAO) DeleteLoginThrottle(key string) error {
+	ret := _m.Called(key)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(key)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateAuditEvent mocks the CreateAuditEvent method
+func (_m *MusicDAO) CreateAuditEvent(event *model.AuditEvent) error {
+	ret := _m.Called(event)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*model.AuditEvent) error); ok {
+		r0 = rf(event)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 ////////////////////////////////
-// PLAYLIST METHODS //
+// API TOKEN METHODS //
 ////////////////////////////////
 
-// GetPlaylistByID mocks the GetPlaylistByID method
-func (_m *MusicDAO) GetPlaylistByID(playlistID string) (*model.Playlist, error) {
-	ret := _m.Called(playlistID)
+// CreateAPIToken mocks the CreateAPIToken method
+func (_m *MusicDAO) CreateAPIToken(token *model.APIToken) error {
+	ret := _m.Called(token)
 
-	var r0 *model.Playlist
-	if rf, ok := ret.Get(0).(func(string) *model.Playlist); ok {
-		r0 = rf(playlistID)
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*model.APIToken) error); ok {
+		r0 = rf(token)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetAPITokens mocks the GetAPITokens method
+func (_m *MusicDAO) GetAPITokens(userID uint) ([]model.APIToken, error) {
+	ret := _m.Called(userID)
+
+	var r0 []model.APIToken
+	if rf, ok := ret.Get(0).(func(uint) []model.APIToken); ok {
+		r0 = rf(userID)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*model.Playlist)
+			r0 = ret.Get(0).([]model.APIToken)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(playlistID)
+	if rf, ok := ret.Get(1).(func(uint) error); ok {
+		r1 = rf(userID)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -257,22 +371,22 @@ func (_m *MusicDAO) GetPlaylistByID(playlistID string) (*model.Playlist, error)
 	return r0, r1
 }
 
-// GetAllPlaylists mocks the GetAllPlaylists method
-func (_m *MusicDAO) GetAllPlaylists() ([]model.Playlist, error) {
-	ret := _m.Called()
+// GetAPITokenByHash mocks the GetAPITokenByHash method
+func (_m *MusicDAO) GetAPITokenByHash(tokenHash string) (*model.APIToken, error) {
+	ret := _m.Called(tokenHash)
 
-	var r0 []model.Playlist
-	if rf, ok := ret.Get(0).(func() []model.Playlist); ok {
-		r0 = rf()
+	var r0 *model.APIToken
+	if rf, ok := ret.Get(0).(func(string) *model.APIToken); ok {
+		r0 = rf(tokenHash)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]model.Playlist)
+			r0 = ret.Get(0).(*model.APIToken)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func() error); ok {
-		r1 = rf()
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(tokenHash)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -280,13 +394,13 @@ func (_m *MusicDAO) GetAllPlaylists() ([]model.Playlist, error) {
 	return r0, r1
 }
 
-// AddSongToPlaylist mocks the AddSongToPlaylist method
-func (_m *MusicDAO) AddSongToPlaylist(playlistID, songID string) error {
-	ret := _m.Called(playlistID, songID)
+// RevokeAPIToken mocks the RevokeAPIToken method
+func (_m *MusicDAO) RevokeAPIToken(userID, tokenID uint, at time.Time) error {
+	ret := _m.Called(userID, tokenID, at)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string, string) error); ok {
-		r0 = rf(playlistID, songID)
+	if rf, ok := ret.Get(0).(func(uint, uint, time.Time) error); ok {
+		r0 = rf(userID, tokenID, at)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -294,13 +408,13 @@ func (_m *MusicDAO) AddSongToPlaylist(playlistID, songID string) error {
 	return r0
 }
 
-// RemoveSongFromPlaylist mocks the RemoveSongFromPlaylist method
-func (_m *MusicDAO) RemoveSongFromPlaylist(playlistID, songID string) error {
-	ret := _m.Called(playlistID, songID)
+// UpdateAPITokenLastUsed mocks the UpdateAPITokenLastUsed method
+func (_m *MusicDAO) UpdateAPITokenLastUsed(tokenID uint, at time.Time) error {
+	ret := _m.Called(tokenID, at)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string, string) error); ok {
-		r0 = rf(playlistID, songID)
+	if rf, ok := ret.Get(0).(func(uint, time.Time) error); ok {
+		r0 = rf(tokenID, at)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -308,13 +422,93 @@ func (_m *MusicDAO) RemoveSongFromPlaylist(playlistID, songID string) error {
 	return r0
 }
 
-// GetSongBySpotifyID mocks the GetSongBySpotifyID method
-func (_m *MusicDAO) GetSongBySpotifyID(spotifyID string) (*model.Song, error) {
-	ret := _m.Called(spotifyID)
+////////////////////////////////
+// RATE LIMIT METHODS //
+////////////////////////////////
+
+// UpdateRateLimitBucket mocks the UpdateRateLimitBucket method
+func (_m *MusicDAO) UpdateRateLimitBucket(initial *model.RateLimitBucket, update func(bucket *model.RateLimitBucket)) error {
+	ret := _m.Called(initial, update)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*model.RateLimitBucket, func(*model.RateLimitBucket)) error); ok {
+		r0 = rf(initial, update)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteRateLimitBuckets mocks the DeleteRateLimitBuckets method
+func (_m *MusicDAO) DeleteRateLimitBuckets(refilledBefore time.Time) (int64, error) {
+	ret := _m.Called(refilledBefore)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(time.Time) int64); ok {
+		r0 = rf(refilledBefore)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(time.Time) error); ok {
+		r1 = rf(refilledBefore)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+////////////////////////////////
+// SONG METHODS //
+////////////////////////////////
+
+// CreateSong mocks the CreateSong method
+func (_m *MusicDAO) CreateSong(song *model.Song) error {
+	ret := _m.Called(song)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*model.Song) error); ok {
+		r0 = rf(song)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetAllSongs mocks the GetAllSongs method
+func (_m *MusicDAO) GetAllSongs() ([]model.Song, error) {
+	ret := _m.Called()
+
+	var r0 []model.Song
+	if rf, ok := ret.Get(0).(func() []model.Song); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Song)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSongByID mocks the GetSongByID method
+func (_m *MusicDAO) GetSongByID(songID string) (*model.Song, error) {
+	ret := _m.Called(songID)
 
 	var r0 *model.Song
 	if rf, ok := ret.Get(0).(func(string) *model.Song); ok {
-		r0 = rf(spotifyID)
+		r0 = rf(songID)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*model.Song)
@@ -323,10 +517,1031 @@ func (_m *MusicDAO) GetSongBySpotifyID(spotifyID string) (*model.Song, error) {
 
 	var r1 error
 	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(spotifyID)
+		r1 = rf(songID)
 	} else {
 		r1 = ret.Error(1)
 	}
 
 	return r0, r1
 }
+
+// GetSongByNameAndArtist mocks the GetSongByNameAndArtist method
+func (_m *MusicDAO) GetSongByNameAndArtist(songName, artistName string) (*model.Song, error) {
+	ret := _m.Called(songName, artistName)
+
+	var r0 *model.Song
+	if rf, ok := ret.Get(0).(func(string, string) *model.Song); ok {
+		r0 = rf(songName, artistName)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Song)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(songName, artistName)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindSongCandidates mocks the FindSongCandidates method
+func (_m *MusicDAO) FindSongCandidates(title string, limit int) ([]model.Song, error) {
+	ret := _m.Called(title, limit)
+
+	var r0 []model.Song
+	if rf, ok := ret.Get(0).(func(string, int) []model.Song); ok {
+		r0 = rf(title, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Song)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, int) error); ok {
+		r1 = rf(title, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSongFromSpotifyByID mocks the GetSongFromSpotifyByID method
+func (_m *MusicDAO) GetSongFromSpotifyByID(spotifyID string) (*model.Song, error) {
+	ret := _m.Called(spotifyID)
+
+	var r0 *model.Song
+	if rf, ok := ret.Get(0).(func(string) *model.Song); ok {
+		r0 = rf(spotifyID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Song)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(spotifyID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SearchSongsFromSpotify mocks the SearchSongsFromSpotify method
+func (_m *MusicDAO) SearchSongsFromSpotify(trackName, artistName string) ([]model.Song, error) {
+	ret := _m.Called(trackName, artistName)
+
+	var r0 []model.Song
+	if rf, ok := ret.Get(0).(func(string, string) []model.Song); ok {
+		r0 = rf(trackName, artistName)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Song)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(trackName, artistName)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetStaleSongs mocks the GetStaleSongs method
+func (_m *MusicDAO) GetStaleSongs(refreshedBefore time.Time, limit int) ([]model.Song, error) {
+	ret := _m.Called(refreshedBefore, limit)
+
+	var r0 []model.Song
+	if rf, ok := ret.Get(0).(func(time.Time, int) []model.Song); ok {
+		r0 = rf(refreshedBefore, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Song)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(time.Time, int) error); ok {
+		r1 = rf(refreshedBefore, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateSong mocks the UpdateSong method
+func (_m *MusicDAO) UpdateSong(song *model.Song) error {
+	ret := _m.Called(song)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*model.Song) error); ok {
+		r0 = rf(song)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpsertSongs mocks the UpsertSongs method
+func (_m *MusicDAO) UpsertSongs(songs []*model.Song) error {
+	ret := _m.Called(songs)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]*model.Song) error); ok {
+		r0 = rf(songs)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+////////////////////////////////
+// PLAYLIST METHODS //
+////////////////////////////////
+
+// CreatePlaylist mocks the CreatePlaylist method
+func (_m *MusicDAO) CreatePlaylist(playlist *model.Playlist) error {
+	ret := _m.Called(playlist)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*model.Playlist) error); ok {
+		r0 = rf(playlist)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetPlaylistByID mocks the GetPlaylistByID method
+func (_m *MusicDAO) GetPlaylistByID(playlistID string) (*model.Playlist, error) {
+	ret := _m.Called(playlistID)
+
+	var r0 *model.Playlist
+	if rf, ok := ret.Get(0).(func(string) *model.Playlist); ok {
+		r0 = rf(playlistID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Playlist)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(playlistID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAllPlaylists mocks the GetAllPlaylists method
+func (_m *MusicDAO) GetAllPlaylists() ([]model.Playlist, error) {
+	ret := _m.Called()
+
+	var r0 []model.Playlist
+	if rf, ok := ret.Get(0).(func() []model.Playlist); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Playlist)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AddSongToPlaylist mocks the AddSongToPlaylist method
+func (_m *MusicDAO) AddSongToPlaylist(playlistID string, songID string, position int, addedBy uint) (*model.PlaylistEntry, error) {
+	ret := _m.Called(playlistID, songID, position, addedBy)
+
+	var r0 *model.PlaylistEntry
+	if rf, ok := ret.Get(0).(func(string, string, int, uint) *model.PlaylistEntry); ok {
+		r0 = rf(playlistID, songID, position, addedBy)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.PlaylistEntry)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string, int, uint) error); ok {
+		r1 = rf(playlistID, songID, position, addedBy)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RemoveSongFromPlaylist mocks the RemoveSongFromPlaylist method
+func (_m *MusicDAO) RemoveSongFromPlaylist(playlistID, songID string, removedBy uint) error {
+	ret := _m.Called(playlistID, songID, removedBy)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, uint) error); ok {
+		r0 = rf(playlistID, songID, removedBy)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// AddSongsToPlaylist mocks the AddSongsToPlaylist method
+func (_m *MusicDAO) AddSongsToPlaylist(playlistID string, refs []model.SongRef, addedBy uint) ([]model.SongRefResult, error) {
+	ret := _m.Called(playlistID, refs, addedBy)
+
+	var r0 []model.SongRefResult
+	if rf, ok := ret.Get(0).(func(string, []model.SongRef, uint) []model.SongRefResult); ok {
+		r0 = rf(playlistID, refs, addedBy)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.SongRefResult)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, []model.SongRef, uint) error); ok {
+		r1 = rf(playlistID, refs, addedBy)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RemoveSongsFromPlaylist mocks the RemoveSongsFromPlaylist method
+func (_m *MusicDAO) RemoveSongsFromPlaylist(playlistID string, refs []model.SongRef, removedBy uint) ([]model.SongRefResult, error) {
+	ret := _m.Called(playlistID, refs, removedBy)
+
+	var r0 []model.SongRefResult
+	if rf, ok := ret.Get(0).(func(string, []model.SongRef, uint) []model.SongRefResult); ok {
+		r0 = rf(playlistID, refs, removedBy)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.SongRefResult)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, []model.SongRef, uint) error); ok {
+		r1 = rf(playlistID, refs, removedBy)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RemovePlaylistEntry mocks the RemovePlaylistEntry method
+func (_m *MusicDAO) RemovePlaylistEntry(playlistID string, entryID uint, removedBy uint) error {
+	ret := _m.Called(playlistID, entryID, removedBy)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, uint, uint) error); ok {
+		r0 = rf(playlistID, entryID, removedBy)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RemoveDuplicateEntries mocks the RemoveDuplicateEntries method
+func (_m *MusicDAO) RemoveDuplicateEntries(playlistID string, duplicates func(entries []model.PlaylistEntry) []model.PlaylistEntry, removedBy uint) (int, error) {
+	ret := _m.Called(playlistID, duplicates, removedBy)
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func(string, func([]model.PlaylistEntry) []model.PlaylistEntry, uint) int); ok {
+		r0 = rf(playlistID, duplicates, removedBy)
+	} else {
+		r0 = ret.Int(0)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, func([]model.PlaylistEntry) []model.PlaylistEntry, uint) error); ok {
+		r1 = rf(playlistID, duplicates, removedBy)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MovePlaylistEntries mocks the MovePlaylistEntries method
+func (_m *MusicDAO) MovePlaylistEntries(playlistID string, rangeStart int, rangeLength int, insertBefore int, movedBy uint) error {
+	ret := _m.Called(playlistID, rangeStart, rangeLength, insertBefore, movedBy)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, int, int, int, uint) error); ok {
+		r0 = rf(playlistID, rangeStart, rangeLength, insertBefore, movedBy)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ReplacePlaylistEntries mocks the ReplacePlaylistEntries method
+func (_m *MusicDAO) ReplacePlaylistEntries(playlistID string, songIDs []uint, addedBy uint) error {
+	ret := _m.Called(playlistID, songIDs, addedBy)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, []uint, uint) error); ok {
+		r0 = rf(playlistID, songIDs, addedBy)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetSongBySpotifyID mocks the GetSongBySpotifyID method
+func (_m *MusicDAO) GetSongBySpotifyID(spotifyID string) (*model.Song, error) {
+	ret := _m.Called(spotifyID)
+
+	var r0 *model.Song
+	if rf, ok := ret.Get(0).(func(string) *model.Song); ok {
+		r0 = rf(spotifyID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Song)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(spotifyID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPlaylistInfo mocks the GetPlaylistInfo method
+func (_m *MusicDAO) GetPlaylistInfo(playlistID string) (*model.Playlist, error) {
+	ret := _m.Called(playlistID)
+
+	var r0 *model.Playlist
+	if rf, ok := ret.Get(0).(func(string) *model.Playlist); ok {
+		r0 = rf(playlistID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Playlist)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(playlistID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SavePlaylistCollaborator mocks the SavePlaylistCollaborator method
+func (_m *MusicDAO) SavePlaylistCollaborator(collaborator *model.PlaylistCollaborator) error {
+	ret := _m.Called(collaborator)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*model.PlaylistCollaborator) error); ok {
+		r0 = rf(collaborator)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetPlaylistCollaborator mocks the GetPlaylistCollaborator method
+func (_m *MusicDAO) GetPlaylistCollaborator(playlistID, userID uint) (*model.PlaylistCollaborator, error) {
+	ret := _m.Called(playlistID, userID)
+
+	var r0 *model.PlaylistCollaborator
+	if rf, ok := ret.Get(0).(func(uint, uint) *model.PlaylistCollaborator); ok {
+		r0 = rf(playlistID, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.PlaylistCollaborator)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(uint, uint) error); ok {
+		r1 = rf(playlistID, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPlaylistCollaborators mocks the GetPlaylistCollaborators method
+func (_m *MusicDAO) GetPlaylistCollaborators(playlistID uint) ([]model.PlaylistCollaborator, error) {
+	ret := _m.Called(playlistID)
+
+	var r0 []model.PlaylistCollaborator
+	if rf, ok := ret.Get(0).(func(uint) []model.PlaylistCollaborator); ok {
+		r0 = rf(playlistID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.PlaylistCollaborator)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(uint) error); ok {
+		r1 = rf(playlistID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPendingInvites mocks the GetPendingInvites method
+func (_m *MusicDAO) GetPendingInvites(userID uint) ([]model.PlaylistCollaborator, error) {
+	ret := _m.Called(userID)
+
+	var r0 []model.PlaylistCollaborator
+	if rf, ok := ret.Get(0).(func(uint) []model.PlaylistCollaborator); ok {
+		r0 = rf(userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.PlaylistCollaborator)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(uint) error); ok {
+		r1 = rf(userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeletePlaylistCollaborator mocks the DeletePlaylistCollaborator method
+func (_m *MusicDAO) DeletePlaylistCollaborator(playlistID, userID uint) error {
+	ret := _m.Called(playlistID, userID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uint, uint) error); ok {
+		r0 = rf(playlistID, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetVisiblePlaylists mocks the GetVisiblePlaylists method
+func (_m *MusicDAO) GetVisiblePlaylists(userID uint) ([]model.Playlist, error) {
+	ret := _m.Called(userID)
+
+	var r0 []model.Playlist
+	if rf, ok := ret.Get(0).(func(uint) []model.Playlist); ok {
+		r0 = rf(userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Playlist)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(uint) error); ok {
+		r1 = rf(userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPlaylistByShareToken mocks the GetPlaylistByShareToken method
+func (_m *MusicDAO) GetPlaylistByShareToken(token string) (*model.Playlist, error) {
+	ret := _m.Called(token)
+
+	var r0 *model.Playlist
+	if rf, ok := ret.Get(0).(func(string) *model.Playlist); ok {
+		r0 = rf(token)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Playlist)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(token)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdatePlaylistSharing mocks the UpdatePlaylistSharing method
+func (_m *MusicDAO) UpdatePlaylistSharing(playlist *model.Playlist, changedBy uint) error {
+	ret := _m.Called(playlist, changedBy)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*model.Playlist, uint) error); ok {
+		r0 = rf(playlist, changedBy)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateFollow mocks the CreateFollow method
+func (_m *MusicDAO) CreateFollow(follow *model.Follow) error {
+	ret := _m.Called(follow)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*model.Follow) error); ok {
+		r0 = rf(follow)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteFollow mocks the DeleteFollow method
+func (_m *MusicDAO) DeleteFollow(followerID uint, targetType string, targetID uint) error {
+	ret := _m.Called(followerID, targetType, targetID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uint, string, uint) error); ok {
+		r0 = rf(followerID, targetType, targetID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CountFollowers mocks the CountFollowers method
+func (_m *MusicDAO) CountFollowers(targetType string, targetID uint) (int64, error) {
+	ret := _m.Called(targetType, targetID)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(string, uint) int64); ok {
+		r0 = rf(targetType, targetID)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, uint) error); ok {
+		r1 = rf(targetType, targetID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CountFollowing mocks the CountFollowing method
+func (_m *MusicDAO) CountFollowing(followerID uint) (int64, error) {
+	ret := _m.Called(followerID)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(uint) int64); ok {
+		r0 = rf(followerID)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(uint) error); ok {
+		r1 = rf(followerID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateActivity mocks the CreateActivity method
+func (_m *MusicDAO) CreateActivity(activity *model.Activity) error {
+	ret := _m.Called(activity)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*model.Activity) error); ok {
+		r0 = rf(activity)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetFeed mocks the GetFeed method
+func (_m *MusicDAO) GetFeed(userID, beforeID uint, limit int) ([]model.Activity, error) {
+	ret := _m.Called(userID, beforeID, limit)
+
+	var r0 []model.Activity
+	if rf, ok := ret.Get(0).(func(uint, uint, int) []model.Activity); ok {
+		r0 = rf(userID, beforeID, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Activity)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(uint, uint, int) error); ok {
+		r1 = rf(userID, beforeID, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SaveRating mocks the SaveRating method
+func (_m *MusicDAO) SaveRating(rating *model.Rating) error {
+	ret := _m.Called(rating)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*model.Rating) error); ok {
+		r0 = rf(rating)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetExistingSongIDs mocks the GetExistingSongIDs method
+func (_m *MusicDAO) GetExistingSongIDs(songIDs []uint) (map[uint]bool, error) {
+	ret := _m.Called(songIDs)
+
+	var r0 map[uint]bool
+	if rf, ok := ret.Get(0).(func([]uint) map[uint]bool); ok {
+		r0 = rf(songIDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[uint]bool)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func([]uint) error); ok {
+		r1 = rf(songIDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreatePlayEvents mocks the CreatePlayEvents method
+func (_m *MusicDAO) CreatePlayEvents(events []model.PlayEvent) error {
+	ret := _m.Called(events)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]model.PlayEvent) error); ok {
+		r0 = rf(events)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetPlayHistory mocks the GetPlayHistory method
+func (_m *MusicDAO) GetPlayHistory(userID, beforeID uint, limit int) ([]model.PlayEvent, error) {
+	ret := _m.Called(userID, beforeID, limit)
+
+	var r0 []model.PlayEvent
+	if rf, ok := ret.Get(0).(func(uint, uint, int) []model.PlayEvent); ok {
+		r0 = rf(userID, beforeID, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.PlayEvent)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(uint, uint, int) error); ok {
+		r1 = rf(userID, beforeID, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSongPlayCount mocks the GetSongPlayCount method
+func (_m *MusicDAO) GetSongPlayCount(songID uint) (*model.SongPlayCount, error) {
+	ret := _m.Called(songID)
+
+	var r0 *model.SongPlayCount
+	if rf, ok := ret.Get(0).(func(uint) *model.SongPlayCount); ok {
+		r0 = rf(songID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.SongPlayCount)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(uint) error); ok {
+		r1 = rf(songID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// LikeSong mocks the LikeSong method
+func (_m *MusicDAO) LikeSong(userID, songID uint) error {
+	ret := _m.Called(userID, songID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uint, uint) error); ok {
+		r0 = rf(userID, songID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UnlikeSong mocks the UnlikeSong method
+func (_m *MusicDAO) UnlikeSong(userID, songID uint) error {
+	ret := _m.Called(userID, songID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uint, uint) error); ok {
+		r0 = rf(userID, songID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetLikedSongs mocks the GetLikedSongs method
+func (_m *MusicDAO) GetLikedSongs(userID uint, oldestFirst bool, offset, limit int) ([]model.LikedSong, int64, error) {
+	ret := _m.Called(userID, oldestFirst, offset, limit)
+
+	var r0 []model.LikedSong
+	if rf, ok := ret.Get(0).(func(uint, bool, int, int) []model.LikedSong); ok {
+		r0 = rf(userID, oldestFirst, offset, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.LikedSong)
+		}
+	}
+
+	var r1 int64
+	if rf, ok := ret.Get(1).(func(uint, bool, int, int) int64); ok {
+		r1 = rf(userID, oldestFirst, offset, limit)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(uint, bool, int, int) error); ok {
+		r2 = rf(userID, oldestFirst, offset, limit)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetSongsByIDs mocks the GetSongsByIDs method
+func (_m *MusicDAO) GetSongsByIDs(songIDs []uint) ([]model.Song, error) {
+	ret := _m.Called(songIDs)
+
+	var r0 []model.Song
+	if rf, ok := ret.Get(0).(func([]uint) []model.Song); ok {
+		r0 = rf(songIDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Song)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func([]uint) error); ok {
+		r1 = rf(songIDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetCoOccurringSongs mocks the GetCoOccurringSongs method
+func (_m *MusicDAO) GetCoOccurringSongs(userID uint, songIDs []uint, limit int) ([]model.SongOccurrence, error) {
+	ret := _m.Called(userID, songIDs, limit)
+
+	var r0 []model.SongOccurrence
+	if rf, ok := ret.Get(0).(func(uint, []uint, int) []model.SongOccurrence); ok {
+		r0 = rf(userID, songIDs, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.SongOccurrence)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(uint, []uint, int) error); ok {
+		r1 = rf(userID, songIDs, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSongsByArtists mocks the GetSongsByArtists method
+func (_m *MusicDAO) GetSongsByArtists(artists []string, excludeIDs []uint, limit int) ([]model.Song, error) {
+	ret := _m.Called(artists, excludeIDs, limit)
+
+	var r0 []model.Song
+	if rf, ok := ret.Get(0).(func([]string, []uint, int) []model.Song); ok {
+		r0 = rf(artists, excludeIDs, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Song)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func([]string, []uint, int) error); ok {
+		r1 = rf(artists, excludeIDs, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdatePlaylistSmartRules mocks the UpdatePlaylistSmartRules method
+func (_m *MusicDAO) UpdatePlaylistSmartRules(playlist *model.Playlist, changedBy uint) error {
+	ret := _m.Called(playlist, changedBy)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*model.Playlist, uint) error); ok {
+		r0 = rf(playlist, changedBy)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// FindSongsMatchingRules mocks the FindSongsMatchingRules method
+func (_m *MusicDAO) FindSongsMatchingRules(ownerID uint, rules model.SmartRules, limit int) ([]uint, error) {
+	ret := _m.Called(ownerID, rules, limit)
+
+	var r0 []uint
+	if rf, ok := ret.Get(0).(func(uint, model.SmartRules, int) []uint); ok {
+		r0 = rf(ownerID, rules, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]uint)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(uint, model.SmartRules, int) error); ok {
+		r1 = rf(ownerID, rules, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MaterializeSmartPlaylist mocks the MaterializeSmartPlaylist method
+func (_m *MusicDAO) MaterializeSmartPlaylist(playlistID uint, songIDs []uint, materializedAt time.Time, refreshedBy uint) error {
+	ret := _m.Called(playlistID, songIDs, materializedAt, refreshedBy)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uint, []uint, time.Time, uint) error); ok {
+		r0 = rf(playlistID, songIDs, materializedAt, refreshedBy)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetStaleSmartPlaylists mocks the GetStaleSmartPlaylists method
+func (_m *MusicDAO) GetStaleSmartPlaylists(materializedBefore time.Time, excludeIDs []uint, limit int) ([]model.Playlist, error) {
+	ret := _m.Called(materializedBefore, excludeIDs, limit)
+
+	var r0 []model.Playlist
+	if rf, ok := ret.Get(0).(func(time.Time, []uint, int) []model.Playlist); ok {
+		r0 = rf(materializedBefore, excludeIDs, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Playlist)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(time.Time, []uint, int) error); ok {
+		r1 = rf(materializedBefore, excludeIDs, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPlaylistRevisions mocks the GetPlaylistRevisions method
+func (_m *MusicDAO) GetPlaylistRevisions(playlistID uint, before, limit int) ([]model.PlaylistRevision, error) {
+	ret := _m.Called(playlistID, before, limit)
+
+	var r0 []model.PlaylistRevision
+	if rf, ok := ret.Get(0).(func(uint, int, int) []model.PlaylistRevision); ok {
+		r0 = rf(playlistID, before, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.PlaylistRevision)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(uint, int, int) error); ok {
+		r1 = rf(playlistID, before, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPlaylistRevision mocks the GetPlaylistRevision method
+func (_m *MusicDAO) GetPlaylistRevision(playlistID uint, number int) (*model.PlaylistRevision, error) {
+	ret := _m.Called(playlistID, number)
+
+	var r0 *model.PlaylistRevision
+	if rf, ok := ret.Get(0).(func(uint, int) *model.PlaylistRevision); ok {
+		r0 = rf(playlistID, number)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.PlaylistRevision)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(uint, int) error); ok {
+		r1 = rf(playlistID, number)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RestorePlaylistRevision mocks the RestorePlaylistRevision method
+func (_m *MusicDAO) RestorePlaylistRevision(playlistID string, number int, restoredBy uint) error {
+	ret := _m.Called(playlistID, number, restoredBy)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, int, uint) error); ok {
+		r0 = rf(playlistID, number, restoredBy)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
@@ -0,0 +1,152 @@
+// Package mail sends the emails the API sends to users, such as email verification and password
+// reset links. SMTPSender delivers them; FileSender and LogSender keep them local for development.
+package mail
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrInvalidMessage is returned for messages that cannot be sent as they are, such as ones with
+// an invalid address or a line break in the subject.
+var ErrInvalidMessage = errors.New("invalid mail message")
+
+// Message is a plain text email to a single recipient.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender sends emails.
+type Sender interface {
+	Send(msg Message) error
+}
+
+// SMTPSender sends emails through an SMTP server, upgrading the connection with STARTTLS when the
+// server supports it.
+type SMTPSender struct {
+	Addr     string // host:port of the server.
+	From     string // Sender address, optionally with a name, e.g. "Music <noreply@example.com>".
+	Username string // Authenticates with PLAIN auth when set.
+	Password string
+}
+
+// Send delivers msg to the server.
+func (s *SMTPSender) Send(msg Message) error {
+	from, err := mail.ParseAddress(s.From)
+	if err != nil {
+		return fmt.Errorf("%w: invalid sender %q: %v", ErrInvalidMessage, s.From, err)
+	}
+	data, to, err := format(s.From, msg)
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if s.Username != "" {
+		host, _, err := net.SplitHostPort(s.Addr)
+		if err != nil {
+			return fmt.Errorf("invalid SMTP address %q: %w", s.Addr, err)
+		}
+		auth = smtp.PlainAuth("", s.Username, s.Password, host)
+	}
+	if err := smtp.SendMail(s.Addr, auth, from.Address, []string{to}, data); err != nil {
+		return fmt.Errorf("failed to send mail to %s: %w", to, err)
+	}
+	return nil
+}
+
+// FileSender writes each email to its own .eml file in Dir instead of sending it, so that links
+// in them can be followed during local development.
+type FileSender struct {
+	Dir  string
+	From string
+}
+
+// Send writes msg to a new file in s.Dir.
+func (s *FileSender) Send(msg Message) error {
+	data, _, err := format(s.From, msg)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.Dir, 0o700); err != nil {
+		return err
+	}
+	name := filepath.Join(s.Dir, fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405"), randomHex(4)))
+	if err := os.WriteFile(name, data, 0o600); err != nil {
+		return err
+	}
+	log.Printf("Wrote mail to %s with subject %q to %s", msg.To, msg.Subject, name)
+	return nil
+}
+
+// LogSender writes emails to the log instead of sending them. Their links can be used by anyone
+// who reads the log, so it must not be used in production.
+type LogSender struct{}
+
+// Send logs msg.
+func (LogSender) Send(msg Message) error {
+	log.Printf("Mail to %s with subject %q:\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
+
+// format returns msg as an RFC 5322 message and the bare recipient address.
+func format(from string, msg Message) ([]byte, string, error) {
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: invalid recipient %q: %v", ErrInvalidMessage, msg.To, err)
+	}
+	if strings.ContainsAny(msg.Subject, "\r\n") || strings.ContainsAny(from, "\r\n") {
+		return nil, "", fmt.Errorf("%w: headers must not contain line breaks", ErrInvalidMessage)
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", to.String())
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "Message-ID: <%s@%s>\r\n", randomHex(16), messageIDHost(from))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+
+	body := quotedprintable.NewWriter(&buf)
+	if _, err := body.Write([]byte(strings.ReplaceAll(msg.Body, "\n", "\r\n"))); err != nil {
+		return nil, "", err
+	}
+	if err := body.Close(); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), to.Address, nil
+}
+
+// messageIDHost returns the domain of the sender address, for unique message IDs.
+func messageIDHost(from string) string {
+	if address, err := mail.ParseAddress(from); err == nil {
+		if _, domain, ok := strings.Cut(address.Address, "@"); ok {
+			return domain
+		}
+	}
+	return "localhost"
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	// crypto/rand only fails if the system's randomness is unavailable; the IDs need not be secret.
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,62 @@
+package mail
+
+import (
+	"io"
+	"mime"
+	"mime/quotedprintable"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormat(t *testing.T) {
+	data, to, err := format("Music <noreply@example.com>", Message{
+		To:      "Alice <alice@example.com>",
+		Subject: "Réinitialiser",
+		Body:    "Open this link:\nhttps://example.com/reset-password?token=abc",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "alice@example.com", to)
+
+	parsed, err := mail.ReadMessage(strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	subject, _ := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	assert.Equal(t, "Réinitialiser", subject)
+	assert.Contains(t, parsed.Header.Get("Message-ID"), "@example.com>")
+	body := new(strings.Builder)
+	if _, err := io.Copy(body, quotedprintable.NewReader(parsed.Body)); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "Open this link:\r\nhttps://example.com/reset-password?token=abc", body.String())
+}
+
+func TestFormatRejectsHeaderInjection(t *testing.T) {
+	_, _, err := format("noreply@example.com", Message{To: "alice@example.com", Subject: "Hi\r\nBcc: eve@example.com"})
+	assert.ErrorIs(t, err, ErrInvalidMessage)
+	_, _, err = format("noreply@example.com", Message{To: "alice@example.com\r\nBcc: eve@example.com", Subject: "Hi"})
+	assert.ErrorIs(t, err, ErrInvalidMessage)
+}
+
+func TestFileSender(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mail")
+	sender := &FileSender{Dir: dir, From: "noreply@example.com"}
+
+	assert.NoError(t, sender.Send(Message{To: "alice@example.com", Subject: "Hi", Body: "Hello"}))
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, files, 1) {
+		data, _ := os.ReadFile(filepath.Join(dir, files[0].Name()))
+		assert.Contains(t, string(data), "To: <alice@example.com>")
+		assert.Contains(t, string(data), "Hello")
+	}
+}
@@ -1,15 +1,21 @@
 package model
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type User struct {
 	gorm.Model
-	FullName  string     `json:"full_name"`
-	Email     string     `gorm:"unique" json:"email"`
-	Username  string     `gorm:"unique" json:"username"`
-	Password  string     // Consider storing hashed passwords only
-	Role      string     `json:"role"`
-	Playlists []Playlist `gorm:"foreignKey:UserID" json:"playlists"`
+	FullName          string     `json:"full_name"`
+	Email             string     `gorm:"unique" json:"email"`
+	EmailVerifiedAt   *time.Time `gorm:"column:email_verified_at" json:"email_verified_at"` // When the user proved they own Email.
+	Username          string     `gorm:"unique" json:"username"`
+	Password          string     // Consider storing hashed passwords only
+	PasswordChangedAt *time.Time `gorm:"column:password_changed_at" json:"-"` // When the password was last reset; older access tokens are rejected.
+	Role              string     `json:"role"`
+	Playlists         []Playlist `gorm:"foreignKey:UserID" json:"playlists"`
 }
 
 type Song struct {
@@ -21,15 +27,304 @@ type Song struct {
 	AlbumImageURL string `gorm:"column:album_image_url" json:"album_image_url"`
 	PreviewURL    string `gorm:"column:preview_url" json:"preview_url"`
 	ExternalURL   string `gorm:"column:external_url" json:"external_url"`
+	// RefreshedAt is the last time the metadata was re-fetched from Spotify.
+	RefreshedAt *time.Time `gorm:"column:refreshed_at;index" json:"refreshed_at"`
+	// Unavailable is set when Spotify no longer returns the track.
+	Unavailable bool `gorm:"column:unavailable;default:false" json:"unavailable"`
 }
 
 type Playlist struct {
 	gorm.Model
-	Name             string   `gorm:"column:playlist_name" json:"playlist_name"`
-	UserID           uint     `gorm:"column:user_id" json:"user_id"`
-	PlaylistImageURL string   `gorm:"column:playlist_image_url" json:"playlist_image_url"`
-	Songs            []Song   `gorm:"many2many:playlist_songs;"`
-	Ratings          []Rating `gorm:"foreignKey:PlaylistID" json:"ratings"`
+	Name             string `gorm:"column:playlist_name" json:"playlist_name"`
+	UserID           uint   `gorm:"column:user_id" json:"user_id"`
+	PlaylistImageURL string `gorm:"column:playlist_image_url" json:"playlist_image_url"`
+	Visibility       string `gorm:"column:visibility;size:16;default:public;index" json:"visibility"`
+	// ShareToken is the unguessable part of the link to an unlisted playlist; it is only set while the playlist is unlisted.
+	ShareToken *string `gorm:"column:share_token;size:64;uniqueIndex" json:"share_token,omitempty"`
+	// SmartRules is set on smart playlists, whose entries are computed from the rules instead of edited.
+	SmartRules *SmartRules `gorm:"column:smart_rules;type:text;serializer:json" json:"smart_rules,omitempty"`
+	// MaterializedAt is the last time the entries of a smart playlist were computed.
+	MaterializedAt *time.Time `gorm:"column:materialized_at;index" json:"materialized_at,omitempty"`
+	// Songs lists the playlist's songs in order, one per entry; it is derived from Entries.
+	Songs   []Song          `gorm:"-"`
+	Entries []PlaylistEntry `gorm:"foreignKey:PlaylistID" json:"entries"`
+	Ratings []Rating        `gorm:"foreignKey:PlaylistID" json:"ratings"`
+}
+
+// Who can see a playlist besides its owner and collaborators.
+const (
+	VisibilityPublic   = "public"   // Everyone.
+	VisibilityUnlisted = "unlisted" // Anyone with the share link.
+	VisibilityPrivate  = "private"  // No one else.
+)
+
+// SmartRules select the songs of a smart playlist. A song must satisfy every rule that is set.
+type SmartRules struct {
+	Artists      []string   `json:"artists,omitempty"`        // Song is by one of these artists.
+	Album        string     `json:"album,omitempty"`          // Song is on this album.
+	AddedAfter   *time.Time `json:"added_after,omitempty"`    // Song was added to the catalog after this time.
+	RatingAbove  *float64   `json:"rating_above,omitempty"`   // Song is in a playlist rated above this on average.
+	MinPlayCount *int64     `json:"min_play_count,omitempty"` // Song was played at least this many times.
+	LikedByMe    bool       `json:"liked_by_me,omitempty"`    // Song is in the playlist owner's library.
+	Limit        int        `json:"limit,omitempty"`          // Maximum number of songs, newest first.
+}
+
+// PlaylistEntry places a song at a position in a playlist. The same song may appear in several entries.
+type PlaylistEntry struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	PlaylistID uint      `gorm:"column:playlist_id;index:idx_playlist_entries_position,priority:1" json:"playlist_id"`
+	SongID     uint      `gorm:"column:song_id;index" json:"song_id"`
+	Song       Song      `gorm:"foreignKey:SongID" json:"-"`
+	Position   int       `gorm:"column:position;index:idx_playlist_entries_position,priority:2" json:"position"`
+	AddedByID  uint      `gorm:"column:added_by_id" json:"added_by_id"`
+	AddedAt    time.Time `gorm:"column:added_at" json:"added_at"`
+}
+
+// Kinds of change recorded in a playlist's history.
+const (
+	RevisionBaseline      = "baseline" // Contents found when history was first recorded for an older playlist.
+	RevisionCreated       = "created"
+	RevisionSongsAdded    = "songs_added"
+	RevisionSongsRemoved  = "songs_removed"
+	RevisionSongsMoved    = "songs_moved"
+	RevisionSongsReplaced = "songs_replaced"
+	RevisionRefreshed     = "refreshed" // A smart playlist was recomputed from its rules.
+	RevisionRestored      = "restored"
+
+	// Changes to a playlist's settings. Their revisions keep the songs of the previous revision,
+	// and restoring one only restores its songs.
+	RevisionVisibilityChanged = "visibility_changed" // Detail is the new visibility.
+	RevisionShareLinkRotated  = "share_link_rotated"
+	RevisionRulesChanged      = "rules_changed" // Detail is the new smart rules in JSON, or empty when they were removed.
+)
+
+// PlaylistRevision is a snapshot of a playlist's songs taken after each change to them or to the
+// playlist's settings. Number counts the revisions of a playlist from 1.
+type PlaylistRevision struct {
+	ID             uint      `gorm:"primaryKey" json:"-"`
+	PlaylistID     uint      `gorm:"column:playlist_id;uniqueIndex:idx_playlist_revisions_number,priority:1" json:"playlist_id"`
+	Number         int       `gorm:"column:number;uniqueIndex:idx_playlist_revisions_number,priority:2" json:"revision"`
+	AuthorID       uint      `gorm:"column:author_id" json:"author_id,omitempty"` // Zero for changes made by the server.
+	AuthorUsername string    `gorm:"-" json:"author_username,omitempty"`
+	Action         string    `gorm:"column:action;size:32" json:"action"`
+	RestoredFrom   int       `gorm:"column:restored_from" json:"restored_from,omitempty"`
+	Detail         string    `gorm:"column:detail;type:text" json:"detail,omitempty"`
+	SongIDs        []uint    `gorm:"column:song_ids;type:text;serializer:json" json:"-"`
+	SongCount      int       `gorm:"column:song_count" json:"song_count"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// ChangesSettings reports whether the revision records a change to the playlist's settings rather
+// than to its songs.
+func (r *PlaylistRevision) ChangesSettings() bool {
+	switch r.Action {
+	case RevisionVisibilityChanged, RevisionShareLinkRotated, RevisionRulesChanged:
+		return true
+	}
+	return false
+}
+
+// Roles a collaborator can be invited with.
+const (
+	CollaboratorEditor = "editor"
+	CollaboratorViewer = "viewer"
+)
+
+// States of a collaboration invite.
+const (
+	InvitePending  = "pending"
+	InviteAccepted = "accepted"
+	InviteDeclined = "declined"
+)
+
+// PlaylistCollaborator grants a user other than the owner access to a playlist once they accept the invite.
+type PlaylistCollaborator struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	PlaylistID  uint       `gorm:"column:playlist_id;uniqueIndex:idx_playlist_collaborators_user,priority:1" json:"playlist_id"`
+	UserID      uint       `gorm:"column:user_id;uniqueIndex:idx_playlist_collaborators_user,priority:2;index" json:"user_id"`
+	Username    string     `gorm:"-" json:"username,omitempty"`
+	Role        string     `gorm:"column:role;size:16" json:"role"`
+	Status      string     `gorm:"column:status;size:16" json:"status"`
+	InvitedByID uint       `gorm:"column:invited_by_id" json:"invited_by_id"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	RespondedAt *time.Time `gorm:"column:responded_at" json:"responded_at,omitempty"`
+	User        User       `gorm:"foreignKey:UserID" json:"-"`
+	Playlist    *Playlist  `gorm:"foreignKey:PlaylistID" json:"playlist,omitempty"`
+}
+
+// SongRef identifies a song either by its ID or by its Spotify ID.
+type SongRef struct {
+	SongID    uint   `json:"song_id,omitempty"`
+	SpotifyID string `json:"spotify_id,omitempty"`
+}
+
+// Outcomes reported for each song of a bulk playlist operation.
+const (
+	SongRefAdded          = "added"
+	SongRefRemoved        = "removed"
+	SongRefNotFound       = "not_found"
+	SongRefAlreadyPresent = "already_present"
+	SongRefNotPresent     = "not_present"
+)
+
+// SongRefResult reports what a bulk playlist operation did with one of the requested songs.
+type SongRefResult struct {
+	SongRef
+	Status  string `json:"status"`
+	EntryID uint   `json:"entry_id,omitempty"`
+}
+
+// Kinds of things a user can follow.
+const (
+	FollowUser     = "user"
+	FollowPlaylist = "playlist"
+)
+
+// Follow records that a user follows another user or a playlist.
+type Follow struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	FollowerID uint      `gorm:"column:follower_id;uniqueIndex:idx_follows_target,priority:1" json:"follower_id"`
+	TargetType string    `gorm:"column:target_type;size:16;uniqueIndex:idx_follows_target,priority:2;index:idx_follows_followers,priority:1" json:"target_type"`
+	TargetID   uint      `gorm:"column:target_id;uniqueIndex:idx_follows_target,priority:3;index:idx_follows_followers,priority:2" json:"target_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Kinds of activity shown in the feed.
+const (
+	ActivityPlaylistCreated = "playlist_created"
+	ActivitySongsAdded      = "songs_added"
+	ActivityPlaylistRated   = "playlist_rated"
+)
+
+// Activity is an append-only record of something a user did to a playlist.
+type Activity struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	ActorID       uint      `gorm:"column:actor_id;index" json:"actor_id"`
+	ActorUsername string    `gorm:"-" json:"actor_username,omitempty"`
+	Type          string    `gorm:"column:type;size:32" json:"type"`
+	PlaylistID    uint      `gorm:"column:playlist_id;index" json:"playlist_id"`
+	PlaylistName  string    `gorm:"-" json:"playlist_name,omitempty"`
+	SongID        uint      `gorm:"column:song_id" json:"song_id,omitempty"`       // First song added, for songs_added.
+	SongCount     int       `gorm:"column:song_count" json:"song_count,omitempty"` // Songs added, for songs_added.
+	Score         int       `gorm:"column:score" json:"score,omitempty"`           // Score given, for playlist_rated.
+	CreatedAt     time.Time `gorm:"index" json:"created_at"`
+	Actor         User      `gorm:"foreignKey:ActorID" json:"-"`
+	Playlist      Playlist  `gorm:"foreignKey:PlaylistID" json:"-"`
+}
+
+// PlayEvent records that a user listened to a song. Play events are written in large volumes, so
+// the table is insert-only and carries a single secondary index for the listening history.
+type PlayEvent struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	UserID     uint      `gorm:"column:user_id;index:idx_play_events_history,priority:1" json:"user_id"`
+	SongID     uint      `gorm:"column:song_id" json:"song_id"`
+	PlaylistID uint      `gorm:"column:playlist_id" json:"playlist_id,omitempty"` // Playlist the song was played from, if any.
+	PlayedAt   time.Time `gorm:"column:played_at;index:idx_play_events_history,priority:2" json:"played_at"`
+	DurationMS int       `gorm:"column:duration_ms" json:"duration_ms"`
+	CreatedAt  time.Time `json:"created_at"`
+	Song       *Song     `gorm:"foreignKey:SongID" json:"song,omitempty"`
+}
+
+// SongPlayCount aggregates the play events of a song, so counts can be read without scanning them.
+type SongPlayCount struct {
+	SongID       uint       `gorm:"primaryKey;autoIncrement:false" json:"song_id"`
+	PlayCount    int64      `gorm:"column:play_count" json:"play_count"`
+	LastPlayedAt *time.Time `gorm:"column:last_played_at" json:"last_played_at,omitempty"`
+}
+
+// SongOccurrence counts the playlists a song appears in.
+type SongOccurrence struct {
+	SongID      uint
+	Occurrences int64
+}
+
+// LikedSong puts a song in a user's library.
+type LikedSong struct {
+	UserID    uint      `gorm:"primaryKey;autoIncrement:false;index:idx_liked_songs_added,priority:1" json:"-"`
+	SongID    uint      `gorm:"primaryKey;autoIncrement:false" json:"-"`
+	CreatedAt time.Time `gorm:"index:idx_liked_songs_added,priority:2" json:"added_at"`
+	Song      Song      `gorm:"foreignKey:SongID" json:"song"`
+}
+
+// UserIdentity links a user to their account at an external OpenID Connect provider.
+type UserIdentity struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"column:user_id;index" json:"user_id"`
+	Issuer    string    `gorm:"column:issuer;size:191;uniqueIndex:idx_user_identity_subject" json:"issuer"`
+	Subject   string    `gorm:"column:subject;size:191;uniqueIndex:idx_user_identity_subject" json:"subject"` // The provider's ID of the account.
+	Email     string    `gorm:"column:email" json:"email"`                                                    // The verified email the account was linked by.
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Scopes of an API token. A token may only be used for the requests its scopes allow.
+const (
+	ScopeRead  = "read"  // GET requests.
+	ScopeWrite = "write" // Requests with any method; implies read.
+	ScopeAdmin = "admin" // Admin routes, for users with the admin role.
+)
+
+// APIToken is a personal access token that lets scripts call the API as a user without their
+// password. Only a SHA-256 hash of the token is stored; the token itself is shown once, when created.
+type APIToken struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	UserID     uint       `gorm:"column:user_id;index" json:"-"`
+	Name       string     `gorm:"column:name;size:100" json:"name"`
+	Prefix     string     `gorm:"column:prefix;size:16" json:"prefix"` // The start of the token, to tell tokens apart.
+	TokenHash  string     `gorm:"column:token_hash;size:64;uniqueIndex" json:"-"`
+	Scopes     []string   `gorm:"column:scopes;type:text;serializer:json" json:"scopes"`
+	ExpiresAt  time.Time  `gorm:"column:expires_at" json:"expires_at"`
+	LastUsedAt *time.Time `gorm:"column:last_used_at" json:"last_used_at"`
+	RevokedAt  *time.Time `gorm:"column:revoked_at;index" json:"-"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// HasScope reports whether the token grants scope. The write scope implies read.
+func (t *APIToken) HasScope(scope string) bool {
+	for _, s := range t.Scopes {
+		if s == scope || (scope == ScopeRead && s == ScopeWrite) {
+			return true
+		}
+	}
+	return false
+}
+
+// LoginThrottle counts the recent failed logins for a username or a client IP. Key is
+// "username:<name>" or "ip:<address>".
+type LoginThrottle struct {
+	Key           string     `gorm:"primaryKey;size:191"`
+	Failures      int        `gorm:"column:failures"`
+	LastFailureAt time.Time  `gorm:"column:last_failure_at"`
+	LockedUntil   *time.Time `gorm:"column:locked_until"` // Logins are refused until then.
+}
+
+// Kinds of security-relevant events recorded in the audit log.
+const (
+	AuditLoginLocked     = "login_locked"
+	AuditLoginUnlocked   = "login_unlocked"
+	AuditAPITokenCreated = "api_token_created"
+	AuditAPITokenRevoked = "api_token_revoked"
+	AuditEmailVerified   = "email_verified"
+	AuditPasswordReset   = "password_reset"
+)
+
+// AuditEvent is an append-only record of a security-relevant event.
+type AuditEvent struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Type      string    `gorm:"column:type;size:32;index" json:"type"`
+	ActorID   uint      `gorm:"column:actor_id" json:"actor_id,omitempty"` // User who caused the event, if any.
+	Username  string    `gorm:"column:username;size:191;index" json:"username,omitempty"`
+	IP        string    `gorm:"column:ip;size:64" json:"ip,omitempty"`
+	Detail    string    `gorm:"column:detail" json:"detail,omitempty"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+}
+
+// RateLimitBucket is the token bucket of one client for one rate limit, shared by all replicas.
+// Key is "<limit name>:user:<id>" or "<limit name>:ip:<address>".
+type RateLimitBucket struct {
+	Key        string    `gorm:"primaryKey;size:191"`
+	Tokens     float64   `gorm:"column:tokens"`
+	RefilledAt time.Time `gorm:"column:refilled_at;index"` // When Tokens was last brought up to date.
 }
 
 type Rating struct {
@@ -0,0 +1,71 @@
+// Package playlistfile reads and writes playlists in common interchange formats.
+package playlistfile
+
+import (
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/model"
+)
+
+// ErrUnsupportedFormat is returned for format names that are not supported.
+var ErrUnsupportedFormat = errors.New("unsupported playlist format")
+
+// Format describes a playlist file format.
+type Format struct {
+	Name        string
+	ContentType string
+	Extension   string
+
+	write func(w io.Writer, playlist *model.Playlist) error
+	read  func(r io.Reader) ([]Entry, error)
+}
+
+var formats = map[string]*Format{
+	"m3u":  {Name: "m3u", ContentType: "audio/x-mpegurl; charset=utf-8", Extension: "m3u8", write: writeM3U, read: readM3U},
+	"xspf": {Name: "xspf", ContentType: "application/xspf+xml; charset=utf-8", Extension: "xspf", write: writeXSPF, read: readXSPF},
+	"csv":  {Name: "csv", ContentType: "text/csv; charset=utf-8", Extension: "csv", write: writeCSV, read: readCSV},
+	"json": {Name: "json", ContentType: "application/json", Extension: "json", write: writeJSON, read: readJSON},
+}
+
+// LookupFormat returns the format with the given name, such as "m3u" or "xspf".
+func LookupFormat(name string) (*Format, error) {
+	format, ok := formats[strings.ToLower(name)]
+	if !ok {
+		return nil, ErrUnsupportedFormat
+	}
+	return format, nil
+}
+
+// Write encodes the playlist's songs, in order, to w.
+func (f *Format) Write(w io.Writer, playlist *model.Playlist) error {
+	return f.write(w, playlist)
+}
+
+// Filename returns a file name for the playlist in this format.
+func (f *Format) Filename(playlist *model.Playlist) string {
+	name := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|`, r) || r < ' ' {
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(playlist.Name))
+	if name == "" {
+		name = "playlist"
+	}
+	return name + "." + f.Extension
+}
+
+// songLocation returns the most playable location of a song: its preview, its Spotify page, or its Spotify URI.
+func songLocation(song model.Song) string {
+	switch {
+	case song.PreviewURL != "":
+		return song.PreviewURL
+	case song.ExternalURL != "":
+		return song.ExternalURL
+	case song.SpotifyID != "":
+		return "spotify:track:" + song.SpotifyID
+	}
+	return ""
+}
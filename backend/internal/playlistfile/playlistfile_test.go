@@ -0,0 +1,119 @@
+package playlistfile
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+var testPlaylist = &model.Playlist{
+	Name: "Road Trip",
+	Songs: []model.Song{
+		{Name: "Song, One", Artist: "Artist A", AlbumName: "Album", SpotifyID: "1", PreviewURL: "https://p.scdn.co/1", ExternalURL: "https://open.spotify.com/track/1"},
+		{Name: "Song Two", Artist: "Artist B", SpotifyID: "2", ExternalURL: "https://open.spotify.com/track/2"},
+	},
+}
+
+func TestLookupFormat(t *testing.T) {
+	format, err := LookupFormat("XSPF")
+	assert.NoError(t, err)
+	assert.Equal(t, "xspf", format.Name)
+	assert.Equal(t, "Road Trip.xspf", format.Filename(testPlaylist))
+
+	_, err = LookupFormat("wpl")
+	assert.Equal(t, ErrUnsupportedFormat, err)
+}
+
+func TestWriteM3U(t *testing.T) {
+	var buf bytes.Buffer
+	format, _ := LookupFormat("m3u")
+	assert.NoError(t, format.Write(&buf, testPlaylist))
+	assert.Equal(t, "#EXTM3U\n"+
+		"#PLAYLIST:Road Trip\n"+
+		"#EXTINF:-1,Artist A - Song, One\n"+
+		"#EXTALB:Album\n"+
+		"https://p.scdn.co/1\n"+
+		"#EXTINF:-1,Artist B - Song Two\n"+
+		"https://open.spotify.com/track/2\n", buf.String())
+}
+
+func TestWriteXSPF(t *testing.T) {
+	var buf bytes.Buffer
+	format, _ := LookupFormat("xspf")
+	assert.NoError(t, format.Write(&buf, testPlaylist))
+
+	var doc xspfPlaylist
+	assert.NoError(t, xml.Unmarshal(buf.Bytes(), &doc))
+	assert.Equal(t, "http://xspf.org/ns/0/", doc.XMLName.Space)
+	assert.Equal(t, "1", doc.Version)
+	assert.Len(t, doc.TrackList, 2)
+	assert.Equal(t, []string{"https://p.scdn.co/1"}, doc.TrackList[0].Location)
+	assert.Equal(t, []string{"spotify:track:2"}, doc.TrackList[1].Identifier)
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	format, _ := LookupFormat("csv")
+	assert.NoError(t, format.Write(&buf, testPlaylist))
+	assert.Equal(t, "position,name,artist,album,spotify_id,preview_url,external_url\n"+
+		"1,\"Song, One\",Artist A,Album,1,https://p.scdn.co/1,https://open.spotify.com/track/1\n"+
+		"2,Song Two,Artist B,,2,,https://open.spotify.com/track/2\n", buf.String())
+}
+
+func TestReadRoundTrip(t *testing.T) {
+	for _, name := range []string{"m3u", "xspf", "csv", "json"} {
+		format, _ := LookupFormat(name)
+		var buf bytes.Buffer
+		assert.NoError(t, format.Write(&buf, testPlaylist))
+
+		entries, err := format.Read(&buf)
+		assert.NoError(t, err, name)
+		assert.Len(t, entries, 2, name)
+		assert.Equal(t, "Song, One", entries[0].Title, name)
+		assert.Equal(t, "Artist B", entries[1].Artist, name)
+	}
+}
+
+func TestReadM3U(t *testing.T) {
+	content := utf8BOM + "/music/Daft Punk - One More Time.mp3\n" +
+		"# a comment\n" +
+		"#EXTINF:320,Queen - Bohemian Rhapsody\n" +
+		"https://open.spotify.com/track/4u7EnebtmKWzUH433cf5Qv?si=x\n"
+	format, _ := LookupFormat("m3u")
+
+	entries, err := format.Read(bytes.NewBufferString(content))
+	assert.NoError(t, err)
+	assert.Equal(t, []Entry{
+		{Line: 1, Raw: "/music/Daft Punk - One More Time.mp3", Title: "One More Time", Artist: "Daft Punk", Location: "/music/Daft Punk - One More Time.mp3"},
+		{Line: 4, Raw: "#EXTINF:320,Queen - Bohemian Rhapsody https://open.spotify.com/track/4u7EnebtmKWzUH433cf5Qv?si=x", Title: "Bohemian Rhapsody", Artist: "Queen", SpotifyID: "4u7EnebtmKWzUH433cf5Qv", Location: "https://open.spotify.com/track/4u7EnebtmKWzUH433cf5Qv?si=x"},
+	}, entries)
+}
+
+func TestReadCSV(t *testing.T) {
+	format, _ := LookupFormat("csv")
+
+	entries, err := format.Read(bytes.NewBufferString("Track Name,Artist Name(s),Track URI\nOne More Time,Daft Punk,spotify:track:0DiWol3AO6WpXZgp0goxAV\n,,\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, []Entry{
+		{Line: 2, Raw: "One More Time,Daft Punk,spotify:track:0DiWol3AO6WpXZgp0goxAV", Title: "One More Time", Artist: "Daft Punk", SpotifyID: "0DiWol3AO6WpXZgp0goxAV"},
+	}, entries)
+
+	_, err = format.Read(bytes.NewBufferString("foo,bar\n1,2\n"))
+	assert.ErrorIs(t, err, ErrInvalidFile)
+}
+
+func TestDetectFormat(t *testing.T) {
+	format, err := DetectFormat("mix.M3U8", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "m3u", format.Name)
+
+	format, err = DetectFormat("upload", []byte("<?xml version=\"1.0\"?><playlist/>"))
+	assert.NoError(t, err)
+	assert.Equal(t, "xspf", format.Name)
+
+	_, err = DetectFormat("upload", []byte("hello"))
+	assert.Equal(t, ErrUnsupportedFormat, err)
+}
@@ -0,0 +1,303 @@
+package playlistfile
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// ErrInvalidFile is returned when a playlist file cannot be parsed.
+var ErrInvalidFile = errors.New("invalid playlist file")
+
+// utf8BOM is the byte order mark some editors put at the start of UTF-8 files.
+const utf8BOM = "\uFEFF"
+
+// Entry is a track read from a playlist file. Any of the descriptive fields may be empty.
+type Entry struct {
+	Line      int    `json:"line"` // Line of the entry in the file, or its track number for XSPF and JSON.
+	Raw       string `json:"raw"`  // The entry as written in the file, for reporting.
+	Title     string `json:"title,omitempty"`
+	Artist    string `json:"artist,omitempty"`
+	Album     string `json:"album,omitempty"`
+	SpotifyID string `json:"spotify_id,omitempty"`
+	Location  string `json:"location,omitempty"`
+}
+
+// Read parses the entries of a playlist file in this format.
+func (f *Format) Read(r io.Reader) ([]Entry, error) {
+	return f.read(r)
+}
+
+// DetectFormat guesses the format of a playlist file from its name, falling back to its first bytes.
+func DetectFormat(filename string, head []byte) (*Format, error) {
+	switch strings.ToLower(path.Ext(filename)) {
+	case ".m3u", ".m3u8":
+		return formats["m3u"], nil
+	case ".xspf":
+		return formats["xspf"], nil
+	case ".csv":
+		return formats["csv"], nil
+	case ".json":
+		return formats["json"], nil
+	}
+
+	trimmed := strings.TrimSpace(strings.TrimPrefix(string(head), utf8BOM))
+	switch {
+	case strings.HasPrefix(trimmed, "#EXTM3U"):
+		return formats["m3u"], nil
+	case strings.HasPrefix(trimmed, "<"):
+		return formats["xspf"], nil
+	case strings.HasPrefix(trimmed, "{"):
+		return formats["json"], nil
+	}
+	return nil, ErrUnsupportedFormat
+}
+
+// readM3U parses plain and extended M3U playlists. Titles and artists come from #EXTINF
+// ("Artist - Title") or, failing that, from the file name of the location.
+func readM3U(r io.Reader) ([]Entry, error) {
+	var entries []Entry
+	var pending Entry
+
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if line == 1 {
+			text = strings.TrimPrefix(text, utf8BOM)
+		}
+
+		switch {
+		case text == "":
+			continue
+		case strings.HasPrefix(text, "#EXTINF:"):
+			pending = Entry{Raw: text}
+			if comma := strings.Index(text, ","); comma != -1 {
+				pending.Artist, pending.Title = splitArtistTitle(text[comma+1:])
+			}
+		case strings.HasPrefix(text, "#EXTALB:"):
+			pending.Album = strings.TrimSpace(strings.TrimPrefix(text, "#EXTALB:"))
+		case strings.HasPrefix(text, "#"):
+			continue
+		default:
+			entry := pending
+			entry.Line = line
+			entry.Location = text
+			if entry.Raw == "" {
+				entry.Raw = text
+			} else {
+				entry.Raw += " " + text
+			}
+			if entry.Title == "" {
+				entry.Artist, entry.Title = splitArtistTitle(locationName(text))
+			}
+			entry.SpotifyID = spotifyTrackID(text)
+			entries = append(entries, entry)
+			pending = Entry{}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+	}
+	return entries, nil
+}
+
+// readXSPF parses an XSPF playlist.
+func readXSPF(r io.Reader) ([]Entry, error) {
+	var doc xspfPlaylist
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+	}
+
+	entries := make([]Entry, len(doc.TrackList))
+	for i, track := range doc.TrackList {
+		entry := Entry{
+			Line:   i + 1,
+			Title:  strings.TrimSpace(track.Title),
+			Artist: strings.TrimSpace(track.Creator),
+			Album:  strings.TrimSpace(track.Album),
+		}
+		if len(track.Location) > 0 {
+			entry.Location = strings.TrimSpace(track.Location[0])
+		}
+		for _, id := range append(track.Identifier, track.Location...) {
+			if entry.SpotifyID = spotifyTrackID(strings.TrimSpace(id)); entry.SpotifyID != "" {
+				break
+			}
+		}
+		if entry.Title == "" && entry.Location != "" {
+			entry.Artist, entry.Title = splitArtistTitle(locationName(entry.Location))
+		}
+		entry.Raw = describe(entry)
+		entries[i] = entry
+	}
+	return entries, nil
+}
+
+// csvColumns maps accepted CSV header names to entry fields.
+var csvColumns = map[string]string{
+	"name": "title", "title": "title", "track": "title", "track name": "title", "song": "title",
+	"artist": "artist", "artist name": "artist", "artist name(s)": "artist", "creator": "artist",
+	"album": "album", "album name": "album",
+	"spotify_id": "spotify", "spotify id": "spotify", "spotify uri": "spotify", "track uri": "spotify", "uri": "spotify",
+	"location": "location", "url": "location", "preview_url": "location", "external_url": "location",
+}
+
+// readCSV parses a CSV playlist with a header row naming at least a title or Spotify column.
+func readCSV(r io.Reader) ([]Entry, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, utf8BOM)))
+		if field, ok := csvColumns[name]; ok {
+			if _, seen := columns[field]; !seen {
+				columns[field] = i
+			}
+		}
+	}
+	_, hasTitle := columns["title"]
+	_, hasSpotify := columns["spotify"]
+	if !hasTitle && !hasSpotify {
+		return nil, fmt.Errorf("%w: CSV header needs a name or spotify_id column", ErrInvalidFile)
+	}
+
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var entries []Entry
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+		}
+		line, _ := cr.FieldPos(0)
+
+		entry := Entry{
+			Line:     line,
+			Raw:      strings.Join(record, ","),
+			Title:    field(record, "title"),
+			Artist:   field(record, "artist"),
+			Album:    field(record, "album"),
+			Location: field(record, "location"),
+		}
+		spotify := field(record, "spotify")
+		if entry.SpotifyID = spotifyTrackID(spotify); entry.SpotifyID == "" && isSpotifyID(spotify) {
+			entry.SpotifyID = spotify
+		}
+		if entry.SpotifyID == "" {
+			entry.SpotifyID = spotifyTrackID(entry.Location)
+		}
+		if entry.Title == "" && entry.SpotifyID == "" {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// readJSON parses the JSON documents produced by writeJSON.
+func readJSON(r io.Reader) ([]Entry, error) {
+	var doc jsonPlaylist
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+	}
+
+	entries := make([]Entry, len(doc.Songs))
+	for i, song := range doc.Songs {
+		entry := Entry{
+			Line:      i + 1,
+			Title:     song.Name,
+			Artist:    song.Artist,
+			Album:     song.AlbumName,
+			SpotifyID: song.SpotifyID,
+			Location:  song.ExternalURL,
+		}
+		entry.Raw = describe(entry)
+		entries[i] = entry
+	}
+	return entries, nil
+}
+
+// describe renders an entry that has no textual line of its own.
+func describe(entry Entry) string {
+	switch {
+	case entry.Artist != "" && entry.Title != "":
+		return entry.Artist + " - " + entry.Title
+	case entry.Title != "":
+		return entry.Title
+	}
+	return entry.Location
+}
+
+// splitArtistTitle splits "Artist - Title". Without a separator the whole text is the title.
+func splitArtistTitle(s string) (artist, title string) {
+	s = strings.TrimSpace(s)
+	if i := strings.Index(s, " - "); i != -1 {
+		return strings.TrimSpace(s[:i]), strings.TrimSpace(s[i+3:])
+	}
+	return "", s
+}
+
+// locationName returns the file name of a path or URL without its extension.
+func locationName(location string) string {
+	if u, err := url.Parse(location); err == nil && u.Path != "" {
+		location = u.Path
+	}
+	location = strings.ReplaceAll(location, `\`, "/")
+	base := path.Base(location)
+	return strings.TrimSuffix(base, path.Ext(base))
+}
+
+// spotifyTrackID extracts the track ID from a Spotify track URI or open.spotify.com URL.
+func spotifyTrackID(s string) string {
+	if id, ok := strings.CutPrefix(s, "spotify:track:"); ok && isSpotifyID(id) {
+		return id
+	}
+	u, err := url.Parse(s)
+	if err != nil || u.Host != "open.spotify.com" {
+		return ""
+	}
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(segments) > 0 && strings.HasPrefix(segments[0], "intl-") {
+		segments = segments[1:]
+	}
+	if len(segments) == 2 && segments[0] == "track" && isSpotifyID(segments[1]) {
+		return segments[1]
+	}
+	return ""
+}
+
+// isSpotifyID reports whether s looks like a base-62 Spotify ID.
+func isSpotifyID(s string) bool {
+	if len(s) != 22 {
+		return false
+	}
+	for _, r := range s {
+		if !(r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z') {
+			return false
+		}
+	}
+	return true
+}
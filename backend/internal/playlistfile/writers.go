@@ -0,0 +1,146 @@
+package playlistfile
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/model"
+)
+
+// writeM3U writes an extended M3U playlist. Track durations are unknown and written as -1.
+func writeM3U(w io.Writer, playlist *model.Playlist) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "#EXTM3U")
+	fmt.Fprintf(bw, "#PLAYLIST:%s\n", singleLine(playlist.Name))
+	for _, song := range playlist.Songs {
+		fmt.Fprintf(bw, "#EXTINF:-1,%s - %s\n", singleLine(song.Artist), singleLine(song.Name))
+		if song.AlbumName != "" {
+			fmt.Fprintf(bw, "#EXTALB:%s\n", singleLine(song.AlbumName))
+		}
+		if song.AlbumImageURL != "" {
+			fmt.Fprintf(bw, "#EXTIMG:%s\n", singleLine(song.AlbumImageURL))
+		}
+		fmt.Fprintln(bw, singleLine(songLocation(song)))
+	}
+	return bw.Flush()
+}
+
+// singleLine strips line breaks, which would corrupt line-based formats.
+func singleLine(s string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(s)
+}
+
+// xspfPlaylist is the root element of an XSPF document (https://xspf.org/spec).
+type xspfPlaylist struct {
+	XMLName   xml.Name    `xml:"http://xspf.org/ns/0/ playlist"`
+	Version   string      `xml:"version,attr"`
+	Title     string      `xml:"title,omitempty"`
+	TrackList []xspfTrack `xml:"trackList>track"`
+}
+
+type xspfTrack struct {
+	Location   []string `xml:"location,omitempty"`
+	Identifier []string `xml:"identifier,omitempty"`
+	Title      string   `xml:"title,omitempty"`
+	Creator    string   `xml:"creator,omitempty"`
+	Album      string   `xml:"album,omitempty"`
+	TrackNum   int      `xml:"trackNum,omitempty"`
+	Image      string   `xml:"image,omitempty"`
+	Info       string   `xml:"info,omitempty"`
+}
+
+// writeXSPF writes an XSPF version 1 playlist.
+func writeXSPF(w io.Writer, playlist *model.Playlist) error {
+	doc := xspfPlaylist{Version: "1", Title: playlist.Name, TrackList: make([]xspfTrack, len(playlist.Songs))}
+	for i, song := range playlist.Songs {
+		track := xspfTrack{
+			Title:    song.Name,
+			Creator:  song.Artist,
+			Album:    song.AlbumName,
+			TrackNum: i + 1,
+			Image:    song.AlbumImageURL,
+			Info:     song.ExternalURL,
+		}
+		if location := songLocation(song); location != "" {
+			track.Location = []string{location}
+		}
+		if song.SpotifyID != "" {
+			track.Identifier = []string{"spotify:track:" + song.SpotifyID}
+		}
+		doc.TrackList[i] = track
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// csvHeader lists the columns written to and read from CSV playlists.
+var csvHeader = []string{"position", "name", "artist", "album", "spotify_id", "preview_url", "external_url"}
+
+// writeCSV writes one RFC 4180 row per song, preceded by a header row.
+func writeCSV(w io.Writer, playlist *model.Playlist) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	for i, song := range playlist.Songs {
+		row := []string{strconv.Itoa(i + 1), song.Name, song.Artist, song.AlbumName, song.SpotifyID, song.PreviewURL, song.ExternalURL}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// jsonPlaylist is the document written by the JSON export.
+type jsonPlaylist struct {
+	Name  string      `json:"name"`
+	Songs []jsonTrack `json:"songs"`
+}
+
+type jsonTrack struct {
+	Position      int    `json:"position"`
+	Name          string `json:"name"`
+	Artist        string `json:"artist"`
+	AlbumName     string `json:"album_name"`
+	AlbumImageURL string `json:"album_image_url,omitempty"`
+	SpotifyID     string `json:"spotify_id,omitempty"`
+	PreviewURL    string `json:"preview_url,omitempty"`
+	ExternalURL   string `json:"external_url,omitempty"`
+}
+
+// writeJSON writes a self-describing JSON document with the playlist's songs.
+func writeJSON(w io.Writer, playlist *model.Playlist) error {
+	doc := jsonPlaylist{Name: playlist.Name, Songs: make([]jsonTrack, len(playlist.Songs))}
+	for i, song := range playlist.Songs {
+		doc.Songs[i] = jsonTrack{
+			Position:      i + 1,
+			Name:          song.Name,
+			Artist:        song.Artist,
+			AlbumName:     song.AlbumName,
+			AlbumImageURL: song.AlbumImageURL,
+			SpotifyID:     song.SpotifyID,
+			PreviewURL:    song.PreviewURL,
+			ExternalURL:   song.ExternalURL,
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
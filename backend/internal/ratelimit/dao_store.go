@@ -0,0 +1,65 @@
+package ratelimit
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/dao"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/model"
+)
+
+// minStaleBucketAge is the least time a bucket may go unused before DAOStore deletes it.
+const minStaleBucketAge = 24 * time.Hour
+
+// DAOStore keeps buckets in the database, so that the limits hold across all replicas.
+type DAOStore struct {
+	musicDAO dao.MusicDAO
+
+	mu       sync.Mutex
+	prunedAt time.Time
+	// staleAge is how long a bucket may go unused before it is deleted. It is at least the longest
+	// Limit.Per, since a bucket is only certain to be full after that long.
+	staleAge time.Duration
+}
+
+// NewDAOStore returns a store for the given limits. Buckets are kept long enough for the longest of
+// them, and of any other limit passed to Take.
+func NewDAOStore(musicDAO dao.MusicDAO, limits map[string]Limit) *DAOStore {
+	s := &DAOStore{musicDAO: musicDAO, staleAge: minStaleBucketAge}
+	for _, limit := range limits {
+		s.staleAge = max(s.staleAge, limit.Per)
+	}
+	return s
+}
+
+// Take counts a request against the bucket with the given key.
+func (s *DAOStore) Take(key string, limit Limit, now time.Time) (Result, error) {
+	s.mu.Lock()
+	s.staleAge = max(s.staleAge, limit.Per)
+	s.mu.Unlock()
+	s.prune(now)
+
+	var result Result
+	initial := &model.RateLimitBucket{Key: key, Tokens: float64(limit.Requests), RefilledAt: now}
+	err := s.musicDAO.UpdateRateLimitBucket(initial, func(bucket *model.RateLimitBucket) {
+		bucket.Tokens, bucket.RefilledAt, result = limit.take(bucket.Tokens, bucket.RefilledAt, now)
+	})
+	return result, err
+}
+
+// prune deletes stale buckets at most once per pruneInterval on each replica.
+func (s *DAOStore) prune(now time.Time) {
+	s.mu.Lock()
+	if now.Sub(s.prunedAt) < pruneInterval {
+		s.mu.Unlock()
+		return
+	}
+	s.prunedAt = now
+	staleAge := s.staleAge
+	s.mu.Unlock()
+
+	if _, err := s.musicDAO.DeleteRateLimitBuckets(now.Add(-staleAge)); err != nil {
+		log.Printf("Failed to delete stale rate limit buckets: %v", err)
+	}
+}
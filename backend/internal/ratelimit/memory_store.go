@@ -0,0 +1,56 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// pruneInterval is how often stores forget buckets that have filled up again.
+const pruneInterval = time.Minute
+
+// MemoryStore keeps buckets in memory. Each replica using one enforces the limits on its own.
+type MemoryStore struct {
+	mu       sync.Mutex
+	buckets  map[string]*memoryBucket
+	prunedAt time.Time
+}
+
+type memoryBucket struct {
+	tokens     float64
+	refilledAt time.Time
+	fullAt     time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]*memoryBucket)}
+}
+
+// Take counts a request against the bucket with the given key.
+func (s *MemoryStore) Take(key string, limit Limit, now time.Time) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.prune(now)
+	bucket, ok := s.buckets[key]
+	if !ok {
+		bucket = &memoryBucket{tokens: float64(limit.Requests), refilledAt: now}
+		s.buckets[key] = bucket
+	}
+	var result Result
+	bucket.tokens, bucket.refilledAt, result = limit.take(bucket.tokens, bucket.refilledAt, now)
+	bucket.fullAt = now.Add(result.Reset)
+	return result, nil
+}
+
+// prune forgets full buckets, which behave the same as missing ones.
+func (s *MemoryStore) prune(now time.Time) {
+	if now.Sub(s.prunedAt) < pruneInterval {
+		return
+	}
+	s.prunedAt = now
+	for key, bucket := range s.buckets {
+		if !bucket.fullAt.After(now) {
+			delete(s.buckets, key)
+		}
+	}
+}
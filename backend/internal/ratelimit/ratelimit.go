@@ -0,0 +1,86 @@
+// Package ratelimit limits how often clients may make requests, using token buckets.
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultLimit names the limit of routes that do not have one of their own.
+const DefaultLimit = "default"
+
+// Limit allows Requests requests at once, refilled evenly over Per. The zero Limit allows everything.
+type Limit struct {
+	Requests int
+	Per      time.Duration
+}
+
+// Unlimited reports whether l allows every request.
+func (l Limit) Unlimited() bool {
+	return l.Requests <= 0 || l.Per <= 0
+}
+
+// ParseLimit parses a limit such as "30/m", "1000/h" or "5/10s". The period is a Go duration,
+// and a bare unit means one of it.
+func ParseLimit(s string) (Limit, error) {
+	requests, period, ok := strings.Cut(strings.TrimSpace(s), "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("invalid rate limit %q: want <requests>/<period>", s)
+	}
+	n, err := strconv.Atoi(requests)
+	if err != nil || n <= 0 {
+		return Limit{}, fmt.Errorf("invalid rate limit %q: requests must be a positive integer", s)
+	}
+	if period != "" && strings.Trim(period, "0123456789.") == period {
+		period = "1" + period
+	}
+	per, err := time.ParseDuration(period)
+	if err != nil || per <= 0 {
+		return Limit{}, fmt.Errorf("invalid rate limit %q: period must be a positive duration", s)
+	}
+	return Limit{Requests: n, Per: per}, nil
+}
+
+// Result describes the state of a client's bucket after a request was counted.
+type Result struct {
+	Allowed    bool
+	Limit      int           // Requests allowed at once.
+	Remaining  int           // Requests allowed right now.
+	RetryAfter time.Duration // When the request was refused, how long until the next one is allowed.
+	Reset      time.Duration // How long until the bucket is full again.
+}
+
+// Store keeps the buckets of all clients. Implementations must count concurrent requests correctly.
+type Store interface {
+	// Take counts a request against the bucket with the given key at time now.
+	Take(key string, limit Limit, now time.Time) (Result, error)
+}
+
+// take refills a bucket that held tokens at refilledAt up to now and takes a token from it if it
+// has one. It returns the new contents of the bucket.
+func (l Limit) take(tokens float64, refilledAt, now time.Time) (float64, time.Time, Result) {
+	rate := float64(l.Requests) / l.Per.Seconds()
+	// Clocks of different replicas may disagree slightly; a bucket is never refilled backwards.
+	if now.After(refilledAt) {
+		tokens = math.Min(float64(l.Requests), tokens+now.Sub(refilledAt).Seconds()*rate)
+		refilledAt = now
+	}
+
+	result := Result{Limit: l.Requests}
+	if tokens >= 1 {
+		tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = seconds((1 - tokens) / rate)
+	}
+	result.Remaining = int(tokens)
+	result.Reset = seconds((float64(l.Requests) - tokens) / rate)
+	return tokens, refilledAt, result
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
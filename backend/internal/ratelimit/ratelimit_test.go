@@ -0,0 +1,121 @@
+package ratelimit
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/dao/mocks"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestParseLimit(t *testing.T) {
+	tests := map[string]Limit{
+		"30/m":   {Requests: 30, Per: time.Minute},
+		"1000/h": {Requests: 1000, Per: time.Hour},
+		"5/10s":  {Requests: 5, Per: 10 * time.Second},
+	}
+	for s, want := range tests {
+		limit, err := ParseLimit(s)
+		assert.NoError(t, err, s)
+		assert.Equal(t, want, limit, s)
+	}
+
+	for _, s := range []string{"", "30", "0/m", "-1/m", "x/m", "30/", "30/0s", "30/fortnight"} {
+		_, err := ParseLimit(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestMemoryStoreTake(t *testing.T) {
+	store := NewMemoryStore()
+	limit := Limit{Requests: 3, Per: 3 * time.Second}
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	for i := 2; i >= 0; i-- {
+		result, err := store.Take("k", limit, now)
+		assert.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 3, result.Limit)
+		assert.Equal(t, i, result.Remaining)
+	}
+
+	result, _ := store.Take("k", limit, now)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, time.Second, result.RetryAfter)
+	assert.Equal(t, 3*time.Second, result.Reset)
+
+	// Other keys have their own bucket.
+	result, _ = store.Take("other", limit, now)
+	assert.True(t, result.Allowed)
+
+	// One token is back after a second, and the bucket never holds more than Requests.
+	result, _ = store.Take("k", limit, now.Add(time.Second))
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+	result, _ = store.Take("k", limit, now.Add(time.Hour))
+	assert.Equal(t, 2, result.Remaining)
+}
+
+func TestMemoryStorePrunesFullBuckets(t *testing.T) {
+	store := NewMemoryStore()
+	limit := Limit{Requests: 1, Per: time.Second}
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	store.Take("a", limit, now)
+	store.Take("b", limit, now.Add(pruneInterval))
+	assert.Len(t, store.buckets, 1)
+	assert.Contains(t, store.buckets, "b")
+}
+
+func TestDAOStoreTake(t *testing.T) {
+	mockDAO := new(mocks.MusicDAO)
+	limit := Limit{Requests: 10, Per: 10 * time.Second}
+	store := NewDAOStore(mockDAO, map[string]Limit{"login": limit})
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	mockDAO.On("DeleteRateLimitBuckets", now.Add(-minStaleBucketAge)).Return(int64(0), nil).Once()
+	mockDAO.On("UpdateRateLimitBucket", mock.MatchedBy(func(initial *model.RateLimitBucket) bool {
+		return initial.Key == "login:ip:10.0.0.1" && initial.Tokens == 10 && initial.RefilledAt.Equal(now)
+	}), mock.Anything).Run(func(args mock.Arguments) {
+		// The stored bucket was emptied two seconds ago, so two tokens are back.
+		bucket := &model.RateLimitBucket{Key: "login:ip:10.0.0.1", Tokens: 0, RefilledAt: now.Add(-2 * time.Second)}
+		args.Get(1).(func(*model.RateLimitBucket))(bucket)
+		assert.Equal(t, 1.0, bucket.Tokens)
+		assert.Equal(t, now, bucket.RefilledAt)
+	}).Return(nil).Once()
+
+	result, err := store.Take("login:ip:10.0.0.1", limit, now)
+	assert.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 1, result.Remaining)
+	mockDAO.AssertExpectations(t)
+
+	// Stale buckets are only deleted once per interval, and store errors are returned.
+	mockDAO.On("UpdateRateLimitBucket", mock.Anything, mock.Anything).Return(errors.New("db down")).Once()
+	_, err = store.Take("login:ip:10.0.0.1", limit, now.Add(time.Second))
+	assert.Error(t, err)
+	mockDAO.AssertNumberOfCalls(t, "DeleteRateLimitBuckets", 1)
+}
+
+func TestDAOStoreKeepsBucketsForTheLongestLimit(t *testing.T) {
+	mockDAO := new(mocks.MusicDAO)
+	weekly := Limit{Requests: 10, Per: 7 * 24 * time.Hour}
+	store := NewDAOStore(mockDAO, map[string]Limit{"login": {Requests: 10, Per: time.Minute}, "weekly": weekly})
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	mockDAO.On("DeleteRateLimitBuckets", now.Add(-weekly.Per)).Return(int64(0), nil).Once()
+	mockDAO.On("UpdateRateLimitBucket", mock.Anything, mock.Anything).Return(nil)
+	_, err := store.Take("login:ip:10.0.0.1", Limit{Requests: 10, Per: time.Minute}, now)
+	assert.NoError(t, err)
+
+	// Limits that were not configured still keep their buckets long enough.
+	monthly := Limit{Requests: 10, Per: 30 * 24 * time.Hour}
+	later := now.Add(pruneInterval)
+	mockDAO.On("DeleteRateLimitBuckets", later.Add(-monthly.Per)).Return(int64(0), nil).Once()
+	_, err = store.Take("monthly:ip:10.0.0.1", monthly, later)
+	assert.NoError(t, err)
+	mockDAO.AssertExpectations(t)
+}
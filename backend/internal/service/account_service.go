@@ -0,0 +1,223 @@
+package service
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/auth"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/dao"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/mail"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/model"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	emailVerificationTTL = 48 * time.Hour
+	passwordResetTTL     = time.Hour
+)
+
+var (
+	// ErrInvalidEmailToken is returned when a verification or password reset token is invalid,
+	// expired or already used.
+	ErrInvalidEmailToken = errors.New("the link is invalid, expired or was already used")
+
+	// ErrEmailAlreadyVerified is returned when the user has already verified their email.
+	ErrEmailAlreadyVerified = errors.New("email already verified")
+)
+
+// AccountConfig describes the emails sent by AccountService.
+type AccountConfig struct {
+	Tokens auth.TokenConfig // Signs the tokens in the links.
+	AppURL string           // Base URL of the web app, which has the pages the links open.
+}
+
+// AccountService verifies users' emails and resets forgotten passwords with links sent by email.
+// The links carry signed tokens that expire and stop working once used.
+type AccountService interface {
+	RequestEmailVerification(userID uint) error
+	VerifyEmail(token string) error
+	// RequestPasswordReset emails a reset link if a user has the email, and does nothing otherwise,
+	// so that callers cannot tell which emails are registered.
+	RequestPasswordReset(email string) error
+	ResetPassword(token, password string) error
+}
+
+type accountService struct {
+	musicDAO dao.MusicDAO
+	sender   mail.Sender
+	config   AccountConfig
+	now      func() time.Time
+}
+
+func NewAccountService(musicDAO dao.MusicDAO, sender mail.Sender, config AccountConfig) AccountService {
+	return &accountService{musicDAO: musicDAO, sender: sender, config: config, now: time.Now}
+}
+
+// RequestEmailVerification emails the user a link to verify their email.
+func (s *accountService) RequestEmailVerification(userID uint) error {
+	user, err := s.musicDAO.GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+	if user.EmailVerifiedAt != nil {
+		return ErrEmailAlreadyVerified
+	}
+
+	// The token is bound to the email, so that it cannot verify an email the user has since changed to.
+	link, err := s.link("/verify-email", auth.PurposeVerifyEmail, user.ID, user.Email, emailVerificationTTL)
+	if err != nil {
+		return err
+	}
+	return s.sender.Send(mail.Message{
+		To:      user.Email,
+		Subject: "Verify your email",
+		Body: fmt.Sprintf("Hi %s,\n\nOpen this link within %s to verify your email:\n\n%s\n\n"+
+			"If you did not create an account, you can ignore this email.\n", user.Username, emailVerificationTTL, link),
+	})
+}
+
+// VerifyEmail marks the email of the token's user as verified.
+func (s *accountService) VerifyEmail(token string) error {
+	claims, err := s.config.Tokens.ParseAction(auth.PurposeVerifyEmail, token)
+	if err != nil {
+		log.Printf("Rejected email verification token: %v", err)
+		return ErrInvalidEmailToken
+	}
+	user, err := s.actionUser(claims, func(user *model.User) string { return user.Email })
+	if err != nil {
+		return err
+	}
+	if user.EmailVerifiedAt != nil {
+		return ErrEmailAlreadyVerified
+	}
+
+	if err := s.musicDAO.VerifyUserEmail(user.ID, user.Email, s.now()); err != nil {
+		if errors.Is(err, dao.ErrUserNotFound) {
+			return ErrInvalidEmailToken
+		}
+		return err
+	}
+	s.audit(model.AuditEvent{Type: model.AuditEmailVerified, ActorID: user.ID, Username: user.Username})
+	return nil
+}
+
+// RequestPasswordReset emails a password reset link to the user with the given email.
+func (s *accountService) RequestPasswordReset(email string) error {
+	user, err := s.musicDAO.GetUserByEmail(strings.TrimSpace(email))
+	if errors.Is(err, dao.ErrUserNotFound) {
+		// The address is not logged: it may be a typo of someone's real address, or not an address at all.
+		log.Print("Password reset requested for an unknown email")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// The token is bound to the current password hash, so that it stops working once the password changes.
+	link, err := s.link("/reset-password", auth.PurposeResetPassword, user.ID, user.Password, passwordResetTTL)
+	if err != nil {
+		return err
+	}
+	return s.sender.Send(mail.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nOpen this link within %s to choose a new password:\n\n%s\n\n"+
+			"If you did not ask to reset your password, you can ignore this email.\n", user.Username, passwordResetTTL, link),
+	})
+}
+
+// ResetPassword sets the password of the token's user. Whoever knew the old password may have
+// signed in or created API tokens with it, so the user's access tokens stop being accepted and
+// their API tokens are revoked. Failed logins for the user are forgotten, and the email counts as
+// verified since the user received the link.
+func (s *accountService) ResetPassword(token, password string) error {
+	claims, err := s.config.Tokens.ParseAction(auth.PurposeResetPassword, token)
+	if err != nil {
+		log.Printf("Rejected password reset token: %v", err)
+		return ErrInvalidEmailToken
+	}
+	user, err := s.actionUser(claims, func(user *model.User) string { return user.Password })
+	if err != nil {
+		return err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	revoked, err := s.musicDAO.ResetUserPassword(user.ID, user.Password, string(hash), s.now())
+	if err != nil {
+		if errors.Is(err, dao.ErrUserNotFound) {
+			return ErrInvalidEmailToken
+		}
+		return err
+	}
+	s.audit(model.AuditEvent{Type: model.AuditPasswordReset, ActorID: user.ID, Username: user.Username, Detail: revokedTokensDetail(revoked)})
+
+	if err := s.musicDAO.DeleteLoginThrottle(usernameThrottleKey(user.Username)); err != nil {
+		log.Printf("Failed to reset failed logins for %s: %v", user.Username, err)
+	}
+	if user.EmailVerifiedAt == nil {
+		if err := s.musicDAO.VerifyUserEmail(user.ID, user.Email, s.now()); err != nil && !errors.Is(err, dao.ErrUserNotFound) {
+			log.Printf("Failed to verify email of user %d: %v", user.ID, err)
+		}
+	}
+	return nil
+}
+
+// revokedTokensDetail lists the API tokens revoked by a password reset for its audit event.
+func revokedTokensDetail(tokens []model.APIToken) string {
+	if len(tokens) == 0 {
+		return "no API tokens revoked"
+	}
+	ids := make([]string, len(tokens))
+	for i, token := range tokens {
+		ids[i] = strconv.FormatUint(uint64(token.ID), 10)
+	}
+	return "revoked API tokens " + strings.Join(ids, ", ")
+}
+
+// actionUser returns the user a token was issued to, if the state the token is bound to has not changed.
+func (s *accountService) actionUser(claims *auth.ActionClaims, state func(*model.User) string) (*model.User, error) {
+	user, err := s.musicDAO.GetUserByID(claims.UserID())
+	if errors.Is(err, dao.ErrUserNotFound) {
+		return nil, ErrInvalidEmailToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(stateFingerprint(state(user))), []byte(claims.State)) != 1 {
+		return nil, ErrInvalidEmailToken
+	}
+	return user, nil
+}
+
+// link returns a link to a page of the web app with a token for the given action.
+func (s *accountService) link(path, purpose string, userID uint, state string, ttl time.Duration) (string, error) {
+	token, err := s.config.Tokens.IssueAction(purpose, userID, stateFingerprint(state), ttl)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(s.config.AppURL, "/") + path + "?token=" + url.QueryEscape(token), nil
+}
+
+func (s *accountService) audit(event model.AuditEvent) {
+	if err := s.musicDAO.CreateAuditEvent(&event); err != nil {
+		log.Printf("Failed to record audit event %s: %v", event.Type, err)
+	}
+}
+
+// stateFingerprint hashes the state a token is bound to, since tokens can be read by anyone who
+// has them and must not reveal it.
+func stateFingerprint(state string) string {
+	sum := sha256.Sum256([]byte(state))
+	return base64.RawURLEncoding.EncodeToString(sum[:16])
+}
@@ -0,0 +1,168 @@
+package service
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/auth"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/dao/mocks"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/mail"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// recordingSender keeps the emails it is asked to send.
+type recordingSender struct {
+	sent []mail.Message
+}
+
+func (s *recordingSender) Send(msg mail.Message) error {
+	s.sent = append(s.sent, msg)
+	return nil
+}
+
+var linkToken = regexp.MustCompile(`\?token=(\S+)`)
+
+// tokenFromEmail returns the token in the link of the last email sent.
+func (s *recordingSender) tokenFromEmail(t *testing.T) string {
+	t.Helper()
+	if len(s.sent) == 0 {
+		t.Fatal("no email was sent")
+	}
+	match := linkToken.FindStringSubmatch(s.sent[len(s.sent)-1].Body)
+	if match == nil {
+		t.Fatal("the email has no link with a token")
+	}
+	token, err := url.QueryUnescape(match[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func newTestAccountService(t *testing.T, mockDAO *mocks.MusicDAO) (*accountService, *recordingSender) {
+	t.Helper()
+	_, private, _ := ed25519.GenerateKey(rand.Reader)
+	key, _ := auth.NewKey("test", private)
+	keys, err := auth.NewKeySet([]*auth.Key{key}, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sender := &recordingSender{}
+	tokens := auth.TokenConfig{Keys: keys, Issuer: "go-music-k8s", Audience: "go-music-k8s-api", TTL: time.Hour, ClockSkew: time.Minute}
+	s := NewAccountService(mockDAO, sender, AccountConfig{Tokens: tokens, AppURL: "https://music.example.com/"}).(*accountService)
+	return s, sender
+}
+
+func TestEmailVerification(t *testing.T) {
+	mockDAO := new(mocks.MusicDAO)
+	s, sender := newTestAccountService(t, mockDAO)
+	user := &model.User{Model: gorm.Model{ID: 7}, Username: "alice", Email: "alice@example.com"}
+
+	mockDAO.On("GetUserByID", uint(7)).Return(user, nil)
+	assert.NoError(t, s.RequestEmailVerification(7))
+	if assert.Len(t, sender.sent, 1) {
+		assert.Equal(t, "alice@example.com", sender.sent[0].To)
+		assert.Contains(t, sender.sent[0].Body, "https://music.example.com/verify-email?token=")
+	}
+	token := sender.tokenFromEmail(t)
+
+	mockDAO.On("VerifyUserEmail", uint(7), "alice@example.com", mock.AnythingOfType("time.Time")).Return(nil).Once()
+	mockDAO.On("CreateAuditEvent", mock.MatchedBy(func(event *model.AuditEvent) bool {
+		return event.Type == model.AuditEmailVerified && event.ActorID == 7
+	})).Return(nil)
+	assert.NoError(t, s.VerifyEmail(token))
+	mockDAO.AssertExpectations(t)
+
+	// The link cannot be used again once the email is verified.
+	verifiedAt := time.Now()
+	user.EmailVerifiedAt = &verifiedAt
+	assert.ErrorIs(t, s.VerifyEmail(token), ErrEmailAlreadyVerified)
+	assert.ErrorIs(t, s.RequestEmailVerification(7), ErrEmailAlreadyVerified)
+}
+
+func TestEmailVerificationRejectsChangedEmailAndOtherTokens(t *testing.T) {
+	mockDAO := new(mocks.MusicDAO)
+	s, sender := newTestAccountService(t, mockDAO)
+	user := &model.User{Model: gorm.Model{ID: 7}, Username: "alice", Email: "alice@example.com", Password: "hash"}
+
+	mockDAO.On("GetUserByID", uint(7)).Return(user, nil)
+	mockDAO.On("GetUserByEmail", "alice@example.com").Return(user, nil)
+	assert.NoError(t, s.RequestEmailVerification(7))
+	verifyToken := sender.tokenFromEmail(t)
+	assert.NoError(t, s.RequestPasswordReset("alice@example.com"))
+	resetToken := sender.tokenFromEmail(t)
+
+	// A password reset link cannot verify an email, nor the other way round.
+	assert.ErrorIs(t, s.VerifyEmail(resetToken), ErrInvalidEmailToken)
+	assert.ErrorIs(t, s.ResetPassword(verifyToken, "newpassword1"), ErrInvalidEmailToken)
+	assert.ErrorIs(t, s.VerifyEmail("not a token"), ErrInvalidEmailToken)
+
+	user.Email = "alice@other.example.com"
+	assert.ErrorIs(t, s.VerifyEmail(verifyToken), ErrInvalidEmailToken)
+	mockDAO.AssertNotCalled(t, "VerifyUserEmail", mock.Anything, mock.Anything, mock.Anything)
+	mockDAO.AssertNotCalled(t, "ResetUserPassword", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPasswordReset(t *testing.T) {
+	mockDAO := new(mocks.MusicDAO)
+	s, sender := newTestAccountService(t, mockDAO)
+	oldHash, _ := bcrypt.GenerateFromPassword([]byte("oldpassword1"), bcrypt.MinCost)
+	user := &model.User{Model: gorm.Model{ID: 7}, Username: "alice", Email: "alice@example.com", Password: string(oldHash)}
+
+	mockDAO.On("GetUserByEmail", "alice@example.com").Return(user, nil)
+	mockDAO.On("GetUserByID", uint(7)).Return(user, nil)
+	assert.NoError(t, s.RequestPasswordReset(" alice@example.com "))
+	token := sender.tokenFromEmail(t)
+
+	var newHash string
+	resetAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return resetAt }
+	revoked := []model.APIToken{{ID: 12, UserID: 7, RevokedAt: &resetAt}, {ID: 5, UserID: 7, RevokedAt: &resetAt}}
+	mockDAO.On("ResetUserPassword", uint(7), string(oldHash), mock.AnythingOfType("string"), resetAt).Run(func(args mock.Arguments) {
+		newHash = args.String(2)
+	}).Return(revoked, nil).Once()
+	mockDAO.On("CreateAuditEvent", mock.MatchedBy(func(event *model.AuditEvent) bool {
+		return event.Type == model.AuditPasswordReset && event.ActorID == 7 && event.Detail == "revoked API tokens 12, 5"
+	})).Return(nil)
+	mockDAO.On("DeleteLoginThrottle", "username:alice").Return(nil).Once()
+	mockDAO.On("VerifyUserEmail", uint(7), "alice@example.com", mock.AnythingOfType("time.Time")).Return(nil).Once()
+
+	assert.NoError(t, s.ResetPassword(token, "newpassword1"))
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(newHash), []byte("newpassword1")))
+	mockDAO.AssertExpectations(t)
+
+	// Once the password has changed, the link no longer works.
+	user.Password = newHash
+	assert.ErrorIs(t, s.ResetPassword(token, "otherpassword1"), ErrInvalidEmailToken)
+}
+
+func TestPasswordResetRaceUsesLinkOnce(t *testing.T) {
+	mockDAO := new(mocks.MusicDAO)
+	s, sender := newTestAccountService(t, mockDAO)
+	user := &model.User{Model: gorm.Model{ID: 7}, Username: "alice", Email: "alice@example.com", Password: "hash"}
+
+	mockDAO.On("GetUserByEmail", "alice@example.com").Return(user, nil)
+	mockDAO.On("GetUserByID", uint(7)).Return(user, nil)
+	assert.NoError(t, s.RequestPasswordReset("alice@example.com"))
+
+	// Another request with the same link changed the password after this one read the user.
+	mockDAO.On("ResetUserPassword", uint(7), "hash", mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).Return(nil, ErrUserNotFound)
+	assert.ErrorIs(t, s.ResetPassword(sender.tokenFromEmail(t), "newpassword1"), ErrInvalidEmailToken)
+}
+
+func TestPasswordResetForUnknownEmailSendsNothing(t *testing.T) {
+	mockDAO := new(mocks.MusicDAO)
+	s, sender := newTestAccountService(t, mockDAO)
+
+	mockDAO.On("GetUserByEmail", "nobody@example.com").Return(nil, ErrUserNotFound)
+	assert.NoError(t, s.RequestPasswordReset("nobody@example.com"))
+	assert.Empty(t, sender.sent)
+}
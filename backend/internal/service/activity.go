@@ -0,0 +1,28 @@
+package service
+
+import (
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/dao"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/model"
+)
+
+// recordActivity appends to the activity log. The log only feeds the activity feed, so failures
+// are logged rather than failing the action that caused them.
+func recordActivity(musicDAO dao.MusicDAO, activity model.Activity) {
+	activity.CreatedAt = time.Now()
+	if err := musicDAO.CreateActivity(&activity); err != nil {
+		log.Printf("Failed to record %s activity for playlist %d: %v", activity.Type, activity.PlaylistID, err)
+	}
+}
+
+// parsePlaylistID converts a playlist ID taken from a URL to its numeric form.
+func parsePlaylistID(playlistID string) (uint, error) {
+	id, err := strconv.ParseUint(playlistID, 10, 64)
+	if err != nil {
+		return 0, ErrPlaylistNotFound
+	}
+	return uint(id), nil
+}
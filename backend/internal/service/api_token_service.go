@@ -0,0 +1,214 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/dao"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/model"
+)
+
+const (
+	// apiTokenPrefix starts every API token, so that they are easy to tell from JWTs and to find in leaked text.
+	apiTokenPrefix = "gmk_"
+	// apiTokenDisplayLength is how much of a token is kept in clear to tell tokens apart.
+	apiTokenDisplayLength = len(apiTokenPrefix) + 8
+
+	defaultAPITokenLifetimeDays = 30
+	maxAPITokenLifetimeDays     = 365
+	maxAPITokensPerUser         = 50
+
+	// lastUsedResolution is how stale the recorded last use of a token may get, so that a busy
+	// script does not cause a write on every request.
+	lastUsedResolution = time.Minute
+)
+
+var (
+	// ErrAPITokenNotFound is returned when the user has no API token with the given ID.
+	ErrAPITokenNotFound = dao.ErrAPITokenNotFound
+
+	// ErrInvalidAPIToken is returned when a presented API token is unknown or revoked.
+	ErrInvalidAPIToken = errors.New("invalid API token")
+
+	// ErrAPITokenExpired is returned when a presented API token has expired.
+	ErrAPITokenExpired = errors.New("API token expired")
+
+	// ErrInvalidAPITokenScope is returned when a new token asks for an unknown scope.
+	ErrInvalidAPITokenScope = errors.New("scopes must be read, write or admin")
+
+	// ErrTooManyAPITokens is returned when a user already has as many tokens as allowed.
+	ErrTooManyAPITokens = fmt.Errorf("a user may have at most %d API tokens", maxAPITokensPerUser)
+)
+
+// APITokenRequest describes a personal API token to create.
+type APITokenRequest struct {
+	Name          string   `json:"name"`
+	Scopes        []string `json:"scopes"`
+	ExpiresInDays int      `json:"expires_in_days"` // Defaults to 30.
+}
+
+// NewAPIToken is a newly created API token together with the token itself, which cannot be
+// retrieved again.
+type NewAPIToken struct {
+	model.APIToken
+	Token string `json:"token"`
+}
+
+// APITokenService manages personal API tokens and authenticates requests made with them.
+type APITokenService interface {
+	CreateAPIToken(userID uint, request APITokenRequest) (*NewAPIToken, error)
+	ListAPITokens(userID uint) ([]model.APIToken, error)
+	RevokeAPIToken(userID, tokenID uint) error
+	// Authenticate returns the API token a request presented and records that it was used.
+	Authenticate(token string) (*model.APIToken, error)
+}
+
+type apiTokenService struct {
+	musicDAO dao.MusicDAO
+	now      func() time.Time
+}
+
+func NewAPITokenService(musicDAO dao.MusicDAO) APITokenService {
+	return &apiTokenService{musicDAO: musicDAO, now: time.Now}
+}
+
+// IsAPIToken reports whether a bearer token looks like a personal API token rather than a JWT.
+func IsAPIToken(token string) bool {
+	return strings.HasPrefix(token, apiTokenPrefix)
+}
+
+// CreateAPIToken creates a token for the user with the requested name, scopes and lifetime.
+func (s *apiTokenService) CreateAPIToken(userID uint, request APITokenRequest) (*NewAPIToken, error) {
+	for _, scope := range request.Scopes {
+		if !validAPITokenScope(scope) {
+			return nil, ErrInvalidAPITokenScope
+		}
+	}
+	days := request.ExpiresInDays
+	if days == 0 {
+		days = defaultAPITokenLifetimeDays
+	}
+	days = min(days, maxAPITokenLifetimeDays)
+
+	existing, err := s.musicDAO.GetAPITokens(userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= maxAPITokensPerUser {
+		return nil, ErrTooManyAPITokens
+	}
+
+	secret, err := newAPITokenSecret()
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	token := &NewAPIToken{
+		APIToken: model.APIToken{
+			UserID:    userID,
+			Name:      request.Name,
+			Prefix:    secret[:apiTokenDisplayLength],
+			TokenHash: hashAPIToken(secret),
+			Scopes:    request.Scopes,
+			ExpiresAt: now.AddDate(0, 0, days),
+		},
+		Token: secret,
+	}
+	if err := s.musicDAO.CreateAPIToken(&token.APIToken); err != nil {
+		return nil, err
+	}
+
+	s.audit(model.AuditEvent{
+		Type:    model.AuditAPITokenCreated,
+		ActorID: userID,
+		Detail:  fmt.Sprintf("token %d %q with scopes %s", token.ID, token.Name, strings.Join(token.Scopes, " ")),
+	})
+	return token, nil
+}
+
+// ListAPITokens returns the user's tokens that have not been revoked, including expired ones.
+func (s *apiTokenService) ListAPITokens(userID uint) ([]model.APIToken, error) {
+	tokens, err := s.musicDAO.GetAPITokens(userID)
+	if err != nil {
+		return nil, err
+	}
+	if tokens == nil {
+		tokens = []model.APIToken{}
+	}
+	return tokens, nil
+}
+
+// RevokeAPIToken revokes one of the user's tokens. Requests made with it are refused from then on.
+func (s *apiTokenService) RevokeAPIToken(userID, tokenID uint) error {
+	if err := s.musicDAO.RevokeAPIToken(userID, tokenID, s.now()); err != nil {
+		return err
+	}
+	s.audit(model.AuditEvent{Type: model.AuditAPITokenRevoked, ActorID: userID, Detail: fmt.Sprintf("token %d", tokenID)})
+	return nil
+}
+
+// Authenticate looks a token up by its hash. It returns ErrInvalidAPIToken for unknown or revoked
+// tokens and ErrAPITokenExpired for expired ones.
+func (s *apiTokenService) Authenticate(secret string) (*model.APIToken, error) {
+	if !IsAPIToken(secret) {
+		return nil, ErrInvalidAPIToken
+	}
+	token, err := s.musicDAO.GetAPITokenByHash(hashAPIToken(secret))
+	if errors.Is(err, dao.ErrAPITokenNotFound) {
+		return nil, ErrInvalidAPIToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	if !now.Before(token.ExpiresAt) {
+		return nil, ErrAPITokenExpired
+	}
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) >= lastUsedResolution {
+		// A failed update must not fail the request it was made for.
+		if err := s.musicDAO.UpdateAPITokenLastUsed(token.ID, now); err != nil {
+			log.Printf("Failed to record use of API token %d: %v", token.ID, err)
+		} else {
+			token.LastUsedAt = &now
+		}
+	}
+	return token, nil
+}
+
+func (s *apiTokenService) audit(event model.AuditEvent) {
+	if err := s.musicDAO.CreateAuditEvent(&event); err != nil {
+		log.Printf("Failed to record audit event %s: %v", event.Type, err)
+	}
+}
+
+func validAPITokenScope(scope string) bool {
+	switch scope {
+	case model.ScopeRead, model.ScopeWrite, model.ScopeAdmin:
+		return true
+	}
+	return false
+}
+
+// newAPITokenSecret returns a new token with 256 random bits.
+func newAPITokenSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return apiTokenPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashAPIToken returns the hash a token is stored and looked up by. The token is random enough
+// that a fast hash is safe, unlike for passwords.
+func hashAPIToken(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/dao/mocks"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestAPITokenService(mockDAO *mocks.MusicDAO, now time.Time) *apiTokenService {
+	s := NewAPITokenService(mockDAO).(*apiTokenService)
+	s.now = func() time.Time { return now }
+	return s
+}
+
+func TestCreateAPITokenStoresOnlyHash(t *testing.T) {
+	mockDAO := new(mocks.MusicDAO)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	s := newTestAPITokenService(mockDAO, now)
+
+	var stored *model.APIToken
+	mockDAO.On("GetAPITokens", uint(7)).Return([]model.APIToken{}, nil)
+	mockDAO.On("CreateAPIToken", mock.AnythingOfType("*model.APIToken")).Run(func(args mock.Arguments) {
+		stored = args.Get(0).(*model.APIToken)
+		stored.ID = 3
+	}).Return(nil)
+	mockDAO.On("CreateAuditEvent", mock.MatchedBy(func(event *model.AuditEvent) bool {
+		return event.Type == model.AuditAPITokenCreated && event.ActorID == 7
+	})).Return(nil)
+
+	token, err := s.CreateAPIToken(7, APITokenRequest{Name: "ci", Scopes: []string{"read"}})
+	assert.NoError(t, err)
+	assert.True(t, IsAPIToken(token.Token))
+	assert.Equal(t, uint(3), token.ID)
+	assert.Equal(t, now.AddDate(0, 0, defaultAPITokenLifetimeDays), token.ExpiresAt)
+	assert.Equal(t, hashAPIToken(token.Token), stored.TokenHash)
+	assert.True(t, strings.HasPrefix(token.Token, stored.Prefix))
+	mockDAO.AssertExpectations(t)
+}
+
+func TestCreateAPITokenLimits(t *testing.T) {
+	mockDAO := new(mocks.MusicDAO)
+	s := newTestAPITokenService(mockDAO, time.Now())
+
+	_, err := s.CreateAPIToken(7, APITokenRequest{Name: "ci", Scopes: []string{"delete"}})
+	assert.ErrorIs(t, err, ErrInvalidAPITokenScope)
+
+	mockDAO.On("GetAPITokens", uint(7)).Return(make([]model.APIToken, maxAPITokensPerUser), nil)
+	_, err = s.CreateAPIToken(7, APITokenRequest{Name: "ci", Scopes: []string{"read"}})
+	assert.ErrorIs(t, err, ErrTooManyAPITokens)
+	mockDAO.AssertNotCalled(t, "CreateAPIToken", mock.Anything)
+}
+
+func TestAuthenticateAPIToken(t *testing.T) {
+	mockDAO := new(mocks.MusicDAO)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	s := newTestAPITokenService(mockDAO, now)
+	secret, err := newAPITokenSecret()
+	assert.NoError(t, err)
+
+	mockDAO.On("GetAPITokenByHash", hashAPIToken(secret)).Return(&model.APIToken{ID: 3, UserID: 7, ExpiresAt: now.Add(time.Hour)}, nil)
+	mockDAO.On("UpdateAPITokenLastUsed", uint(3), now).Return(nil).Once()
+
+	token, err := s.Authenticate(secret)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(7), token.UserID)
+	assert.Equal(t, now, *token.LastUsedAt)
+	mockDAO.AssertExpectations(t)
+}
+
+func TestAuthenticateAPITokenRecordsUseAtMostOncePerMinute(t *testing.T) {
+	mockDAO := new(mocks.MusicDAO)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	s := newTestAPITokenService(mockDAO, now)
+	lastUsed := now.Add(-30 * time.Second)
+
+	mockDAO.On("GetAPITokenByHash", hashAPIToken("gmk_recent")).Return(&model.APIToken{ID: 3, ExpiresAt: now.Add(time.Hour), LastUsedAt: &lastUsed}, nil)
+
+	_, err := s.Authenticate("gmk_recent")
+	assert.NoError(t, err)
+	mockDAO.AssertNotCalled(t, "UpdateAPITokenLastUsed", mock.Anything, mock.Anything)
+}
+
+func TestAuthenticateRejectsExpiredAndUnknownTokens(t *testing.T) {
+	mockDAO := new(mocks.MusicDAO)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	s := newTestAPITokenService(mockDAO, now)
+
+	mockDAO.On("GetAPITokenByHash", hashAPIToken("gmk_expired")).Return(&model.APIToken{ID: 3, ExpiresAt: now}, nil)
+	mockDAO.On("GetAPITokenByHash", hashAPIToken("gmk_unknown")).Return(nil, ErrAPITokenNotFound)
+
+	_, err := s.Authenticate("gmk_expired")
+	assert.ErrorIs(t, err, ErrAPITokenExpired)
+	_, err = s.Authenticate("gmk_unknown")
+	assert.ErrorIs(t, err, ErrInvalidAPIToken)
+	_, err = s.Authenticate("eyJhbGciOiJSUzI1NiJ9.e30.sig")
+	assert.ErrorIs(t, err, ErrInvalidAPIToken)
+	mockDAO.AssertNotCalled(t, "UpdateAPITokenLastUsed", mock.Anything, mock.Anything)
+}
+
+func TestAPITokenScopes(t *testing.T) {
+	token := &model.APIToken{Scopes: []string{model.ScopeWrite}}
+	assert.True(t, token.HasScope(model.ScopeRead))
+	assert.True(t, token.HasScope(model.ScopeWrite))
+	assert.False(t, token.HasScope(model.ScopeAdmin))
+
+	token = &model.APIToken{Scopes: []string{model.ScopeRead}}
+	assert.False(t, token.HasScope(model.ScopeWrite))
+}
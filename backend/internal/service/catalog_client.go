@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/model"
+)
+
+const (
+	spotifyAPIBaseURL = "https://api.spotify.com/v1"
+
+	// spotifyMaxTracksPerRequest is the maximum number of IDs accepted by the several-tracks endpoint.
+	spotifyMaxTracksPerRequest = 50
+)
+
+// CatalogClient fetches track metadata from the music catalog.
+type CatalogClient interface {
+	// GetTracks fetches the given tracks, keyed by Spotify ID. Tracks the catalog
+	// no longer knows about are absent from the result.
+	GetTracks(ctx context.Context, spotifyIDs []string) (map[string]*model.Song, error)
+	// GetPlaylist fetches a Spotify playlist with all of its tracks, in playlist order.
+	GetPlaylist(ctx context.Context, spotifyID string) (*CatalogCollection, error)
+	// GetAlbum fetches a Spotify album with all of its tracks, in track order.
+	GetAlbum(ctx context.Context, spotifyID string) (*CatalogCollection, error)
+}
+
+// CatalogCollection is an ordered list of tracks from the catalog, such as a playlist or an album.
+type CatalogCollection struct {
+	Name     string
+	ImageURL string
+	Tracks   []*model.Song
+}
+
+type spotifyCatalogClient struct {
+	httpClient *http.Client
+	baseURL    string
+}
+
+// NewSpotifyCatalogClient creates a CatalogClient backed by the Spotify Web API.
+func NewSpotifyCatalogClient() CatalogClient {
+	return &spotifyCatalogClient{httpClient: newSpotifyHTTPClient(), baseURL: spotifyAPIBaseURL}
+}
+
+// spotifyTrack is the track object returned by the Spotify Web API.
+type spotifyTrack struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Artists []struct {
+		Name string `json:"name"`
+	} `json:"artists"`
+	Album struct {
+		Name   string         `json:"name"`
+		Images []spotifyImage `json:"images"`
+	} `json:"album"`
+	PreviewURL   string `json:"preview_url"`
+	ExternalURLs struct {
+		Spotify string `json:"spotify"`
+	} `json:"external_urls"`
+}
+
+// toSong converts a Spotify track into a Song.
+func (t *spotifyTrack) toSong() *model.Song {
+	song := &model.Song{
+		SpotifyID:   t.ID,
+		Name:        t.Name,
+		AlbumName:   t.Album.Name,
+		PreviewURL:  t.PreviewURL,
+		ExternalURL: t.ExternalURLs.Spotify,
+	}
+	if len(t.Artists) > 0 {
+		song.Artist = t.Artists[0].Name
+	}
+	if len(t.Album.Images) > 0 {
+		song.AlbumImageURL = t.Album.Images[0].URL
+	}
+	return song
+}
+
+// GetTracks fetches tracks through Spotify's several-tracks endpoint, in chunks of up to 50 IDs.
+func (c *spotifyCatalogClient) GetTracks(ctx context.Context, spotifyIDs []string) (map[string]*model.Song, error) {
+	songs := make(map[string]*model.Song, len(spotifyIDs))
+	for start := 0; start < len(spotifyIDs); start += spotifyMaxTracksPerRequest {
+		end := start + spotifyMaxTracksPerRequest
+		if end > len(spotifyIDs) {
+			end = len(spotifyIDs)
+		}
+
+		var response struct {
+			Tracks []*spotifyTrack `json:"tracks"`
+		}
+		requestURL := fmt.Sprintf("%s/tracks?ids=%s", c.baseURL, url.QueryEscape(strings.Join(spotifyIDs[start:end], ",")))
+		if err := c.getJSON(ctx, requestURL, &response); err != nil {
+			return nil, err
+		}
+
+		// Unknown IDs come back as null entries.
+		for _, track := range response.Tracks {
+			if track != nil {
+				songs[track.ID] = track.toSong()
+			}
+		}
+	}
+	return songs, nil
+}
+
+// spotifyImage is an image object returned by the Spotify Web API.
+type spotifyImage struct {
+	URL string `json:"url"`
+}
+
+// spotifyPage holds the paging fields shared by Spotify paging objects.
+type spotifyPage struct {
+	Next string `json:"next"`
+}
+
+// GetPlaylist fetches a playlist and follows the paging links until all tracks are loaded.
+// Local files and podcast episodes are skipped.
+func (c *spotifyCatalogClient) GetPlaylist(ctx context.Context, spotifyID string) (*CatalogCollection, error) {
+	var playlist struct {
+		Name   string         `json:"name"`
+		Images []spotifyImage `json:"images"`
+	}
+	if err := c.getJSON(ctx, fmt.Sprintf("%s/playlists/%s?fields=name,images", c.baseURL, url.PathEscape(spotifyID)), &playlist); err != nil {
+		return nil, err
+	}
+
+	collection := &CatalogCollection{Name: playlist.Name}
+	if len(playlist.Images) > 0 {
+		collection.ImageURL = playlist.Images[0].URL
+	}
+
+	next := fmt.Sprintf("%s/playlists/%s/tracks?limit=100&additional_types=track", c.baseURL, url.PathEscape(spotifyID))
+	for next != "" {
+		var page struct {
+			spotifyPage
+			Items []struct {
+				Track *struct {
+					spotifyTrack
+					Type string `json:"type"`
+				} `json:"track"`
+			} `json:"items"`
+		}
+		if err := c.getJSON(ctx, next, &page); err != nil {
+			return nil, err
+		}
+
+		for _, item := range page.Items {
+			if item.Track == nil || item.Track.ID == "" || item.Track.Type != "track" {
+				continue
+			}
+			collection.Tracks = append(collection.Tracks, item.Track.toSong())
+		}
+		next = page.Next
+	}
+
+	return collection, nil
+}
+
+// GetAlbum fetches an album and follows the paging links until all tracks are loaded.
+// Album tracks are returned without album details, so those are copied from the album itself.
+func (c *spotifyCatalogClient) GetAlbum(ctx context.Context, spotifyID string) (*CatalogCollection, error) {
+	type trackPage struct {
+		spotifyPage
+		Items []*spotifyTrack `json:"items"`
+	}
+
+	var album struct {
+		Name   string         `json:"name"`
+		Images []spotifyImage `json:"images"`
+		Tracks trackPage      `json:"tracks"`
+	}
+	if err := c.getJSON(ctx, fmt.Sprintf("%s/albums/%s", c.baseURL, url.PathEscape(spotifyID)), &album); err != nil {
+		return nil, err
+	}
+
+	collection := &CatalogCollection{Name: album.Name}
+	if len(album.Images) > 0 {
+		collection.ImageURL = album.Images[0].URL
+	}
+
+	page := album.Tracks
+	for {
+		for _, track := range page.Items {
+			if track == nil || track.ID == "" {
+				continue
+			}
+			song := track.toSong()
+			song.AlbumName = collection.Name
+			song.AlbumImageURL = collection.ImageURL
+			collection.Tracks = append(collection.Tracks, song)
+		}
+
+		if page.Next == "" {
+			break
+		}
+		next := page.Next
+		page = trackPage{}
+		if err := c.getJSON(ctx, next, &page); err != nil {
+			return nil, err
+		}
+	}
+
+	return collection, nil
+}
+
+// getJSON performs a GET request against the Spotify API and decodes the JSON response into v.
+func (c *spotifyCatalogClient) getJSON(ctx context.Context, requestURL string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrFetchingFromSpotify, err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrFetchingFromSpotify, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		log.Printf("Spotify request %s failed with status code: %d", requestURL, resp.StatusCode)
+		return fmt.Errorf("%w: status code %d", ErrFetchingFromSpotify, resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("%w: %v", ErrFetchingFromSpotify, err)
+	}
+	return nil
+}
@@ -0,0 +1,224 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/dao"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/model"
+)
+
+// CatalogRefreshConfig controls how often and how much of the song catalog is refreshed.
+type CatalogRefreshConfig struct {
+	Interval  time.Duration // Time between refresh runs; zero disables the worker.
+	MaxAge    time.Duration // Songs refreshed longer ago than this are considered stale.
+	BatchSize int           // Number of songs fetched from Spotify per request.
+}
+
+// CatalogRefreshStats counts the work done by a refresh run.
+type CatalogRefreshStats struct {
+	Batches     int `json:"batches"`
+	Checked     int `json:"checked"`
+	Updated     int `json:"updated"`
+	Unavailable int `json:"unavailable"`
+}
+
+// CatalogRefreshStatus reports the progress of the catalog refresh worker.
+type CatalogRefreshStatus struct {
+	Running           bool                `json:"running"`
+	Interval          string              `json:"interval"`
+	MaxAge            string              `json:"max_age"`
+	BatchSize         int                 `json:"batch_size"`
+	LastRunStartedAt  *time.Time          `json:"last_run_started_at,omitempty"`
+	LastRunFinishedAt *time.Time          `json:"last_run_finished_at,omitempty"`
+	LastError         string              `json:"last_error,omitempty"`
+	LastRun           CatalogRefreshStats `json:"last_run"` // Progress of the running or most recent run.
+	Total             CatalogRefreshStats `json:"total"`
+}
+
+// CatalogRefreshService periodically re-fetches stale song metadata from Spotify.
+type CatalogRefreshService interface {
+	Run(ctx context.Context)
+	RefreshStaleSongs(ctx context.Context) (CatalogRefreshStats, error)
+	Status() CatalogRefreshStatus
+}
+
+type catalogRefreshService struct {
+	songDAO dao.MusicDAO
+	catalog CatalogClient
+	config  CatalogRefreshConfig
+	now     func() time.Time
+
+	mu     sync.Mutex
+	status CatalogRefreshStatus
+}
+
+func NewCatalogRefreshService(songDAO dao.MusicDAO, catalog CatalogClient, config CatalogRefreshConfig) CatalogRefreshService {
+	if config.BatchSize <= 0 || config.BatchSize > spotifyMaxTracksPerRequest {
+		config.BatchSize = spotifyMaxTracksPerRequest
+	}
+	return &catalogRefreshService{
+		songDAO: songDAO,
+		catalog: catalog,
+		config:  config,
+		now:     time.Now,
+		status: CatalogRefreshStatus{
+			Interval:  config.Interval.String(),
+			MaxAge:    config.MaxAge.String(),
+			BatchSize: config.BatchSize,
+		},
+	}
+}
+
+// Run refreshes the catalog immediately and then on every interval until ctx is cancelled.
+func (s *catalogRefreshService) Run(ctx context.Context) {
+	if s.config.Interval <= 0 {
+		log.Println("Catalog refresh worker disabled")
+		return
+	}
+
+	log.Printf("Starting catalog refresh worker (interval %s, max age %s)", s.config.Interval, s.config.MaxAge)
+	ticker := time.NewTicker(s.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.RefreshStaleSongs(ctx); err != nil {
+			log.Printf("Catalog refresh failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Println("Stopping catalog refresh worker")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RefreshStaleSongs re-fetches every stale song in batches, updating changed metadata and
+// marking songs Spotify no longer returns as unavailable.
+func (s *catalogRefreshService) RefreshStaleSongs(ctx context.Context) (CatalogRefreshStats, error) {
+	s.startRun()
+
+	var stats CatalogRefreshStats
+	err := s.refresh(ctx, &stats)
+
+	s.finishRun(stats, err)
+	return stats, err
+}
+
+func (s *catalogRefreshService) refresh(ctx context.Context, stats *CatalogRefreshStats) error {
+	cutoff := s.now().Add(-s.config.MaxAge)
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		songs, err := s.songDAO.GetStaleSongs(cutoff, s.config.BatchSize)
+		if err != nil {
+			return err
+		}
+		if len(songs) == 0 {
+			return nil
+		}
+
+		spotifyIDs := make([]string, len(songs))
+		for i, song := range songs {
+			spotifyIDs[i] = song.SpotifyID
+		}
+
+		tracks, err := s.catalog.GetTracks(ctx, spotifyIDs)
+		if err != nil {
+			return err
+		}
+
+		refreshedAt := s.now()
+		for i := range songs {
+			song := &songs[i]
+			track, found := tracks[song.SpotifyID]
+			if !found {
+				song.Unavailable = true
+				stats.Unavailable++
+			} else {
+				if applySongMetadata(song, track) {
+					stats.Updated++
+				}
+				song.Unavailable = false
+			}
+			song.RefreshedAt = &refreshedAt
+
+			if err := s.songDAO.UpdateSong(song); err != nil {
+				return err
+			}
+			stats.Checked++
+		}
+		stats.Batches++
+		s.reportProgress(*stats)
+
+		if len(songs) < s.config.BatchSize {
+			return nil
+		}
+	}
+}
+
+// applySongMetadata copies catalog metadata onto song and reports whether anything changed.
+func applySongMetadata(song, track *model.Song) bool {
+	changed := false
+	for _, field := range []struct{ dst, src *string }{
+		{&song.Name, &track.Name},
+		{&song.Artist, &track.Artist},
+		{&song.AlbumName, &track.AlbumName},
+		{&song.AlbumImageURL, &track.AlbumImageURL},
+		{&song.PreviewURL, &track.PreviewURL},
+		{&song.ExternalURL, &track.ExternalURL},
+	} {
+		if *field.dst != *field.src {
+			*field.dst = *field.src
+			changed = true
+		}
+	}
+	return changed
+}
+
+// Status returns a snapshot of the worker's progress.
+func (s *catalogRefreshService) Status() CatalogRefreshStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.status
+}
+
+func (s *catalogRefreshService) startRun() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	startedAt := s.now()
+	s.status.Running = true
+	s.status.LastRunStartedAt = &startedAt
+	s.status.LastRun = CatalogRefreshStats{}
+}
+
+func (s *catalogRefreshService) reportProgress(stats CatalogRefreshStats) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status.LastRun = stats
+}
+
+func (s *catalogRefreshService) finishRun(stats CatalogRefreshStats, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	finishedAt := s.now()
+	s.status.Running = false
+	s.status.LastRunFinishedAt = &finishedAt
+	s.status.LastRun = stats
+	s.status.LastError = ""
+	if err != nil {
+		s.status.LastError = err.Error()
+	}
+	s.status.Total.Batches += stats.Batches
+	s.status.Total.Checked += stats.Checked
+	s.status.Total.Updated += stats.Updated
+	s.status.Total.Unavailable += stats.Unavailable
+	log.Printf("Catalog refresh finished: %d checked, %d updated, %d unavailable", stats.Checked, stats.Updated, stats.Unavailable)
+}
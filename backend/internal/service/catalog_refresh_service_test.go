@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/dao/mocks"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// fakeCatalogClient is an in-memory CatalogClient keyed by Spotify ID.
+type fakeCatalogClient struct {
+	tracks      map[string]*model.Song
+	collections map[string]*CatalogCollection
+	err         error
+}
+
+func (f *fakeCatalogClient) GetTracks(ctx context.Context, spotifyIDs []string) (map[string]*model.Song, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	result := make(map[string]*model.Song)
+	for _, id := range spotifyIDs {
+		if track, ok := f.tracks[id]; ok {
+			result[id] = track
+		}
+	}
+	return result, nil
+}
+
+func (f *fakeCatalogClient) GetPlaylist(ctx context.Context, spotifyID string) (*CatalogCollection, error) {
+	return f.getCollection(spotifyID)
+}
+
+func (f *fakeCatalogClient) GetAlbum(ctx context.Context, spotifyID string) (*CatalogCollection, error) {
+	return f.getCollection(spotifyID)
+}
+
+func (f *fakeCatalogClient) getCollection(spotifyID string) (*CatalogCollection, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	collection, ok := f.collections[spotifyID]
+	if !ok {
+		return nil, ErrFetchingFromSpotify
+	}
+	return collection, nil
+}
+
+func TestRefreshStaleSongs(t *testing.T) {
+	mockDAO := new(mocks.MusicDAO)
+	catalog := &fakeCatalogClient{tracks: map[string]*model.Song{
+		"changed":   {SpotifyID: "changed", Name: "Song", Artist: "Artist", PreviewURL: "https://p.scdn.co/new"},
+		"unchanged": {SpotifyID: "unchanged", Name: "Other", Artist: "Artist"},
+	}}
+	rs := NewCatalogRefreshService(mockDAO, catalog, CatalogRefreshConfig{MaxAge: time.Hour, BatchSize: 10})
+
+	staleSongs := []model.Song{
+		{SpotifyID: "changed", Name: "Song", Artist: "Artist", PreviewURL: "https://p.scdn.co/old"},
+		{SpotifyID: "unchanged", Name: "Other", Artist: "Artist"},
+		{SpotifyID: "gone", Name: "Gone", Artist: "Artist"},
+	}
+	mockDAO.On("GetStaleSongs", mock.AnythingOfType("time.Time"), 10).Return(staleSongs, nil).Once()
+
+	var updated []model.Song
+	mockDAO.On("UpdateSong", mock.AnythingOfType("*model.Song")).Run(func(args mock.Arguments) {
+		updated = append(updated, *args.Get(0).(*model.Song))
+	}).Return(nil)
+
+	stats, err := rs.RefreshStaleSongs(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, CatalogRefreshStats{Batches: 1, Checked: 3, Updated: 1, Unavailable: 1}, stats)
+	mockDAO.AssertExpectations(t)
+
+	assert.Len(t, updated, 3)
+	assert.Equal(t, "https://p.scdn.co/new", updated[0].PreviewURL)
+	assert.False(t, updated[1].Unavailable)
+	assert.True(t, updated[2].Unavailable)
+	for _, song := range updated {
+		assert.NotNil(t, song.RefreshedAt)
+	}
+
+	status := rs.Status()
+	assert.False(t, status.Running)
+	assert.Equal(t, stats, status.LastRun)
+	assert.Equal(t, stats, status.Total)
+}
+
+func TestRefreshStaleSongsCatalogError(t *testing.T) {
+	mockDAO := new(mocks.MusicDAO)
+	catalog := &fakeCatalogClient{err: ErrFetchingFromSpotify}
+	rs := NewCatalogRefreshService(mockDAO, catalog, CatalogRefreshConfig{MaxAge: time.Hour, BatchSize: 10})
+
+	mockDAO.On("GetStaleSongs", mock.AnythingOfType("time.Time"), 10).Return([]model.Song{{SpotifyID: "id"}}, nil).Once()
+
+	_, err := rs.RefreshStaleSongs(context.Background())
+	assert.True(t, errors.Is(err, ErrFetchingFromSpotify))
+	mockDAO.AssertNotCalled(t, "UpdateSong", mock.Anything)
+	assert.Equal(t, ErrFetchingFromSpotify.Error(), rs.Status().LastError)
+}
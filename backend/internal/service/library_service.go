@@ -0,0 +1,93 @@
+package service
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/dao"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/model"
+)
+
+var ErrInvalidLibrarySort = errors.New("sort must be added_desc or added_asc")
+
+const (
+	LibrarySortAddedDesc = "added_desc"
+	LibrarySortAddedAsc  = "added_asc"
+
+	defaultLibraryLimit = 50
+	maxLibraryLimit     = 200
+)
+
+// LikedSongsPage is a page of a user's liked songs. Offset is passed back with the limit to fetch
+// the following pages.
+type LikedSongsPage struct {
+	Songs  []model.LikedSong `json:"songs"`
+	Total  int64             `json:"total"`
+	Offset int               `json:"offset"`
+	Limit  int               `json:"limit"`
+}
+
+// LibraryService manages the songs users keep in their personal library.
+type LibraryService interface {
+	LikeSong(userID uint, songID string) error
+	UnlikeSong(userID uint, songID string) error
+	GetLikedSongs(userID uint, sort string, offset, limit int) (*LikedSongsPage, error)
+}
+
+type libraryService struct {
+	musicDAO dao.MusicDAO
+}
+
+func NewLibraryService(musicDAO dao.MusicDAO) LibraryService {
+	return &libraryService{musicDAO: musicDAO}
+}
+
+// LikeSong adds a song to the user's library. Liking a song that is already liked does nothing.
+func (s *libraryService) LikeSong(userID uint, songID string) error {
+	id, err := strconv.ParseUint(songID, 10, 64)
+	if err != nil {
+		return ErrSongNotFound
+	}
+	return s.musicDAO.LikeSong(userID, uint(id))
+}
+
+// UnlikeSong removes a song from the user's library.
+func (s *libraryService) UnlikeSong(userID uint, songID string) error {
+	id, err := strconv.ParseUint(songID, 10, 64)
+	if err != nil {
+		return ErrSongNotFound
+	}
+	return s.musicDAO.UnlikeSong(userID, uint(id))
+}
+
+// GetLikedSongs returns a page of the user's liked songs, most recently liked first unless sort
+// asks for the oldest first.
+func (s *libraryService) GetLikedSongs(userID uint, sort string, offset, limit int) (*LikedSongsPage, error) {
+	var oldestFirst bool
+	switch sort {
+	case "", LibrarySortAddedDesc:
+	case LibrarySortAddedAsc:
+		oldestFirst = true
+	default:
+		return nil, ErrInvalidLibrarySort
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	if limit <= 0 {
+		limit = defaultLibraryLimit
+	}
+	if limit > maxLibraryLimit {
+		limit = maxLibraryLimit
+	}
+
+	songs, total, err := s.musicDAO.GetLikedSongs(userID, oldestFirst, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	page := &LikedSongsPage{Songs: songs, Total: total, Offset: offset, Limit: limit}
+	if page.Songs == nil {
+		page.Songs = []model.LikedSong{}
+	}
+	return page, nil
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/dao/mocks"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLikeSong(t *testing.T) {
+	mockDAO := new(mocks.MusicDAO)
+	ls := NewLibraryService(mockDAO)
+
+	mockDAO.On("LikeSong", uint(1), uint(5)).Return(nil).Once()
+	assert.NoError(t, ls.LikeSong(1, "5"))
+
+	mockDAO.On("LikeSong", uint(1), uint(6)).Return(ErrSongNotFound).Once()
+	assert.Equal(t, ErrSongNotFound, ls.LikeSong(1, "6"))
+
+	assert.Equal(t, ErrSongNotFound, ls.LikeSong(1, "abc"))
+	mockDAO.AssertExpectations(t)
+}
+
+func TestUnlikeSong(t *testing.T) {
+	mockDAO := new(mocks.MusicDAO)
+	ls := NewLibraryService(mockDAO)
+
+	mockDAO.On("UnlikeSong", uint(1), uint(5)).Return(nil).Once()
+	assert.NoError(t, ls.UnlikeSong(1, "5"))
+	mockDAO.AssertExpectations(t)
+}
+
+func TestGetLikedSongs(t *testing.T) {
+	mockDAO := new(mocks.MusicDAO)
+	ls := NewLibraryService(mockDAO)
+
+	liked := []model.LikedSong{{UserID: 1, SongID: 5}, {UserID: 1, SongID: 3}}
+	mockDAO.On("GetLikedSongs", uint(1), false, 0, defaultLibraryLimit).Return(liked, int64(2), nil).Once()
+	mockDAO.On("GetLikedSongs", uint(1), true, 10, maxLibraryLimit).Return(nil, int64(2), nil).Once()
+
+	page, err := ls.GetLikedSongs(1, "", 0, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, liked, page.Songs)
+	assert.Equal(t, int64(2), page.Total)
+	assert.Equal(t, defaultLibraryLimit, page.Limit)
+
+	page, err = ls.GetLikedSongs(1, LibrarySortAddedAsc, 10, 1000)
+	assert.NoError(t, err)
+	assert.Empty(t, page.Songs)
+	assert.NotNil(t, page.Songs)
+	assert.Equal(t, maxLibraryLimit, page.Limit)
+
+	_, err = ls.GetLikedSongs(1, "name", 0, 0)
+	assert.Equal(t, ErrInvalidLibrarySort, err)
+	mockDAO.AssertExpectations(t)
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/dao"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/model"
+)
+
+// LoginThrottledError is returned when a login is refused because of earlier failed attempts for
+// the same username or from the same client IP. The password is not checked.
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginThrottledError) Error() string {
+	return fmt.Sprintf("too many failed login attempts, retry in %s", e.RetryAfter.Round(time.Second))
+}
+
+// loginThrottlePolicy decides how long logins are refused after a number of failures.
+type loginThrottlePolicy struct {
+	freeAttempts    int           // Failures allowed before logins are delayed.
+	baseDelay       time.Duration // Delay after the first failure beyond freeAttempts; doubles with each further failure.
+	lockoutAfter    int           // Failures that lock logins for lockoutDuration.
+	lockoutDuration time.Duration
+	resetAfter      time.Duration // Failures are forgotten after this long without another one.
+}
+
+var (
+	// Usernames are protected tightly; this is what stops password guessing against one account.
+	usernameThrottle = loginThrottlePolicy{
+		freeAttempts:    3,
+		baseDelay:       time.Second,
+		lockoutAfter:    10,
+		lockoutDuration: 15 * time.Minute,
+		resetAfter:      time.Hour,
+	}
+	// Client IPs get more room, since many users may share an address behind a NAT.
+	ipThrottle = loginThrottlePolicy{
+		freeAttempts:    10,
+		baseDelay:       time.Second,
+		lockoutAfter:    50,
+		lockoutDuration: 15 * time.Minute,
+		resetAfter:      time.Hour,
+	}
+)
+
+// delay returns how long logins are refused after the given number of consecutive failures.
+func (p loginThrottlePolicy) delay(failures int) time.Duration {
+	if failures >= p.lockoutAfter {
+		return p.lockoutDuration
+	}
+	if failures <= p.freeAttempts {
+		return 0
+	}
+	delay := p.baseDelay
+	for i := p.freeAttempts + 1; i < failures && delay < p.lockoutDuration; i++ {
+		delay *= 2
+	}
+	return min(delay, p.lockoutDuration)
+}
+
+// LoginService checks credentials while slowing down and locking out repeated failures.
+type LoginService interface {
+	Login(username, password, ip string) (uint, error)
+	UnlockUser(username string, adminID uint) error
+}
+
+type loginService struct {
+	musicDAO    dao.MusicDAO
+	userService UserService
+	now         func() time.Time
+}
+
+func NewLoginService(musicDAO dao.MusicDAO, userService UserService) LoginService {
+	return &loginService{musicDAO: musicDAO, userService: userService, now: time.Now}
+}
+
+func usernameThrottleKey(username string) string {
+	return "username:" + strings.ToLower(username)
+}
+
+func ipThrottleKey(ip string) string {
+	return "ip:" + ip
+}
+
+// Login returns the ID of the user with the given credentials. It returns a *LoginThrottledError
+// while the username or IP is locked, and ErrInvalidCredentials otherwise. Unknown usernames are
+// throttled like existing ones, so lockouts do not reveal which accounts exist.
+func (s *loginService) Login(username, password, ip string) (uint, error) {
+	now := s.now()
+	usernameKey, ipKey := usernameThrottleKey(username), ipThrottleKey(ip)
+
+	throttles, err := s.musicDAO.GetLoginThrottles([]string{usernameKey, ipKey})
+	if err != nil {
+		return 0, err
+	}
+	var retryAfter time.Duration
+	for _, throttle := range throttles {
+		if throttle.LockedUntil != nil && throttle.LockedUntil.After(now) {
+			retryAfter = max(retryAfter, throttle.LockedUntil.Sub(now))
+		}
+	}
+	if retryAfter > 0 {
+		return 0, &LoginThrottledError{RetryAfter: retryAfter}
+	}
+
+	userID, valid := s.userService.ValidateUser(username, password)
+	if valid {
+		// Only the username is cleared: an attacker must not reset their IP's count by logging into their own account.
+		if err := s.musicDAO.DeleteLoginThrottle(usernameKey); err != nil {
+			log.Printf("Failed to reset failed logins for %s: %v", usernameKey, err)
+		}
+		return userID, nil
+	}
+
+	s.recordFailure(usernameKey, usernameThrottle, now, model.AuditEvent{Username: username, IP: ip})
+	s.recordFailure(ipKey, ipThrottle, now, model.AuditEvent{IP: ip})
+	return 0, ErrInvalidCredentials
+}
+
+// recordFailure counts a failed login for key and delays or locks further logins as the policy
+// says. Reaching the lockout threshold is recorded in the audit log. Errors are only logged, so
+// that the caller still gets the invalid credentials error.
+func (s *loginService) recordFailure(key string, policy loginThrottlePolicy, now time.Time, event model.AuditEvent) {
+	throttle, err := s.musicDAO.RecordLoginFailure(key, now, now.Add(-policy.resetAfter))
+	if err != nil {
+		log.Printf("Failed to record failed login for %s: %v", key, err)
+		return
+	}
+	delay := policy.delay(throttle.Failures)
+	if delay == 0 {
+		return
+	}
+	if err := s.musicDAO.LockLogin(key, now.Add(delay)); err != nil {
+		log.Printf("Failed to lock logins for %s: %v", key, err)
+		return
+	}
+
+	if throttle.Failures == policy.lockoutAfter {
+		log.Printf("Locked logins for %s for %s after %d failed attempts", key, delay, throttle.Failures)
+		event.Type = model.AuditLoginLocked
+		event.Detail = fmt.Sprintf("%d failed attempts, locked for %s", throttle.Failures, delay)
+		if err := s.musicDAO.CreateAuditEvent(&event); err != nil {
+			log.Printf("Failed to record audit event for %s: %v", key, err)
+		}
+	}
+}
+
+// UnlockUser lifts a lock on logins for an existing user and forgets their failed attempts.
+func (s *loginService) UnlockUser(username string, adminID uint) error {
+	user, err := s.musicDAO.GetUserByUsername(username)
+	if err != nil {
+		return err
+	}
+	if err := s.musicDAO.DeleteLoginThrottle(usernameThrottleKey(user.Username)); err != nil {
+		return err
+	}
+
+	log.Printf("User %d unlocked logins for %s", adminID, user.Username)
+	return s.musicDAO.CreateAuditEvent(&model.AuditEvent{Type: model.AuditLoginUnlocked, ActorID: adminID, Username: user.Username})
+}
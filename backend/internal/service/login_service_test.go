@@ -0,0 +1,115 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/dao/mocks"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+func newTestLoginService(mockDAO *mocks.MusicDAO, now time.Time) *loginService {
+	s := NewLoginService(mockDAO, NewUserService(mockDAO)).(*loginService)
+	s.now = func() time.Time { return now }
+	return s
+}
+
+func TestLoginThrottleDelay(t *testing.T) {
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{0, 0},
+		{3, 0},
+		{4, time.Second},
+		{5, 2 * time.Second},
+		{9, 32 * time.Second},
+		{10, 15 * time.Minute},
+		{1000, 15 * time.Minute},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, usernameThrottle.delay(tt.failures), "failures=%d", tt.failures)
+	}
+	// The IP policy is capped at the lockout duration well before its lockout threshold.
+	assert.Equal(t, 15*time.Minute, ipThrottle.delay(49))
+}
+
+func TestLoginSuccessResetsUsername(t *testing.T) {
+	mockDAO := new(mocks.MusicDAO)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	s := newTestLoginService(mockDAO, now)
+	hash, _ := bcrypt.GenerateFromPassword([]byte("secret123"), bcrypt.MinCost)
+
+	mockDAO.On("GetLoginThrottles", []string{"username:alice", "ip:10.0.0.1"}).Return([]model.LoginThrottle{{Key: "username:alice", Failures: 2}}, nil)
+	mockDAO.On("GetUserByUsername", "Alice").Return(&model.User{Model: gorm.Model{ID: 7}, Username: "alice", Password: string(hash)}, nil)
+	mockDAO.On("DeleteLoginThrottle", "username:alice").Return(nil).Once()
+
+	userID, err := s.Login("Alice", "secret123", "10.0.0.1")
+	assert.NoError(t, err)
+	assert.Equal(t, uint(7), userID)
+	mockDAO.AssertExpectations(t)
+	mockDAO.AssertNotCalled(t, "DeleteLoginThrottle", "ip:10.0.0.1")
+}
+
+func TestLoginRefusedWhileLocked(t *testing.T) {
+	mockDAO := new(mocks.MusicDAO)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	s := newTestLoginService(mockDAO, now)
+	usernameUntil, ipUntil := now.Add(10*time.Second), now.Add(time.Minute)
+
+	mockDAO.On("GetLoginThrottles", []string{"username:alice", "ip:10.0.0.1"}).Return([]model.LoginThrottle{
+		{Key: "username:alice", Failures: 5, LockedUntil: &usernameUntil},
+		{Key: "ip:10.0.0.1", Failures: 20, LockedUntil: &ipUntil},
+	}, nil)
+
+	_, err := s.Login("alice", "secret123", "10.0.0.1")
+	var throttled *LoginThrottledError
+	if assert.ErrorAs(t, err, &throttled) {
+		assert.Equal(t, time.Minute, throttled.RetryAfter)
+	}
+	// The password is not checked while locked.
+	mockDAO.AssertNotCalled(t, "GetUserByUsername", mock.Anything)
+}
+
+func TestLoginFailureLocksAtThreshold(t *testing.T) {
+	mockDAO := new(mocks.MusicDAO)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	s := newTestLoginService(mockDAO, now)
+	expired := now.Add(-time.Second)
+
+	mockDAO.On("GetLoginThrottles", []string{"username:alice", "ip:10.0.0.1"}).Return([]model.LoginThrottle{
+		{Key: "username:alice", Failures: 9, LockedUntil: &expired},
+	}, nil)
+	mockDAO.On("GetUserByUsername", "alice").Return(nil, ErrUserNotFound)
+	mockDAO.On("RecordLoginFailure", "username:alice", now, now.Add(-time.Hour)).Return(&model.LoginThrottle{Key: "username:alice", Failures: 10}, nil).Once()
+	mockDAO.On("RecordLoginFailure", "ip:10.0.0.1", now, now.Add(-time.Hour)).Return(&model.LoginThrottle{Key: "ip:10.0.0.1", Failures: 1}, nil).Once()
+	mockDAO.On("LockLogin", "username:alice", now.Add(15*time.Minute)).Return(nil).Once()
+	mockDAO.On("CreateAuditEvent", mock.MatchedBy(func(e *model.AuditEvent) bool {
+		return e.Type == model.AuditLoginLocked && e.Username == "alice" && e.IP == "10.0.0.1"
+	})).Return(nil).Once()
+
+	_, err := s.Login("alice", "wrong", "10.0.0.1")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+	mockDAO.AssertExpectations(t)
+	mockDAO.AssertNotCalled(t, "LockLogin", "ip:10.0.0.1", mock.Anything)
+}
+
+func TestUnlockUser(t *testing.T) {
+	mockDAO := new(mocks.MusicDAO)
+	s := newTestLoginService(mockDAO, time.Now())
+
+	mockDAO.On("GetUserByUsername", "Alice").Return(&model.User{Model: gorm.Model{ID: 7}, Username: "alice"}, nil)
+	mockDAO.On("DeleteLoginThrottle", "username:alice").Return(nil).Once()
+	mockDAO.On("CreateAuditEvent", mock.MatchedBy(func(e *model.AuditEvent) bool {
+		return e.Type == model.AuditLoginUnlocked && e.ActorID == 1 && e.Username == "alice"
+	})).Return(nil).Once()
+	assert.NoError(t, s.UnlockUser("Alice", 1))
+
+	mockDAO.On("GetUserByUsername", "bob").Return(nil, ErrUserNotFound)
+	assert.ErrorIs(t, s.UnlockUser("bob", 1), ErrUserNotFound)
+	mockDAO.AssertExpectations(t)
+}
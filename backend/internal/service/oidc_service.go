@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/dao"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/model"
+	"golang.org/x/oauth2"
+)
+
+var (
+	// ErrOIDCNotConfigured is returned when no OpenID Connect provider is configured.
+	ErrOIDCNotConfigured = errors.New("OIDC login is not configured")
+
+	// ErrOIDCLoginFailed is returned when the provider did not confirm who signed in.
+	ErrOIDCLoginFailed = errors.New("OIDC login failed")
+
+	// ErrEmailNotVerified is returned when the provider has not verified the account's email,
+	// which is needed to link or create a user.
+	ErrEmailNotVerified = errors.New("the identity provider has not verified the email")
+
+	// ErrAccountNotLinked is returned when a local user has the provider account's email but has
+	// not verified it, so the provider account cannot be linked to them automatically.
+	ErrAccountNotLinked = errors.New("an account with this email exists but has not verified it")
+
+	// ErrIdentityNotFound is returned when no user is linked to an identity provider account.
+	ErrIdentityNotFound = dao.ErrIdentityNotFound
+)
+
+// OIDCConfig describes the OpenID Connect provider users may sign in with.
+type OIDCConfig struct {
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string // The API's callback URL, as registered with the provider.
+}
+
+// OIDCLoginService signs users in with an OpenID Connect provider using the authorization-code flow.
+type OIDCLoginService interface {
+	// AuthCodeURL returns the provider's sign-in page for a login with the given state, nonce
+	// and PKCE verifier, which the caller keeps until the provider redirects back.
+	AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error)
+	// Login exchanges the code the provider redirected back with for the signed-in user. A user
+	// not yet linked to the provider account is linked by verified email, or created.
+	Login(ctx context.Context, code, nonce, verifier string) (*model.User, error)
+}
+
+type oidcLoginService struct {
+	musicDAO dao.MusicDAO
+	config   OIDCConfig
+
+	mu       sync.Mutex
+	provider *oidc.Provider
+}
+
+func NewOIDCLoginService(musicDAO dao.MusicDAO, config OIDCConfig) OIDCLoginService {
+	return &oidcLoginService{musicDAO: musicDAO, config: config}
+}
+
+// discover fetches the provider's configuration the first time it is needed, so that the API
+// starts even when the provider is unreachable.
+func (s *oidcLoginService) discover(ctx context.Context) (*oidc.Provider, *oauth2.Config, error) {
+	if s.config.IssuerURL == "" {
+		return nil, nil, ErrOIDCNotConfigured
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.provider == nil {
+		provider, err := oidc.NewProvider(ctx, s.config.IssuerURL)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to discover OIDC provider: %w", err)
+		}
+		s.provider = provider
+	}
+	return s.provider, &oauth2.Config{
+		ClientID:     s.config.ClientID,
+		ClientSecret: s.config.ClientSecret,
+		RedirectURL:  s.config.RedirectURL,
+		Endpoint:     s.provider.Endpoint(),
+		Scopes:       []string{oidc.ScopeOpenID, "email", "profile"},
+	}, nil
+}
+
+func (s *oidcLoginService) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	_, oauthConfig, err := s.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	return oauthConfig.AuthCodeURL(state, oidc.Nonce(nonce), oauth2.S256ChallengeOption(verifier)), nil
+}
+
+// oidcClaims are the claims of an ID token that identify the user.
+type oidcClaims struct {
+	Email             string `json:"email"`
+	EmailVerified     bool   `json:"email_verified"`
+	PreferredUsername string `json:"preferred_username"`
+	Name              string `json:"name"`
+}
+
+func (s *oidcLoginService) Login(ctx context.Context, code, nonce, verifier string) (*model.User, error) {
+	provider, oauthConfig, err := s.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := oauthConfig.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrOIDCLoginFailed, err)
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, fmt.Errorf("%w: no ID token in the token response", ErrOIDCLoginFailed)
+	}
+	idToken, err := provider.Verifier(&oidc.Config{ClientID: s.config.ClientID}).Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrOIDCLoginFailed, err)
+	}
+	if idToken.Nonce != nonce {
+		return nil, fmt.Errorf("%w: ID token nonce does not match", ErrOIDCLoginFailed)
+	}
+	var claims oidcClaims
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrOIDCLoginFailed, err)
+	}
+
+	return s.userForIdentity(idToken.Issuer, idToken.Subject, claims)
+}
+
+// userForIdentity returns the user linked to the provider account, linking one by verified email
+// or creating one if there is none. A user is only linked if they have verified the email too:
+// otherwise whoever registered the address first, perhaps to take over the account of its real
+// owner, would get the provider account.
+func (s *oidcLoginService) userForIdentity(issuer, subject string, claims oidcClaims) (*model.User, error) {
+	identity, err := s.musicDAO.GetUserIdentity(issuer, subject)
+	if err == nil {
+		return s.musicDAO.GetUserByID(identity.UserID)
+	}
+	if !errors.Is(err, ErrIdentityNotFound) {
+		return nil, err
+	}
+
+	email := strings.ToLower(strings.TrimSpace(claims.Email))
+	if email == "" || !claims.EmailVerified {
+		return nil, ErrEmailNotVerified
+	}
+	user, err := s.musicDAO.GetUserByEmail(email)
+	if errors.Is(err, ErrUserNotFound) {
+		user, err = s.provisionUser(email, claims)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if user.EmailVerifiedAt == nil {
+		return nil, ErrAccountNotLinked
+	}
+
+	identity = &model.UserIdentity{UserID: user.ID, Issuer: issuer, Subject: subject, Email: email}
+	if err := s.musicDAO.CreateUserIdentity(identity); err != nil {
+		return nil, err
+	}
+	log.Printf("Linked user %d to %s account %s", user.ID, issuer, subject)
+	return user, nil
+}
+
+// maxProvisionAttempts bounds the usernames tried when creating a user for a provider account.
+const maxProvisionAttempts = 5
+
+// provisionUser creates a user for a provider account. The user has no password, so they can only
+// sign in through the provider.
+func (s *oidcLoginService) provisionUser(email string, claims oidcClaims) (*model.User, error) {
+	base := provisionedUsername(claims.PreferredUsername, email)
+	for attempt := 0; attempt < maxProvisionAttempts; attempt++ {
+		username := base
+		if attempt > 0 {
+			suffix, err := randomUsernameSuffix()
+			if err != nil {
+				return nil, err
+			}
+			username = base + "-" + suffix
+		}
+		// The provider has verified the email, so the user need not verify it again.
+		verifiedAt := time.Now()
+		user := &model.User{Username: username, Email: email, EmailVerifiedAt: &verifiedAt, FullName: claims.Name}
+		err := s.musicDAO.CreateUser(user)
+		if errors.Is(err, ErrUsernameTaken) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("Created user %q for OIDC login", username)
+		return user, nil
+	}
+	return nil, fmt.Errorf("failed to find a free username for %s: %w", email, ErrUsernameTaken)
+}
+
+var usernameDisallowed = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+
+// provisionedUsername derives a username that passes registration's rules from the provider's
+// preferred username, or else from the local part of the email.
+func provisionedUsername(preferred, email string) string {
+	name := usernameDisallowed.ReplaceAllString(preferred, "")
+	if len(name) < 3 {
+		local, _, _ := strings.Cut(email, "@")
+		name = usernameDisallowed.ReplaceAllString(local, "")
+	}
+	if len(name) < 3 {
+		name = "user"
+	}
+	// Leave room for the suffix added when the name is taken.
+	if len(name) > 24 {
+		name = name[:24]
+	}
+	return name
+}
+
+// randomUsernameSuffix returns a short random string that makes a taken username unique.
+func randomUsernameSuffix() (string, error) {
+	b := make([]byte, 3)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
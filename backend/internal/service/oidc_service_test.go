@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/dao/mocks"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/model"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/oidctest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/oauth2"
+	"gorm.io/gorm"
+)
+
+const testRedirectURL = "http://localhost:8081/api/v1/login/oidc/callback"
+
+func newTestOIDCLogin(t *testing.T, identity oidctest.Identity) (*oidctest.Provider, *mocks.MusicDAO, OIDCLoginService) {
+	provider := oidctest.NewProvider("music-api", "client-secret", identity)
+	t.Cleanup(provider.Close)
+	mockDAO := new(mocks.MusicDAO)
+	s := NewOIDCLoginService(mockDAO, OIDCConfig{
+		IssuerURL:    provider.URL,
+		ClientID:     provider.ClientID,
+		ClientSecret: provider.ClientSecret,
+		RedirectURL:  testRedirectURL,
+	})
+	return provider, mockDAO, s
+}
+
+// signIn follows the flow up to the provider's redirect back and returns the authorization code.
+func signIn(t *testing.T, s OIDCLoginService, nonce, verifier string) string {
+	t.Helper()
+	authURL, err := s.AuthCodeURL(context.Background(), "state-1", nonce, verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := client.Get(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil || resp.StatusCode != http.StatusFound {
+		t.Fatalf("unexpected authorization response %d %q", resp.StatusCode, resp.Header.Get("Location"))
+	}
+	assert.Equal(t, "state-1", location.Query().Get("state"))
+	return location.Query().Get("code")
+}
+
+func TestOIDCLoginProvisionsUser(t *testing.T) {
+	provider, mockDAO, s := newTestOIDCLogin(t, oidctest.Identity{
+		Subject: "abc123", Email: "New.User@Example.com", EmailVerified: true, PreferredUsername: "new user!", Name: "New User",
+	})
+	verifier := oauth2.GenerateVerifier()
+	code := signIn(t, s, "nonce-1", verifier)
+
+	mockDAO.On("GetUserIdentity", provider.URL, "abc123").Return(nil, ErrIdentityNotFound)
+	mockDAO.On("GetUserByEmail", "new.user@example.com").Return(nil, ErrUserNotFound)
+	mockDAO.On("CreateUser", mock.MatchedBy(func(u *model.User) bool {
+		return u.Username == "newuser" && u.Email == "new.user@example.com" && u.FullName == "New User" && u.Password == ""
+	})).Run(func(args mock.Arguments) {
+		args.Get(0).(*model.User).ID = 9
+	}).Return(nil).Once()
+	mockDAO.On("CreateUserIdentity", &model.UserIdentity{UserID: 9, Issuer: provider.URL, Subject: "abc123", Email: "new.user@example.com"}).Return(nil).Once()
+
+	user, err := s.Login(context.Background(), code, "nonce-1", verifier)
+	if assert.NoError(t, err) {
+		assert.Equal(t, uint(9), user.ID)
+	}
+	mockDAO.AssertExpectations(t)
+}
+
+func TestOIDCLoginLinksUserByVerifiedEmail(t *testing.T) {
+	provider, mockDAO, s := newTestOIDCLogin(t, oidctest.Identity{Subject: "abc123", Email: "alice@example.com", EmailVerified: true})
+	verifier := oauth2.GenerateVerifier()
+	code := signIn(t, s, "nonce-1", verifier)
+
+	verifiedAt := time.Now()
+	alice := &model.User{Model: gorm.Model{ID: 3}, Username: "alice", Email: "alice@example.com", EmailVerifiedAt: &verifiedAt}
+	mockDAO.On("GetUserIdentity", provider.URL, "abc123").Return(nil, ErrIdentityNotFound).Once()
+	mockDAO.On("GetUserByEmail", "alice@example.com").Return(alice, nil).Once()
+	mockDAO.On("CreateUserIdentity", mock.MatchedBy(func(i *model.UserIdentity) bool { return i.UserID == 3 })).Return(nil).Once()
+
+	user, err := s.Login(context.Background(), code, "nonce-1", verifier)
+	assert.NoError(t, err)
+	assert.Equal(t, alice, user)
+
+	// Later logins find the user through the link, even after the email changed at the provider.
+	provider.SetIdentity(oidctest.Identity{Subject: "abc123", Email: "alice@elsewhere.com"})
+	code = signIn(t, s, "nonce-2", verifier)
+	mockDAO.On("GetUserIdentity", provider.URL, "abc123").Return(&model.UserIdentity{UserID: 3}, nil).Once()
+	mockDAO.On("GetUserByID", uint(3)).Return(alice, nil).Once()
+
+	user, err = s.Login(context.Background(), code, "nonce-2", verifier)
+	assert.NoError(t, err)
+	assert.Equal(t, alice, user)
+	mockDAO.AssertExpectations(t)
+	mockDAO.AssertNotCalled(t, "CreateUser", mock.Anything)
+}
+
+func TestOIDCLoginDoesNotLinkUnverifiedLocalAccount(t *testing.T) {
+	provider, mockDAO, s := newTestOIDCLogin(t, oidctest.Identity{Subject: "abc123", Email: "alice@example.com", EmailVerified: true})
+	verifier := oauth2.GenerateVerifier()
+	code := signIn(t, s, "nonce-1", verifier)
+
+	// Someone registered the address without proving they own it.
+	squatter := &model.User{Model: gorm.Model{ID: 4}, Username: "mallory", Email: "alice@example.com"}
+	mockDAO.On("GetUserIdentity", provider.URL, "abc123").Return(nil, ErrIdentityNotFound)
+	mockDAO.On("GetUserByEmail", "alice@example.com").Return(squatter, nil)
+
+	_, err := s.Login(context.Background(), code, "nonce-1", verifier)
+	assert.ErrorIs(t, err, ErrAccountNotLinked)
+	mockDAO.AssertNotCalled(t, "CreateUserIdentity", mock.Anything)
+}
+
+func TestOIDCLoginRequiresVerifiedEmail(t *testing.T) {
+	provider, mockDAO, s := newTestOIDCLogin(t, oidctest.Identity{Subject: "abc123", Email: "alice@example.com"})
+	verifier := oauth2.GenerateVerifier()
+	code := signIn(t, s, "nonce-1", verifier)
+	mockDAO.On("GetUserIdentity", provider.URL, "abc123").Return(nil, ErrIdentityNotFound)
+
+	_, err := s.Login(context.Background(), code, "nonce-1", verifier)
+	assert.ErrorIs(t, err, ErrEmailNotVerified)
+	mockDAO.AssertNotCalled(t, "GetUserByEmail", mock.Anything)
+}
+
+func TestOIDCLoginRejectsForgedResponses(t *testing.T) {
+	_, mockDAO, s := newTestOIDCLogin(t, oidctest.Identity{Subject: "abc123", Email: "alice@example.com", EmailVerified: true})
+
+	// A code exchanged without the verifier it was requested with.
+	code := signIn(t, s, "nonce-1", oauth2.GenerateVerifier())
+	_, err := s.Login(context.Background(), code, "nonce-1", oauth2.GenerateVerifier())
+	assert.ErrorIs(t, err, ErrOIDCLoginFailed)
+
+	// An ID token issued for another login attempt.
+	verifier := oauth2.GenerateVerifier()
+	code = signIn(t, s, "nonce-1", verifier)
+	_, err = s.Login(context.Background(), code, "nonce-2", verifier)
+	assert.ErrorIs(t, err, ErrOIDCLoginFailed)
+
+	// A code used twice.
+	_, err = s.Login(context.Background(), code, "nonce-1", verifier)
+	assert.ErrorIs(t, err, ErrOIDCLoginFailed)
+
+	mockDAO.AssertNotCalled(t, "GetUserIdentity", mock.Anything, mock.Anything)
+}
+
+func TestOIDCLoginNotConfigured(t *testing.T) {
+	s := NewOIDCLoginService(new(mocks.MusicDAO), OIDCConfig{})
+	_, err := s.AuthCodeURL(context.Background(), "state", "nonce", "verifier")
+	assert.ErrorIs(t, err, ErrOIDCNotConfigured)
+	_, err = s.Login(context.Background(), "code", "nonce", "verifier")
+	assert.ErrorIs(t, err, ErrOIDCNotConfigured)
+}
+
+func TestProvisionedUsername(t *testing.T) {
+	assert.Equal(t, "jdoe", provisionedUsername("jdoe", "john@example.com"))
+	assert.Equal(t, "john.doe", provisionedUsername("", "john.doe@example.com"))
+	assert.Equal(t, "john", provisionedUsername("j!", "jo+hn@example.com"))
+	assert.Equal(t, "user", provisionedUsername("", "x@example.com"))
+	assert.Len(t, provisionedUsername("a-very-long-preferred-username-indeed", ""), 24)
+}
+
+func TestOIDCLoginProvisionsFreeUsername(t *testing.T) {
+	provider, mockDAO, s := newTestOIDCLogin(t, oidctest.Identity{Subject: "abc123", Email: "bob@example.com", EmailVerified: true})
+	verifier := oauth2.GenerateVerifier()
+	code := signIn(t, s, "nonce-1", verifier)
+
+	mockDAO.On("GetUserIdentity", provider.URL, "abc123").Return(nil, ErrIdentityNotFound)
+	mockDAO.On("GetUserByEmail", "bob@example.com").Return(nil, ErrUserNotFound)
+	mockDAO.On("CreateUser", mock.MatchedBy(func(u *model.User) bool { return u.Username == "bob" })).Return(ErrUsernameTaken).Once()
+	mockDAO.On("CreateUser", mock.MatchedBy(func(u *model.User) bool { return len(u.Username) == len("bob-123abc") })).Return(nil).Once()
+	mockDAO.On("CreateUserIdentity", mock.AnythingOfType("*model.UserIdentity")).Return(nil).Once()
+
+	user, err := s.Login(context.Background(), code, "nonce-1", verifier)
+	if assert.NoError(t, err) {
+		assert.Regexp(t, `^bob-[0-9a-f]{6}$`, user.Username)
+	}
+	mockDAO.AssertExpectations(t)
+}
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/dao"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/model"
+)
+
+var (
+	ErrEmptyPlayBatch    = errors.New("at least one play is required")
+	ErrTooManyPlays      = fmt.Errorf("at most %d plays can be recorded per request", maxPlaysPerBatch)
+	ErrPlayEventNotFound = dao.ErrPlayEventNotFound
+)
+
+const (
+	maxPlaysPerBatch = 500
+	// maxPlayClockSkew is how far in the future a play may be timestamped, to allow for client clocks.
+	maxPlayClockSkew = 5 * time.Minute
+	// maxPlayAge is how old a play may be when it is reported, to allow for offline clients.
+	maxPlayAge = 30 * 24 * time.Hour
+
+	defaultHistoryLimit = 50
+	maxHistoryLimit     = 200
+)
+
+// Play is a play event as reported by a client.
+type Play struct {
+	SongID     uint      `json:"song_id"`
+	PlayedAt   time.Time `json:"played_at"`
+	DurationMS int       `json:"duration_ms"`
+	PlaylistID uint      `json:"playlist_id,omitempty"`
+}
+
+// RejectedPlay explains why a play of a batch was not recorded.
+type RejectedPlay struct {
+	Index  int    `json:"index"`
+	Reason string `json:"reason"`
+}
+
+// PlayBatchResult reports how many plays of a batch were recorded and which were rejected.
+type PlayBatchResult struct {
+	Accepted int            `json:"accepted"`
+	Rejected []RejectedPlay `json:"rejected"`
+}
+
+// PlayHistory is a page of a user's listening history, most recent first. NextBefore is passed as
+// "before" to fetch the next page.
+type PlayHistory struct {
+	Plays      []model.PlayEvent `json:"plays"`
+	NextBefore uint              `json:"next_before,omitempty"`
+}
+
+// PlayService records what users listen to.
+type PlayService interface {
+	RecordPlays(userID uint, plays []Play) (*PlayBatchResult, error)
+	GetHistory(userID, before uint, limit int) (*PlayHistory, error)
+	GetSongPlayCount(songID string) (*model.SongPlayCount, error)
+}
+
+type playService struct {
+	musicDAO        dao.MusicDAO
+	playlistService PlaylistService
+}
+
+func NewPlayService(musicDAO dao.MusicDAO, playlistService PlaylistService) PlayService {
+	return &playService{musicDAO: musicDAO, playlistService: playlistService}
+}
+
+// RecordPlays stores a batch of plays. Invalid plays are rejected individually so that one bad
+// event does not make the client resend the whole batch.
+func (s *playService) RecordPlays(userID uint, plays []Play) (*PlayBatchResult, error) {
+	if len(plays) == 0 {
+		return nil, ErrEmptyPlayBatch
+	}
+	if len(plays) > maxPlaysPerBatch {
+		return nil, ErrTooManyPlays
+	}
+
+	songIDs := make([]uint, 0, len(plays))
+	for _, play := range plays {
+		songIDs = append(songIDs, play.SongID)
+	}
+	existing, err := s.musicDAO.GetExistingSongIDs(songIDs)
+	if err != nil {
+		return nil, err
+	}
+	visible, err := s.visiblePlaylists(userID, plays)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	result := &PlayBatchResult{Rejected: []RejectedPlay{}}
+	events := make([]model.PlayEvent, 0, len(plays))
+	for i, play := range plays {
+		var reason string
+		switch {
+		case !existing[play.SongID]:
+			reason = "song not found"
+		case play.PlaylistID != 0 && !visible[play.PlaylistID]:
+			reason = "playlist not found"
+		case play.PlayedAt.IsZero():
+			reason = "played_at is required"
+		case play.PlayedAt.After(now.Add(maxPlayClockSkew)):
+			reason = "played_at is in the future"
+		case play.PlayedAt.Before(now.Add(-maxPlayAge)):
+			reason = "played_at is too old"
+		case play.DurationMS < 0:
+			reason = "duration_ms must not be negative"
+		}
+		if reason != "" {
+			result.Rejected = append(result.Rejected, RejectedPlay{Index: i, Reason: reason})
+			continue
+		}
+		events = append(events, model.PlayEvent{
+			UserID:     userID,
+			SongID:     play.SongID,
+			PlaylistID: play.PlaylistID,
+			PlayedAt:   play.PlayedAt.UTC(),
+			DurationMS: play.DurationMS,
+		})
+	}
+
+	if err := s.musicDAO.CreatePlayEvents(events); err != nil {
+		return nil, err
+	}
+	result.Accepted = len(events)
+	return result, nil
+}
+
+// visiblePlaylists reports which of the playlists the plays came from the user may see, so that
+// plays cannot be attributed to, and reveal the existence of, other users' private playlists.
+func (s *playService) visiblePlaylists(userID uint, plays []Play) (map[uint]bool, error) {
+	visible := make(map[uint]bool)
+	checked := make(map[uint]bool)
+	for _, play := range plays {
+		if play.PlaylistID == 0 || checked[play.PlaylistID] {
+			continue
+		}
+		checked[play.PlaylistID] = true
+		err := s.playlistService.AuthorizePlaylist(strconv.FormatUint(uint64(play.PlaylistID), 10), userID, PermissionView)
+		switch {
+		case err == nil:
+			visible[play.PlaylistID] = true
+		case !errors.Is(err, ErrPlaylistNotFound) && !errors.Is(err, ErrPlaylistForbidden):
+			return nil, err
+		}
+	}
+	return visible, nil
+}
+
+// GetHistory returns a page of the user's listening history.
+func (s *playService) GetHistory(userID, before uint, limit int) (*PlayHistory, error) {
+	if limit <= 0 {
+		limit = defaultHistoryLimit
+	}
+	if limit > maxHistoryLimit {
+		limit = maxHistoryLimit
+	}
+
+	events, err := s.musicDAO.GetPlayHistory(userID, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	history := &PlayHistory{Plays: events}
+	if history.Plays == nil {
+		history.Plays = []model.PlayEvent{}
+	}
+	if len(events) == limit {
+		history.NextBefore = events[len(events)-1].ID
+	}
+	return history, nil
+}
+
+// GetSongPlayCount returns how many times a song was played by all users.
+func (s *playService) GetSongPlayCount(songID string) (*model.SongPlayCount, error) {
+	id, err := strconv.ParseUint(songID, 10, 64)
+	if err != nil {
+		return nil, ErrSongNotFound
+	}
+	if _, err := s.musicDAO.GetSongByID(songID); err != nil {
+		return nil, err
+	}
+	return s.musicDAO.GetSongPlayCount(uint(id))
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/dao/mocks"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func TestRecordPlays(t *testing.T) {
+	mockDAO := new(mocks.MusicDAO)
+	ps := NewPlayService(mockDAO, NewPlaylistService(mockDAO))
+	now := time.Now()
+
+	plays := []Play{
+		{SongID: 1, PlayedAt: now.Add(-time.Minute), DurationMS: 180000, PlaylistID: 4},
+		{SongID: 9, PlayedAt: now},
+		{SongID: 1, PlayedAt: now.Add(time.Hour)},
+		{SongID: 2},
+		{SongID: 2, PlayedAt: now, DurationMS: 30000},
+		{SongID: 2, PlayedAt: now, PlaylistID: 5},
+		{SongID: 1, PlayedAt: now, PlaylistID: 4},
+	}
+	mockDAO.On("GetExistingSongIDs", []uint{1, 9, 1, 2, 2, 2, 1}).Return(map[uint]bool{1: true, 2: true}, nil)
+	mockDAO.On("GetPlaylistInfo", "4").Return(&model.Playlist{Model: gorm.Model{ID: 4}, UserID: 3, Visibility: model.VisibilityPublic}, nil).Once()
+	mockDAO.On("GetPlaylistCollaborator", uint(4), uint(7)).Return(nil, ErrCollaboratorNotFound).Once()
+	// Another user's private playlist.
+	mockDAO.On("GetPlaylistInfo", "5").Return(&model.Playlist{Model: gorm.Model{ID: 5}, UserID: 3, Visibility: model.VisibilityPrivate}, nil).Once()
+	mockDAO.On("GetPlaylistCollaborator", uint(5), uint(7)).Return(nil, ErrCollaboratorNotFound).Once()
+	mockDAO.On("CreatePlayEvents", mock.MatchedBy(func(events []model.PlayEvent) bool {
+		return len(events) == 3 && events[0].UserID == 7 && events[0].SongID == 1 && events[0].PlaylistID == 4 &&
+			events[1].SongID == 2 && events[1].DurationMS == 30000 && events[2].PlaylistID == 4
+	})).Return(nil).Once()
+
+	result, err := ps.RecordPlays(7, plays)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, result.Accepted)
+	assert.Equal(t, []RejectedPlay{
+		{Index: 1, Reason: "song not found"},
+		{Index: 2, Reason: "played_at is in the future"},
+		{Index: 3, Reason: "played_at is required"},
+		{Index: 5, Reason: "playlist not found"},
+	}, result.Rejected)
+
+	_, err = ps.RecordPlays(7, nil)
+	assert.Equal(t, ErrEmptyPlayBatch, err)
+
+	_, err = ps.RecordPlays(7, make([]Play, maxPlaysPerBatch+1))
+	assert.Equal(t, ErrTooManyPlays, err)
+	mockDAO.AssertExpectations(t)
+}
+
+func TestGetHistory(t *testing.T) {
+	mockDAO := new(mocks.MusicDAO)
+	ps := NewPlayService(mockDAO, NewPlaylistService(mockDAO))
+
+	page := []model.PlayEvent{{ID: 12}, {ID: 10}}
+	mockDAO.On("GetPlayHistory", uint(7), uint(0), 2).Return(page, nil)
+	mockDAO.On("GetPlayHistory", uint(7), uint(10), defaultHistoryLimit).Return(nil, nil)
+
+	history, err := ps.GetHistory(7, 0, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, page, history.Plays)
+	assert.Equal(t, uint(10), history.NextBefore)
+
+	history, err = ps.GetHistory(7, 10, 0)
+	assert.NoError(t, err)
+	assert.Empty(t, history.Plays)
+	assert.Zero(t, history.NextBefore)
+}
+
+func TestGetSongPlayCount(t *testing.T) {
+	mockDAO := new(mocks.MusicDAO)
+	ps := NewPlayService(mockDAO, NewPlaylistService(mockDAO))
+
+	mockDAO.On("GetSongByID", "3").Return(&model.Song{Name: "Song"}, nil)
+	mockDAO.On("GetSongByID", "4").Return(nil, ErrSongNotFound)
+	mockDAO.On("GetSongPlayCount", uint(3)).Return(&model.SongPlayCount{SongID: 3, PlayCount: 42}, nil)
+
+	count, err := ps.GetSongPlayCount("3")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), count.PlayCount)
+
+	_, err = ps.GetSongPlayCount("4")
+	assert.Equal(t, ErrSongNotFound, err)
+
+	_, err = ps.GetSongPlayCount("abc")
+	assert.Equal(t, ErrSongNotFound, err)
+}
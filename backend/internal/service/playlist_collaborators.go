@@ -0,0 +1,177 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/dao"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/model"
+)
+
+var (
+	ErrPlaylistForbidden    = errors.New("not allowed to perform this action on the playlist")
+	ErrCollaboratorNotFound = dao.ErrCollaboratorNotFound
+	ErrInviteeNotFound      = dao.ErrUserNotFound
+	ErrInvalidRole          = errors.New("role must be editor or viewer")
+	ErrCannotInviteOwner    = errors.New("the playlist owner cannot be invited")
+	ErrNoPendingInvite      = errors.New("no pending invite for this playlist")
+)
+
+// PlaylistPermission is the level of access an action needs on a playlist.
+type PlaylistPermission int
+
+const (
+	// PermissionView allows reading the playlist and its collaborators. Everyone has it on public
+	// playlists; on private and unlisted ones only the owner and accepted collaborators do.
+	PermissionView PlaylistPermission = iota
+	// PermissionEdit allows changing the playlist's songs. Owners and accepted editors have it.
+	PermissionEdit
+	// PermissionEditSongs is PermissionEdit for changes made to the songs directly, which smart
+	// playlists do not allow.
+	PermissionEditSongs
+	// PermissionManage allows inviting and removing collaborators. Only the owner has it.
+	PermissionManage
+)
+
+// AuthorizePlaylist returns ErrPlaylistForbidden unless the user has the permission on the playlist.
+func (s *playlistService) AuthorizePlaylist(playlistID string, userID uint, permission PlaylistPermission) error {
+	playlist, err := s.musicDAO.GetPlaylistInfo(playlistID)
+	if err != nil {
+		return err
+	}
+	return s.authorize(playlist, userID, permission)
+}
+
+func (s *playlistService) authorize(playlist *model.Playlist, userID uint, permission PlaylistPermission) error {
+	if err := s.authorizeUser(playlist, userID, permission); err != nil {
+		return err
+	}
+	if permission == PermissionEditSongs && playlist.SmartRules != nil {
+		return ErrSmartPlaylistReadOnly
+	}
+	return nil
+}
+
+func (s *playlistService) authorizeUser(playlist *model.Playlist, userID uint, permission PlaylistPermission) error {
+	if playlist.UserID == userID {
+		return nil
+	}
+
+	collaborator, err := s.musicDAO.GetPlaylistCollaborator(playlist.ID, userID)
+	if err != nil && !errors.Is(err, dao.ErrCollaboratorNotFound) {
+		return err
+	}
+	accepted := err == nil && collaborator.Status == model.InviteAccepted
+
+	// Playlists the user cannot see are reported as missing rather than forbidden.
+	if !accepted && playlist.Visibility != model.VisibilityPublic {
+		return ErrPlaylistNotFound
+	}
+	if permission == PermissionManage {
+		return ErrPlaylistForbidden
+	}
+	if (permission == PermissionEdit || permission == PermissionEditSongs) && (!accepted || collaborator.Role != model.CollaboratorEditor) {
+		return ErrPlaylistForbidden
+	}
+	return nil
+}
+
+// InviteCollaborator invites a user to a playlist with the given role. Inviting an existing
+// collaborator changes their role; inviting a user who declined asks them again.
+func (s *playlistService) InviteCollaborator(playlistID string, inviterID uint, username, role string) (*model.PlaylistCollaborator, error) {
+	if role != model.CollaboratorEditor && role != model.CollaboratorViewer {
+		return nil, ErrInvalidRole
+	}
+	playlist, err := s.musicDAO.GetPlaylistInfo(playlistID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(playlist, inviterID, PermissionManage); err != nil {
+		return nil, err
+	}
+	invitee, err := s.musicDAO.GetUserByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+	if invitee.ID == playlist.UserID {
+		return nil, ErrCannotInviteOwner
+	}
+
+	collaborator, err := s.musicDAO.GetPlaylistCollaborator(playlist.ID, invitee.ID)
+	switch {
+	case errors.Is(err, dao.ErrCollaboratorNotFound):
+		collaborator = &model.PlaylistCollaborator{PlaylistID: playlist.ID, UserID: invitee.ID, Status: model.InvitePending}
+	case err != nil:
+		return nil, err
+	case collaborator.Status == model.InviteDeclined:
+		collaborator.Status = model.InvitePending
+		collaborator.RespondedAt = nil
+	}
+	collaborator.Role = role
+	collaborator.InvitedByID = inviterID
+
+	if err := s.musicDAO.SavePlaylistCollaborator(collaborator); err != nil {
+		return nil, err
+	}
+	collaborator.Username = invitee.Username
+	return collaborator, nil
+}
+
+// RespondToInvite accepts or declines the user's pending invite to a playlist.
+func (s *playlistService) RespondToInvite(playlistID string, userID uint, accept bool) (*model.PlaylistCollaborator, error) {
+	playlist, err := s.musicDAO.GetPlaylistInfo(playlistID)
+	if err != nil {
+		return nil, err
+	}
+	collaborator, err := s.musicDAO.GetPlaylistCollaborator(playlist.ID, userID)
+	if errors.Is(err, dao.ErrCollaboratorNotFound) {
+		return nil, ErrNoPendingInvite
+	}
+	if err != nil {
+		return nil, err
+	}
+	if collaborator.Status != model.InvitePending {
+		return nil, ErrNoPendingInvite
+	}
+
+	collaborator.Status = model.InviteDeclined
+	if accept {
+		collaborator.Status = model.InviteAccepted
+	}
+	respondedAt := time.Now()
+	collaborator.RespondedAt = &respondedAt
+
+	if err := s.musicDAO.SavePlaylistCollaborator(collaborator); err != nil {
+		return nil, err
+	}
+	return collaborator, nil
+}
+
+// GetPlaylistCollaborators lists everyone invited to a playlist, whether or not they have answered.
+func (s *playlistService) GetPlaylistCollaborators(playlistID string) ([]model.PlaylistCollaborator, error) {
+	playlist, err := s.musicDAO.GetPlaylistInfo(playlistID)
+	if err != nil {
+		return nil, err
+	}
+	return s.musicDAO.GetPlaylistCollaborators(playlist.ID)
+}
+
+// RemoveCollaborator removes a user from a playlist's collaborators. Owners can remove anyone;
+// collaborators can only remove themselves.
+func (s *playlistService) RemoveCollaborator(playlistID string, actorID, userID uint) error {
+	playlist, err := s.musicDAO.GetPlaylistInfo(playlistID)
+	if err != nil {
+		return err
+	}
+	if actorID != userID {
+		if err := s.authorize(playlist, actorID, PermissionManage); err != nil {
+			return err
+		}
+	}
+	return s.musicDAO.DeletePlaylistCollaborator(playlist.ID, userID)
+}
+
+// GetPendingInvites lists the invites the user has not answered yet.
+func (s *playlistService) GetPendingInvites(userID uint) ([]model.PlaylistCollaborator, error) {
+	return s.musicDAO.GetPendingInvites(userID)
+}
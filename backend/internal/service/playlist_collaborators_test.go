@@ -0,0 +1,119 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/dao/mocks"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func TestAuthorizePlaylist(t *testing.T) {
+	mockDAO := new(mocks.MusicDAO)
+	ps := NewPlaylistService(mockDAO)
+	playlist := &model.Playlist{Model: gorm.Model{ID: 1}, UserID: 10, Visibility: model.VisibilityPublic}
+
+	mockDAO.On("GetPlaylistInfo", "1").Return(playlist, nil)
+	mockDAO.On("GetPlaylistInfo", "2").Return(nil, ErrPlaylistNotFound)
+	mockDAO.On("GetPlaylistCollaborator", uint(1), uint(20)).
+		Return(&model.PlaylistCollaborator{Role: model.CollaboratorEditor, Status: model.InviteAccepted}, nil)
+	mockDAO.On("GetPlaylistCollaborator", uint(1), uint(21)).
+		Return(&model.PlaylistCollaborator{Role: model.CollaboratorViewer, Status: model.InviteAccepted}, nil)
+	mockDAO.On("GetPlaylistCollaborator", uint(1), uint(22)).
+		Return(&model.PlaylistCollaborator{Role: model.CollaboratorEditor, Status: model.InvitePending}, nil)
+	mockDAO.On("GetPlaylistCollaborator", uint(1), uint(30)).Return(nil, ErrCollaboratorNotFound)
+
+	assert.NoError(t, ps.AuthorizePlaylist("1", 10, PermissionManage))
+	assert.NoError(t, ps.AuthorizePlaylist("1", 20, PermissionEdit))
+	assert.Equal(t, ErrPlaylistForbidden, ps.AuthorizePlaylist("1", 20, PermissionManage))
+	assert.Equal(t, ErrPlaylistForbidden, ps.AuthorizePlaylist("1", 21, PermissionEdit))
+	assert.Equal(t, ErrPlaylistForbidden, ps.AuthorizePlaylist("1", 22, PermissionEdit))
+	assert.Equal(t, ErrPlaylistForbidden, ps.AuthorizePlaylist("1", 30, PermissionEdit))
+	assert.NoError(t, ps.AuthorizePlaylist("1", 30, PermissionView))
+	assert.Equal(t, ErrPlaylistNotFound, ps.AuthorizePlaylist("2", 10, PermissionView))
+
+	// Private playlists are hidden from everyone but the owner and accepted collaborators.
+	private := &model.Playlist{Model: gorm.Model{ID: 1}, UserID: 10, Visibility: model.VisibilityPrivate}
+	mockDAO.On("GetPlaylistInfo", "3").Return(private, nil)
+	assert.NoError(t, ps.AuthorizePlaylist("3", 21, PermissionView))
+	assert.Equal(t, ErrPlaylistNotFound, ps.AuthorizePlaylist("3", 22, PermissionView))
+	assert.Equal(t, ErrPlaylistNotFound, ps.AuthorizePlaylist("3", 30, PermissionEdit))
+	assert.Equal(t, ErrPlaylistNotFound, ps.AuthorizePlaylist("3", 30, PermissionManage))
+}
+
+func TestInviteCollaborator(t *testing.T) {
+	mockDAO := new(mocks.MusicDAO)
+	ps := NewPlaylistService(mockDAO)
+	playlist := &model.Playlist{Model: gorm.Model{ID: 1}, UserID: 10, Visibility: model.VisibilityPublic}
+
+	mockDAO.On("GetPlaylistInfo", "1").Return(playlist, nil)
+	mockDAO.On("GetUserByUsername", "bob").Return(&model.User{Model: gorm.Model{ID: 20}, Username: "bob"}, nil)
+	mockDAO.On("GetUserByUsername", "owner").Return(&model.User{Model: gorm.Model{ID: 10}, Username: "owner"}, nil)
+	mockDAO.On("GetPlaylistCollaborator", uint(1), uint(20)).
+		Return(&model.PlaylistCollaborator{ID: 5, PlaylistID: 1, UserID: 20, Role: model.CollaboratorViewer, Status: model.InviteDeclined}, nil)
+	mockDAO.On("SavePlaylistCollaborator", mock.AnythingOfType("*model.PlaylistCollaborator")).Return(nil)
+
+	// Inviting a user who declined asks them again, with the new role.
+	collaborator, err := ps.InviteCollaborator("1", 10, "bob", model.CollaboratorEditor)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(5), collaborator.ID)
+	assert.Equal(t, model.InvitePending, collaborator.Status)
+	assert.Equal(t, model.CollaboratorEditor, collaborator.Role)
+	assert.Equal(t, "bob", collaborator.Username)
+
+	_, err = ps.InviteCollaborator("1", 10, "owner", model.CollaboratorEditor)
+	assert.Equal(t, ErrCannotInviteOwner, err)
+
+	_, err = ps.InviteCollaborator("1", 10, "bob", "admin")
+	assert.Equal(t, ErrInvalidRole, err)
+
+	mockDAO.On("GetPlaylistCollaborator", uint(1), uint(30)).Return(nil, ErrCollaboratorNotFound)
+	_, err = ps.InviteCollaborator("1", 30, "bob", model.CollaboratorViewer)
+	assert.Equal(t, ErrPlaylistForbidden, err)
+	mockDAO.AssertNumberOfCalls(t, "SavePlaylistCollaborator", 1)
+}
+
+func TestRespondToInvite(t *testing.T) {
+	mockDAO := new(mocks.MusicDAO)
+	ps := NewPlaylistService(mockDAO)
+	playlist := &model.Playlist{Model: gorm.Model{ID: 1}, UserID: 10, Visibility: model.VisibilityPublic}
+
+	mockDAO.On("GetPlaylistInfo", "1").Return(playlist, nil)
+	mockDAO.On("GetPlaylistCollaborator", uint(1), uint(20)).
+		Return(&model.PlaylistCollaborator{PlaylistID: 1, UserID: 20, Role: model.CollaboratorEditor, Status: model.InvitePending}, nil).Once()
+	mockDAO.On("GetPlaylistCollaborator", uint(1), uint(20)).
+		Return(&model.PlaylistCollaborator{PlaylistID: 1, UserID: 20, Role: model.CollaboratorEditor, Status: model.InviteAccepted}, nil)
+	mockDAO.On("GetPlaylistCollaborator", uint(1), uint(30)).Return(nil, ErrCollaboratorNotFound)
+	mockDAO.On("SavePlaylistCollaborator", mock.AnythingOfType("*model.PlaylistCollaborator")).Return(nil)
+
+	collaborator, err := ps.RespondToInvite("1", 20, true)
+	assert.NoError(t, err)
+	assert.Equal(t, model.InviteAccepted, collaborator.Status)
+	assert.NotNil(t, collaborator.RespondedAt)
+
+	_, err = ps.RespondToInvite("1", 20, false)
+	assert.Equal(t, ErrNoPendingInvite, err)
+
+	_, err = ps.RespondToInvite("1", 30, true)
+	assert.Equal(t, ErrNoPendingInvite, err)
+}
+
+func TestRemoveCollaborator(t *testing.T) {
+	mockDAO := new(mocks.MusicDAO)
+	ps := NewPlaylistService(mockDAO)
+	playlist := &model.Playlist{Model: gorm.Model{ID: 1}, UserID: 10, Visibility: model.VisibilityPublic}
+
+	mockDAO.On("GetPlaylistInfo", "1").Return(playlist, nil)
+	mockDAO.On("GetPlaylistCollaborator", uint(1), uint(20)).
+		Return(&model.PlaylistCollaborator{Role: model.CollaboratorEditor, Status: model.InviteAccepted}, nil)
+	mockDAO.On("DeletePlaylistCollaborator", uint(1), uint(20)).Return(nil)
+	mockDAO.On("DeletePlaylistCollaborator", uint(1), uint(21)).Return(nil)
+
+	// Collaborators may leave, and the owner may remove anyone, but editors cannot remove others.
+	assert.NoError(t, ps.RemoveCollaborator("1", 20, 20))
+	assert.NoError(t, ps.RemoveCollaborator("1", 10, 21))
+	assert.Equal(t, ErrPlaylistForbidden, ps.RemoveCollaborator("1", 20, 21))
+	mockDAO.AssertNumberOfCalls(t, "DeletePlaylistCollaborator", 2)
+}
@@ -0,0 +1,210 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/model"
+)
+
+// Ways of ordering the songs of a merged playlist.
+const (
+	MergeOrderSequential = "sequential" // All songs of the first playlist, then the second, and so on.
+	MergeOrderInterleave = "interleave" // One song from each playlist in turn.
+)
+
+// Ways of deciding that two playlist entries are duplicates.
+const (
+	DedupeNone         = "none"
+	DedupeBySongID     = "song_id"
+	DedupeByNameArtist = "name_artist" // Same normalized song name and artist, e.g. a single and its album version.
+)
+
+const (
+	minMergeSources = 2
+	maxMergeSources = 20
+)
+
+var (
+	ErrInvalidMergeSources = fmt.Errorf("between %d and %d distinct playlists must be merged", minMergeSources, maxMergeSources)
+	ErrInvalidMergeOrder   = errors.New("order must be sequential or interleave")
+	ErrInvalidDedupeMode   = errors.New("dedupe must be none, song_id or name_artist")
+)
+
+// PlaylistMerge describes a new playlist made from the songs of existing ones.
+type PlaylistMerge struct {
+	PlaylistIDs []uint `json:"playlist_ids"`
+	Name        string `json:"playlist_name"`
+	Visibility  string `json:"visibility"`
+	Order       string `json:"order"`
+	Dedupe      string `json:"dedupe"`
+}
+
+// DedupeResult reports how many entries a dedupe removed and the playlist that remains.
+type DedupeResult struct {
+	Removed  int             `json:"removed"`
+	Playlist *model.Playlist `json:"playlist"`
+}
+
+// CopyPlaylist creates a playlist owned by userID with the songs of a playlist the user can see.
+// The copy is static even when the source is a smart playlist. An empty name names it after the source.
+func (s *playlistService) CopyPlaylist(playlistID string, userID uint, name, visibility string) (*model.Playlist, error) {
+	source, err := s.musicDAO.GetPlaylistByID(playlistID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(source, userID, PermissionView); err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(name) == "" {
+		name = source.Name + " (copy)"
+	}
+	playlist := &model.Playlist{Name: name, UserID: userID, Visibility: visibility}
+	if err := s.insertPlaylist(playlist, entrySongIDs(source.Entries)); err != nil {
+		return nil, err
+	}
+	return s.musicDAO.GetPlaylistByID(fmt.Sprint(playlist.ID))
+}
+
+// MergePlaylists creates a playlist owned by userID with the songs of several playlists the user can see.
+func (s *playlistService) MergePlaylists(userID uint, merge PlaylistMerge) (*model.Playlist, error) {
+	switch merge.Order {
+	case "":
+		merge.Order = MergeOrderSequential
+	case MergeOrderSequential, MergeOrderInterleave:
+	default:
+		return nil, ErrInvalidMergeOrder
+	}
+	if merge.Dedupe == "" {
+		merge.Dedupe = DedupeNone
+	}
+	if !validDedupeMode(merge.Dedupe) {
+		return nil, ErrInvalidDedupeMode
+	}
+	playlistIDs := uniqueIDs(merge.PlaylistIDs)
+	if len(playlistIDs) < minMergeSources || len(playlistIDs) > maxMergeSources {
+		return nil, ErrInvalidMergeSources
+	}
+
+	sources := make([][]model.PlaylistEntry, len(playlistIDs))
+	for i, id := range playlistIDs {
+		source, err := s.musicDAO.GetPlaylistByID(fmt.Sprint(id))
+		if err != nil {
+			return nil, err
+		}
+		if err := s.authorize(source, userID, PermissionView); err != nil {
+			return nil, err
+		}
+		sources[i] = source.Entries
+	}
+
+	var entries []model.PlaylistEntry
+	if merge.Order == MergeOrderInterleave {
+		for i := 0; ; i++ {
+			added := false
+			for _, source := range sources {
+				if i < len(source) {
+					entries = append(entries, source[i])
+					added = true
+				}
+			}
+			if !added {
+				break
+			}
+		}
+	} else {
+		for _, source := range sources {
+			entries = append(entries, source...)
+		}
+	}
+	if merge.Dedupe != DedupeNone {
+		entries, _ = dedupeEntries(entries, merge.Dedupe)
+	}
+
+	playlist := &model.Playlist{Name: merge.Name, UserID: userID, Visibility: merge.Visibility}
+	if err := s.insertPlaylist(playlist, entrySongIDs(entries)); err != nil {
+		return nil, err
+	}
+	return s.musicDAO.GetPlaylistByID(fmt.Sprint(playlist.ID))
+}
+
+// DedupePlaylist removes duplicate entries from a playlist, keeping the first occurrence of each song.
+func (s *playlistService) DedupePlaylist(playlistID string, userID uint, by string) (*DedupeResult, error) {
+	if by == "" {
+		by = DedupeBySongID
+	}
+	if by == DedupeNone || !validDedupeMode(by) {
+		return nil, ErrInvalidDedupeMode
+	}
+	playlist, err := s.musicDAO.GetPlaylistInfo(playlistID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(playlist, userID, PermissionEditSongs); err != nil {
+		return nil, err
+	}
+
+	removed, err := s.musicDAO.RemoveDuplicateEntries(playlistID, func(entries []model.PlaylistEntry) []model.PlaylistEntry {
+		_, duplicates := dedupeEntries(entries, by)
+		return duplicates
+	}, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	playlist, err = s.musicDAO.GetPlaylistByID(playlistID)
+	if err != nil {
+		return nil, err
+	}
+	return &DedupeResult{Removed: removed, Playlist: playlist}, nil
+}
+
+func validDedupeMode(mode string) bool {
+	switch mode {
+	case DedupeNone, DedupeBySongID, DedupeByNameArtist:
+		return true
+	}
+	return false
+}
+
+// dedupeEntries splits entries into the first occurrence of each song and the later duplicates.
+// Entries are expected to have their Song loaded when deduping by name and artist.
+func dedupeEntries(entries []model.PlaylistEntry, by string) (kept, duplicates []model.PlaylistEntry) {
+	seen := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		key := fmt.Sprint(entry.SongID)
+		if by == DedupeByNameArtist {
+			key = normalizeForMatch(entry.Song.Name) + "|" + normalizeForMatch(entry.Song.Artist)
+		}
+		if seen[key] {
+			duplicates = append(duplicates, entry)
+			continue
+		}
+		seen[key] = true
+		kept = append(kept, entry)
+	}
+	return kept, duplicates
+}
+
+func entrySongIDs(entries []model.PlaylistEntry) []uint {
+	songIDs := make([]uint, len(entries))
+	for i, entry := range entries {
+		songIDs[i] = entry.SongID
+	}
+	return songIDs
+}
+
+// uniqueIDs returns ids without repeats, in the order they first appear.
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	unique := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
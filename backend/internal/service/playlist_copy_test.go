@@ -0,0 +1,168 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/dao/mocks"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func entriesOf(songs ...model.Song) []model.PlaylistEntry {
+	entries := make([]model.PlaylistEntry, len(songs))
+	for i, song := range songs {
+		entries[i] = model.PlaylistEntry{ID: uint(100 + i), SongID: song.ID, Song: song, Position: i}
+	}
+	return entries
+}
+
+func playlistSongIDs(p *model.Playlist) []uint {
+	return entrySongIDs(p.Entries)
+}
+
+func TestCopyPlaylist(t *testing.T) {
+	mockDAO := new(mocks.MusicDAO)
+	ps := NewPlaylistService(mockDAO)
+
+	rules := &model.SmartRules{Artists: []string{"A"}}
+	source := &model.Playlist{Model: gorm.Model{ID: 1}, Name: "Road trip", UserID: 10, Visibility: model.VisibilityPublic, SmartRules: rules,
+		Entries: entriesOf(model.Song{Model: gorm.Model{ID: 3}}, model.Song{Model: gorm.Model{ID: 1}})}
+	mockDAO.On("GetPlaylistByID", "1").Return(source, nil)
+	mockDAO.On("GetPlaylistCollaborator", uint(1), uint(20)).Return(nil, ErrCollaboratorNotFound)
+	mockDAO.On("CreatePlaylist", mock.MatchedBy(func(p *model.Playlist) bool {
+		return p.Name == "Road trip (copy)" && p.UserID == 20 && p.SmartRules == nil && p.Visibility == model.VisibilityPrivate &&
+			assert.ObjectsAreEqual([]uint{3, 1}, playlistSongIDs(p)) && p.Entries[0].AddedByID == 20
+	})).Run(func(args mock.Arguments) {
+		args.Get(0).(*model.Playlist).ID = 2
+	}).Return(nil)
+	mockDAO.On("CreateActivity", mock.AnythingOfType("*model.Activity")).Return(nil)
+	copied := &model.Playlist{Model: gorm.Model{ID: 2}, Name: "Road trip (copy)", UserID: 20}
+	mockDAO.On("GetPlaylistByID", "2").Return(copied, nil)
+
+	playlist, err := ps.CopyPlaylist("1", 20, " ", model.VisibilityPrivate)
+	assert.NoError(t, err)
+	assert.Equal(t, copied, playlist)
+	mockDAO.AssertExpectations(t)
+}
+
+func TestCopyHiddenPlaylist(t *testing.T) {
+	mockDAO := new(mocks.MusicDAO)
+	ps := NewPlaylistService(mockDAO)
+
+	mockDAO.On("GetPlaylistByID", "1").Return(&model.Playlist{Model: gorm.Model{ID: 1}, UserID: 10, Visibility: model.VisibilityPrivate}, nil)
+	mockDAO.On("GetPlaylistCollaborator", uint(1), uint(20)).Return(nil, ErrCollaboratorNotFound)
+
+	_, err := ps.CopyPlaylist("1", 20, "", "")
+	assert.ErrorIs(t, err, ErrPlaylistNotFound)
+	mockDAO.AssertNotCalled(t, "CreatePlaylist", mock.Anything)
+}
+
+func TestMergePlaylists(t *testing.T) {
+	a := model.Song{Model: gorm.Model{ID: 1}, Name: "Song A", Artist: "X"}
+	b := model.Song{Model: gorm.Model{ID: 2}, Name: "Song B", Artist: "X"}
+	bAlbum := model.Song{Model: gorm.Model{ID: 3}, Name: "song b!", Artist: "x"}
+	c := model.Song{Model: gorm.Model{ID: 4}, Name: "Song C", Artist: "Y"}
+
+	tests := []struct {
+		name   string
+		order  string
+		dedupe string
+		want   []uint
+	}{
+		{"sequential", "", "", []uint{1, 2, 3, 2, 4}},
+		{"interleave", MergeOrderInterleave, DedupeNone, []uint{1, 3, 2, 2, 4}},
+		{"dedupe by song", MergeOrderSequential, DedupeBySongID, []uint{1, 2, 3, 4}},
+		{"dedupe by name and artist", MergeOrderInterleave, DedupeByNameArtist, []uint{1, 3, 4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDAO := new(mocks.MusicDAO)
+			ps := NewPlaylistService(mockDAO)
+
+			mockDAO.On("GetPlaylistByID", "1").Return(&model.Playlist{Model: gorm.Model{ID: 1}, UserID: 10, Entries: entriesOf(a, b)}, nil)
+			mockDAO.On("GetPlaylistByID", "2").Return(&model.Playlist{Model: gorm.Model{ID: 2}, UserID: 10, Entries: entriesOf(bAlbum, b, c)}, nil)
+			mockDAO.On("CreatePlaylist", mock.MatchedBy(func(p *model.Playlist) bool {
+				return p.Name == "Mix" && p.UserID == 10 && assert.ObjectsAreEqual(tt.want, playlistSongIDs(p))
+			})).Run(func(args mock.Arguments) {
+				args.Get(0).(*model.Playlist).ID = 3
+			}).Return(nil)
+			mockDAO.On("CreateActivity", mock.AnythingOfType("*model.Activity")).Return(nil)
+			mockDAO.On("GetPlaylistByID", "3").Return(&model.Playlist{Model: gorm.Model{ID: 3}}, nil)
+
+			_, err := ps.MergePlaylists(10, PlaylistMerge{PlaylistIDs: []uint{1, 2, 1}, Name: "Mix", Order: tt.order, Dedupe: tt.dedupe})
+			assert.NoError(t, err)
+			mockDAO.AssertExpectations(t)
+		})
+	}
+}
+
+func TestMergePlaylistsInvalid(t *testing.T) {
+	ps := NewPlaylistService(new(mocks.MusicDAO))
+
+	_, err := ps.MergePlaylists(10, PlaylistMerge{PlaylistIDs: []uint{1, 1}, Name: "Mix"})
+	assert.ErrorIs(t, err, ErrInvalidMergeSources)
+
+	_, err = ps.MergePlaylists(10, PlaylistMerge{PlaylistIDs: []uint{1, 2}, Name: "Mix", Order: "shuffle"})
+	assert.ErrorIs(t, err, ErrInvalidMergeOrder)
+
+	_, err = ps.MergePlaylists(10, PlaylistMerge{PlaylistIDs: []uint{1, 2}, Name: "Mix", Dedupe: "album"})
+	assert.ErrorIs(t, err, ErrInvalidDedupeMode)
+}
+
+func TestDedupePlaylist(t *testing.T) {
+	mockDAO := new(mocks.MusicDAO)
+	ps := NewPlaylistService(mockDAO)
+
+	a := model.Song{Model: gorm.Model{ID: 1}, Name: "Song A", Artist: "X"}
+	aLive := model.Song{Model: gorm.Model{ID: 2}, Name: "Song  A", Artist: "X"}
+	b := model.Song{Model: gorm.Model{ID: 3}, Name: "Song B", Artist: "X"}
+	mockDAO.On("GetPlaylistInfo", "1").Return(&model.Playlist{Model: gorm.Model{ID: 1}, UserID: 10}, nil)
+	mockDAO.On("RemoveDuplicateEntries", "1", mock.Anything, uint(10)).Run(func(args mock.Arguments) {
+		// The duplicates are picked from the entries the DAO reads under the playlist's lock.
+		duplicates := args.Get(1).(func([]model.PlaylistEntry) []model.PlaylistEntry)(entriesOf(a, b, aLive, a))
+		assert.Equal(t, []uint{102, 103}, []uint{duplicates[0].ID, duplicates[1].ID})
+		assert.Len(t, duplicates, 2)
+	}).Return(2, nil).Once()
+	deduped := &model.Playlist{Model: gorm.Model{ID: 1}, UserID: 10, Entries: entriesOf(a, b)}
+	mockDAO.On("GetPlaylistByID", "1").Return(deduped, nil).Once()
+
+	result, err := ps.DedupePlaylist("1", 10, DedupeByNameArtist)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result.Removed)
+	assert.Equal(t, deduped, result.Playlist)
+	mockDAO.AssertExpectations(t)
+}
+
+func TestDedupePlaylistNoDuplicates(t *testing.T) {
+	mockDAO := new(mocks.MusicDAO)
+	ps := NewPlaylistService(mockDAO)
+
+	a := model.Song{Model: gorm.Model{ID: 1}, Name: "Song A", Artist: "X"}
+	aLive := model.Song{Model: gorm.Model{ID: 2}, Name: "Song A", Artist: "X"}
+	mockDAO.On("GetPlaylistInfo", "1").Return(&model.Playlist{Model: gorm.Model{ID: 1}, UserID: 10}, nil)
+	mockDAO.On("RemoveDuplicateEntries", "1", mock.Anything, uint(10)).Run(func(args mock.Arguments) {
+		duplicates := args.Get(1).(func([]model.PlaylistEntry) []model.PlaylistEntry)(entriesOf(a, aLive))
+		assert.Empty(t, duplicates)
+	}).Return(0, nil)
+	mockDAO.On("GetPlaylistByID", "1").Return(&model.Playlist{Model: gorm.Model{ID: 1}, UserID: 10, Entries: entriesOf(a, aLive)}, nil)
+
+	result, err := ps.DedupePlaylist("1", 10, "")
+	assert.NoError(t, err)
+	assert.Zero(t, result.Removed)
+
+	_, err = ps.DedupePlaylist("1", 10, DedupeNone)
+	assert.ErrorIs(t, err, ErrInvalidDedupeMode)
+}
+
+func TestDedupeSmartPlaylist(t *testing.T) {
+	mockDAO := new(mocks.MusicDAO)
+	ps := NewPlaylistService(mockDAO)
+
+	mockDAO.On("GetPlaylistInfo", "1").Return(&model.Playlist{Model: gorm.Model{ID: 1}, UserID: 10, SmartRules: &model.SmartRules{}}, nil)
+
+	_, err := ps.DedupePlaylist("1", 10, DedupeBySongID)
+	assert.ErrorIs(t, err, ErrSmartPlaylistReadOnly)
+	mockDAO.AssertNotCalled(t, "RemoveDuplicateEntries", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/model"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/playlistfile"
+)
+
+var (
+	ErrEmptyPlaylistFile  = errors.New("playlist file has no entries")
+	ErrTooManyFileEntries = fmt.Errorf("playlist files may have at most %d entries", maxFileImportEntries)
+)
+
+const (
+	MatchSourceSpotifyID = "spotify_id"
+	MatchSourceLocal     = "local"
+	MatchSourceCatalog   = "catalog"
+
+	// matchThreshold is the confidence above which a song is accepted as a match.
+	matchThreshold = 0.8
+
+	maxFileImportEntries = 500
+	songCandidateLimit   = 25
+	fileImportTimeout    = 2 * time.Minute
+)
+
+// FileImportMatch reports how one entry of an imported playlist file was resolved.
+// For unresolved entries Song holds the best candidate found, if any.
+type FileImportMatch struct {
+	playlistfile.Entry
+	Song       *model.Song `json:"song,omitempty"`
+	Source     string      `json:"source,omitempty"`
+	Confidence float64     `json:"confidence"`
+}
+
+// PlaylistFileImportReport describes the playlist created from a file and how its entries were matched.
+type PlaylistFileImportReport struct {
+	PlaylistID uint              `json:"playlist_id"`
+	Name       string            `json:"name"`
+	Total      int               `json:"total"`
+	Matched    []FileImportMatch `json:"matched"`
+	Unresolved []FileImportMatch `json:"unresolved"`
+}
+
+// ImportFile parses a playlist file, resolves each entry against the local songs and then the
+// catalog, and creates a playlist owned by the user with the matched songs in file order.
+func (s *playlistImportService) ImportFile(userID uint, name string, format *playlistfile.Format, file io.Reader) (*PlaylistFileImportReport, error) {
+	entries, err := format.Read(file)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, ErrEmptyPlaylistFile
+	}
+	if len(entries) > maxFileImportEntries {
+		return nil, ErrTooManyFileEntries
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), fileImportTimeout)
+	defer cancel()
+
+	report := &PlaylistFileImportReport{
+		Name:       name,
+		Total:      len(entries),
+		Matched:    []FileImportMatch{},
+		Unresolved: []FileImportMatch{},
+	}
+	playlist := &model.Playlist{Name: name, UserID: userID}
+	now := time.Now()
+	for _, entry := range entries {
+		match := s.resolveEntry(ctx, entry)
+		if match.Confidence < matchThreshold {
+			report.Unresolved = append(report.Unresolved, match)
+			continue
+		}
+		report.Matched = append(report.Matched, match)
+		playlist.Entries = append(playlist.Entries, model.PlaylistEntry{
+			SongID:    match.Song.ID,
+			Position:  len(playlist.Entries),
+			AddedByID: userID,
+			AddedAt:   now,
+		})
+	}
+
+	if err := s.musicDAO.CreatePlaylist(playlist); err != nil {
+		return nil, err
+	}
+	recordActivity(s.musicDAO, model.Activity{ActorID: userID, Type: model.ActivityPlaylistCreated, PlaylistID: playlist.ID})
+	report.PlaylistID = playlist.ID
+	return report, nil
+}
+
+// resolveEntry finds the song an entry refers to: by Spotify ID when the file has one, otherwise by
+// fuzzy matching local songs and, if none is close enough, the catalog search.
+func (s *playlistImportService) resolveEntry(ctx context.Context, entry playlistfile.Entry) FileImportMatch {
+	match := FileImportMatch{Entry: entry}
+
+	if entry.SpotifyID != "" {
+		if song := s.songBySpotifyID(ctx, entry.SpotifyID); song != nil {
+			match.Song, match.Source, match.Confidence = song, MatchSourceSpotifyID, 1
+			return match
+		}
+	}
+	if entry.Title == "" {
+		return match
+	}
+
+	candidates, err := s.musicDAO.FindSongCandidates(stripTitleAnnotations(entry.Title), songCandidateLimit)
+	if err != nil {
+		log.Printf("Failed to find local candidates for %q: %v", entry.Raw, err)
+	}
+	for i := range candidates {
+		match.consider(&candidates[i], MatchSourceLocal)
+	}
+	if match.Confidence >= matchThreshold || entry.Artist == "" {
+		return match
+	}
+
+	// The catalog search requires an artist, and stores what it finds.
+	songs, err := s.songService.SearchSongsFromSpotify(entry.Title, entry.Artist)
+	if err != nil {
+		log.Printf("Catalog search failed for %q: %v", entry.Raw, err)
+		return match
+	}
+	for _, song := range songs {
+		match.consider(song, MatchSourceCatalog)
+	}
+
+	// Songs that were already stored come back from the search without their ID.
+	if match.Source == MatchSourceCatalog && match.Song.ID == 0 {
+		stored, err := s.musicDAO.GetSongBySpotifyID(match.Song.SpotifyID)
+		if err != nil {
+			match.Confidence = 0
+			return match
+		}
+		match.Song = stored
+	}
+	return match
+}
+
+// consider replaces the match's song with song if it scores higher.
+func (m *FileImportMatch) consider(song *model.Song, source string) {
+	confidence := songMatchConfidence(m.Title, m.Artist, song)
+	if m.Song == nil || confidence > m.Confidence {
+		m.Song, m.Source, m.Confidence = song, source, confidence
+	}
+}
+
+// songBySpotifyID returns the stored song with the given Spotify ID, fetching and storing it
+// from the catalog when it is not known yet.
+func (s *playlistImportService) songBySpotifyID(ctx context.Context, spotifyID string) *model.Song {
+	song, err := s.musicDAO.GetSongBySpotifyID(spotifyID)
+	if err == nil {
+		return song
+	}
+
+	tracks, err := s.catalog.GetTracks(ctx, []string{spotifyID})
+	if err != nil || tracks[spotifyID] == nil {
+		return nil
+	}
+	song = tracks[spotifyID]
+	refreshedAt := time.Now()
+	song.RefreshedAt = &refreshedAt
+	if err := s.musicDAO.UpsertSongs([]*model.Song{song}); err != nil {
+		log.Printf("Failed to store catalog track %s: %v", spotifyID, err)
+		return nil
+	}
+	return song
+}
@@ -0,0 +1,168 @@
+package service
+
+import (
+	"slices"
+
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/dao"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/model"
+)
+
+var ErrRevisionNotFound = dao.ErrRevisionNotFound
+
+const (
+	defaultRevisionLimit = 20
+	maxRevisionLimit     = 100
+)
+
+// SongChanges lists the songs added to and removed from a playlist between two revisions. A song
+// added or removed several times is listed as many times.
+type SongChanges struct {
+	Added   []uint `json:"added_song_ids"`
+	Removed []uint `json:"removed_song_ids"`
+	// Reordered is set when the songs present in both revisions are in a different order.
+	Reordered bool `json:"reordered"`
+}
+
+// PlaylistHistoryEntry is a revision along with what changed since the previous revision.
+type PlaylistHistoryEntry struct {
+	model.PlaylistRevision
+	SongChanges
+}
+
+// PlaylistHistory is a page of a playlist's revisions, newest first. NextBefore is passed as
+// "before" to fetch the next page.
+type PlaylistHistory struct {
+	Revisions  []PlaylistHistoryEntry `json:"revisions"`
+	NextBefore int                    `json:"next_before,omitempty"`
+}
+
+// RevisionDiff describes how a playlist changed from one revision to another. Songs holds the
+// details of the added and removed songs that still exist.
+type RevisionDiff struct {
+	From int `json:"from"`
+	To   int `json:"to"`
+	SongChanges
+	Songs []model.Song `json:"songs"`
+}
+
+// GetPlaylistHistory returns a page of the revisions of a playlist the user can see.
+func (s *playlistService) GetPlaylistHistory(playlistID string, userID uint, before, limit int) (*PlaylistHistory, error) {
+	if limit <= 0 {
+		limit = defaultRevisionLimit
+	}
+	if limit > maxRevisionLimit {
+		limit = maxRevisionLimit
+	}
+	playlist, err := s.musicDAO.GetPlaylistInfo(playlistID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(playlist, userID, PermissionView); err != nil {
+		return nil, err
+	}
+
+	// One more revision is loaded to tell what the oldest revision of the page changed.
+	revisions, err := s.musicDAO.GetPlaylistRevisions(playlist.ID, before, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	history := &PlaylistHistory{Revisions: []PlaylistHistoryEntry{}}
+	for i := 0; i < len(revisions) && i < limit; i++ {
+		var previous []uint
+		if i+1 < len(revisions) {
+			previous = revisions[i+1].SongIDs
+		}
+		history.Revisions = append(history.Revisions, PlaylistHistoryEntry{
+			PlaylistRevision: revisions[i],
+			SongChanges:      diffSongIDs(previous, revisions[i].SongIDs),
+		})
+	}
+	if len(revisions) > limit {
+		history.NextBefore = revisions[limit-1].Number
+	}
+	return history, nil
+}
+
+// DiffPlaylistRevisions compares two revisions of a playlist the user can see.
+func (s *playlistService) DiffPlaylistRevisions(playlistID string, userID uint, from, to int) (*RevisionDiff, error) {
+	playlist, err := s.musicDAO.GetPlaylistInfo(playlistID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(playlist, userID, PermissionView); err != nil {
+		return nil, err
+	}
+
+	fromRevision, err := s.musicDAO.GetPlaylistRevision(playlist.ID, from)
+	if err != nil {
+		return nil, err
+	}
+	toRevision, err := s.musicDAO.GetPlaylistRevision(playlist.ID, to)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &RevisionDiff{From: from, To: to, SongChanges: diffSongIDs(fromRevision.SongIDs, toRevision.SongIDs)}
+	changed := append(slices.Clone(diff.Added), diff.Removed...)
+	slices.Sort(changed)
+	if diff.Songs, err = s.musicDAO.GetSongsByIDs(slices.Compact(changed)); err != nil {
+		return nil, err
+	}
+	if diff.Songs == nil {
+		diff.Songs = []model.Song{}
+	}
+	return diff, nil
+}
+
+// RestorePlaylistRevision rolls the songs of a playlist back to a revision. The restore is itself
+// recorded as a revision, so it can be undone.
+func (s *playlistService) RestorePlaylistRevision(playlistID string, userID uint, number int) (*model.Playlist, error) {
+	playlist, err := s.musicDAO.GetPlaylistInfo(playlistID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(playlist, userID, PermissionEditSongs); err != nil {
+		return nil, err
+	}
+
+	if err := s.musicDAO.RestorePlaylistRevision(playlistID, number, userID); err != nil {
+		return nil, err
+	}
+	return s.musicDAO.GetPlaylistByID(playlistID)
+}
+
+// diffSongIDs compares two ordered lists of songs, which may contain a song several times.
+func diffSongIDs(from, to []uint) SongChanges {
+	changes := SongChanges{Added: []uint{}, Removed: []uint{}}
+
+	remaining := make(map[uint]int, len(from))
+	for _, id := range from {
+		remaining[id]++
+	}
+	var keptTo []uint
+	for _, id := range to {
+		if remaining[id] > 0 {
+			remaining[id]--
+			keptTo = append(keptTo, id)
+		} else {
+			changes.Added = append(changes.Added, id)
+		}
+	}
+
+	remaining = make(map[uint]int, len(to))
+	for _, id := range to {
+		remaining[id]++
+	}
+	var keptFrom []uint
+	for _, id := range from {
+		if remaining[id] > 0 {
+			remaining[id]--
+			keptFrom = append(keptFrom, id)
+		} else {
+			changes.Removed = append(changes.Removed, id)
+		}
+	}
+
+	changes.Reordered = !slices.Equal(keptFrom, keptTo)
+	return changes
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/dao/mocks"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/model"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestDiffSongIDs(t *testing.T) {
+	changes := diffSongIDs([]uint{1, 2, 3, 2}, []uint{3, 2, 4, 1})
+	assert.Equal(t, []uint{4}, changes.Added)
+	assert.Equal(t, []uint{2}, changes.Removed)
+	assert.True(t, changes.Reordered)
+
+	changes = diffSongIDs(nil, []uint{1, 2})
+	assert.Equal(t, []uint{1, 2}, changes.Added)
+	assert.Empty(t, changes.Removed)
+	assert.False(t, changes.Reordered)
+
+	changes = diffSongIDs([]uint{1, 2, 3}, []uint{1, 3})
+	assert.Empty(t, changes.Added)
+	assert.Equal(t, []uint{2}, changes.Removed)
+	assert.False(t, changes.Reordered)
+}
+
+func TestGetPlaylistHistory(t *testing.T) {
+	mockDAO := new(mocks.MusicDAO)
+	ps := NewPlaylistService(mockDAO)
+
+	mockDAO.On("GetPlaylistInfo", "1").Return(&model.Playlist{Model: gorm.Model{ID: 1}, UserID: 10, Visibility: model.VisibilityPublic}, nil)
+	mockDAO.On("GetPlaylistRevisions", uint(1), 0, 3).Return([]model.PlaylistRevision{
+		{Number: 4, Action: model.RevisionSongsRemoved, SongIDs: []uint{1}},
+		{Number: 3, Action: model.RevisionSongsMoved, SongIDs: []uint{1, 2}},
+		{Number: 2, Action: model.RevisionSongsAdded, SongIDs: []uint{2, 1}},
+	}, nil)
+	mockDAO.On("GetPlaylistRevisions", uint(1), 3, 3).Return([]model.PlaylistRevision{
+		{Number: 2, Action: model.RevisionSongsAdded, SongIDs: []uint{2, 1}},
+		{Number: 1, Action: model.RevisionCreated, SongIDs: []uint{2}},
+	}, nil)
+
+	history, err := ps.GetPlaylistHistory("1", 10, 0, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, history.NextBefore)
+	if assert.Len(t, history.Revisions, 2) {
+		assert.Equal(t, []uint{2}, history.Revisions[0].Removed)
+		assert.True(t, history.Revisions[1].Reordered)
+	}
+
+	history, err = ps.GetPlaylistHistory("1", 10, 3, 2)
+	assert.NoError(t, err)
+	assert.Zero(t, history.NextBefore)
+	if assert.Len(t, history.Revisions, 2) {
+		assert.Equal(t, []uint{1}, history.Revisions[0].Added)
+		assert.Equal(t, []uint{2}, history.Revisions[1].Added)
+	}
+}
+
+func TestDiffPlaylistRevisions(t *testing.T) {
+	mockDAO := new(mocks.MusicDAO)
+	ps := NewPlaylistService(mockDAO)
+
+	mockDAO.On("GetPlaylistInfo", "1").Return(&model.Playlist{Model: gorm.Model{ID: 1}, UserID: 10}, nil)
+	mockDAO.On("GetPlaylistRevision", uint(1), 1).Return(&model.PlaylistRevision{Number: 1, SongIDs: []uint{1, 2}}, nil)
+	mockDAO.On("GetPlaylistRevision", uint(1), 3).Return(&model.PlaylistRevision{Number: 3, SongIDs: []uint{2, 3}}, nil)
+	mockDAO.On("GetPlaylistRevision", uint(1), 9).Return(nil, ErrRevisionNotFound)
+	mockDAO.On("GetSongsByIDs", []uint{1, 3}).Return([]model.Song{{Model: gorm.Model{ID: 1}}, {Model: gorm.Model{ID: 3}}}, nil)
+
+	diff, err := ps.DiffPlaylistRevisions("1", 10, 1, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint{3}, diff.Added)
+	assert.Equal(t, []uint{1}, diff.Removed)
+	assert.Len(t, diff.Songs, 2)
+
+	_, err = ps.DiffPlaylistRevisions("1", 10, 1, 9)
+	assert.Equal(t, ErrRevisionNotFound, err)
+
+	// Private playlists' history is hidden from other users.
+	mockDAO.On("GetPlaylistCollaborator", uint(1), uint(20)).Return(nil, ErrCollaboratorNotFound)
+	_, err = ps.DiffPlaylistRevisions("1", 20, 1, 3)
+	assert.Equal(t, ErrPlaylistNotFound, err)
+}
+
+func TestRestorePlaylistRevision(t *testing.T) {
+	mockDAO := new(mocks.MusicDAO)
+	ps := NewPlaylistService(mockDAO)
+	playlist := &model.Playlist{Model: gorm.Model{ID: 1}, UserID: 10}
+
+	mockDAO.On("GetPlaylistInfo", "1").Return(playlist, nil)
+	mockDAO.On("RestorePlaylistRevision", "1", 2, uint(10)).Return(nil).Once()
+	mockDAO.On("RestorePlaylistRevision", "1", 9, uint(10)).Return(ErrRevisionNotFound).Once()
+	mockDAO.On("GetPlaylistByID", "1").Return(playlist, nil)
+
+	restored, err := ps.RestorePlaylistRevision("1", 10, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, playlist, restored)
+
+	_, err = ps.RestorePlaylistRevision("1", 10, 9)
+	assert.Equal(t, ErrRevisionNotFound, err)
+
+	playlist.SmartRules = &model.SmartRules{LikedByMe: true}
+	_, err = ps.RestorePlaylistRevision("1", 10, 2)
+	assert.Equal(t, ErrSmartPlaylistReadOnly, err)
+	mockDAO.AssertExpectations(t)
+}
@@ -0,0 +1,280 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/dao"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/model"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/playlistfile"
+)
+
+var (
+	ErrInvalidImportSource = errors.New("invalid Spotify playlist or album")
+	ErrImportJobNotFound   = errors.New("import job not found")
+)
+
+const (
+	ImportSourcePlaylist = "playlist"
+	ImportSourceAlbum    = "album"
+
+	ImportJobPending   = "pending"
+	ImportJobRunning   = "running"
+	ImportJobCompleted = "completed"
+	ImportJobFailed    = "failed"
+
+	importTimeout      = 10 * time.Minute
+	importJobRetention = 24 * time.Hour
+	importBatchSize    = 50
+)
+
+var spotifyIDPattern = regexp.MustCompile(`^[0-9A-Za-z]+$`)
+
+// PlaylistImportRequest describes the Spotify playlist or album to import.
+type PlaylistImportRequest struct {
+	Source string `json:"source"`         // Spotify URL, URI or bare ID.
+	Type   string `json:"type,omitempty"` // "playlist" or "album"; required when Source is a bare ID.
+	Name   string `json:"name,omitempty"` // Name of the new playlist; defaults to the source's name.
+}
+
+// PlaylistImportJob tracks the progress of an asynchronous playlist import.
+type PlaylistImportJob struct {
+	ID             string     `json:"id"`
+	UserID         uint       `json:"user_id"`
+	SourceType     string     `json:"source_type"`
+	SourceID       string     `json:"source_id"`
+	Status         string     `json:"status"`
+	TotalTracks    int        `json:"total_tracks"`
+	ImportedTracks int        `json:"imported_tracks"`
+	PlaylistID     uint       `json:"playlist_id,omitempty"`
+	Error          string     `json:"error,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	FinishedAt     *time.Time `json:"finished_at,omitempty"`
+}
+
+// PlaylistImportService imports Spotify playlists and albums, or playlist files, as playlists owned by a user.
+// Jobs are kept in memory, so their status is only visible on the replica that started them.
+type PlaylistImportService interface {
+	StartImport(userID uint, req PlaylistImportRequest) (*PlaylistImportJob, error)
+	GetImportJob(userID uint, jobID string) (*PlaylistImportJob, error)
+	ImportFile(userID uint, name string, format *playlistfile.Format, file io.Reader) (*PlaylistFileImportReport, error)
+}
+
+type playlistImportService struct {
+	musicDAO    dao.MusicDAO
+	catalog     CatalogClient
+	songService SongService
+
+	mu   sync.Mutex
+	jobs map[string]*PlaylistImportJob
+}
+
+func NewPlaylistImportService(musicDAO dao.MusicDAO, catalog CatalogClient, songService SongService) PlaylistImportService {
+	return &playlistImportService{
+		musicDAO:    musicDAO,
+		catalog:     catalog,
+		songService: songService,
+		jobs:        make(map[string]*PlaylistImportJob),
+	}
+}
+
+// StartImport validates the request and starts importing it in the background.
+func (s *playlistImportService) StartImport(userID uint, req PlaylistImportRequest) (*PlaylistImportJob, error) {
+	sourceType, sourceID, err := parseSpotifySource(req.Source, req.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	jobID, err := newImportJobID()
+	if err != nil {
+		return nil, err
+	}
+
+	job := &PlaylistImportJob{
+		ID:         jobID,
+		UserID:     userID,
+		SourceType: sourceType,
+		SourceID:   sourceID,
+		Status:     ImportJobPending,
+		CreatedAt:  time.Now(),
+	}
+
+	s.mu.Lock()
+	s.pruneJobs()
+	s.jobs[job.ID] = job
+	snapshot := *job
+	s.mu.Unlock()
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), importTimeout)
+		defer cancel()
+		s.runImport(ctx, job, req.Name)
+	}()
+
+	return &snapshot, nil
+}
+
+// GetImportJob returns the current state of one of the user's import jobs.
+func (s *playlistImportService) GetImportJob(userID uint, jobID string) (*PlaylistImportJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[jobID]
+	if !ok || job.UserID != userID {
+		return nil, ErrImportJobNotFound
+	}
+	snapshot := *job
+	return &snapshot, nil
+}
+
+// runImport fetches the source's tracks, stores them and creates the playlist, recording the outcome on job.
+func (s *playlistImportService) runImport(ctx context.Context, job *PlaylistImportJob, name string) {
+	s.updateJob(job, func(j *PlaylistImportJob) { j.Status = ImportJobRunning })
+
+	playlistID, err := s.importCollection(ctx, job, name)
+
+	s.updateJob(job, func(j *PlaylistImportJob) {
+		finishedAt := time.Now()
+		j.FinishedAt = &finishedAt
+		if err != nil {
+			j.Status = ImportJobFailed
+			j.Error = err.Error()
+			return
+		}
+		j.Status = ImportJobCompleted
+		j.PlaylistID = playlistID
+	})
+
+	if err != nil {
+		log.Printf("Import of Spotify %s %s failed: %v", job.SourceType, job.SourceID, err)
+	}
+}
+
+func (s *playlistImportService) importCollection(ctx context.Context, job *PlaylistImportJob, name string) (uint, error) {
+	var collection *CatalogCollection
+	var err error
+	if job.SourceType == ImportSourceAlbum {
+		collection, err = s.catalog.GetAlbum(ctx, job.SourceID)
+	} else {
+		collection, err = s.catalog.GetPlaylist(ctx, job.SourceID)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	tracks := collection.Tracks
+	s.updateJob(job, func(j *PlaylistImportJob) { j.TotalTracks = len(tracks) })
+
+	refreshedAt := time.Now()
+	for start := 0; start < len(tracks); start += importBatchSize {
+		end := start + importBatchSize
+		if end > len(tracks) {
+			end = len(tracks)
+		}
+		batch := tracks[start:end]
+		for _, track := range batch {
+			track.RefreshedAt = &refreshedAt
+		}
+		if err := s.musicDAO.UpsertSongs(batch); err != nil {
+			return 0, err
+		}
+		s.updateJob(job, func(j *PlaylistImportJob) { j.ImportedTracks = end })
+	}
+
+	if name == "" {
+		name = collection.Name
+	}
+	playlist := &model.Playlist{
+		Name:             name,
+		UserID:           job.UserID,
+		PlaylistImageURL: collection.ImageURL,
+		Entries:          make([]model.PlaylistEntry, len(tracks)),
+	}
+	for i, track := range tracks {
+		playlist.Entries[i] = model.PlaylistEntry{SongID: track.ID, Position: i, AddedByID: job.UserID, AddedAt: refreshedAt}
+	}
+
+	if err := s.musicDAO.CreatePlaylist(playlist); err != nil {
+		return 0, err
+	}
+	recordActivity(s.musicDAO, model.Activity{ActorID: job.UserID, Type: model.ActivityPlaylistCreated, PlaylistID: playlist.ID})
+	return playlist.ID, nil
+}
+
+func (s *playlistImportService) updateJob(job *PlaylistImportJob, update func(*PlaylistImportJob)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	update(job)
+}
+
+// pruneJobs forgets jobs that finished more than importJobRetention ago. The caller must hold s.mu.
+func (s *playlistImportService) pruneJobs() {
+	cutoff := time.Now().Add(-importJobRetention)
+	for id, job := range s.jobs {
+		if job.FinishedAt != nil && job.FinishedAt.Before(cutoff) {
+			delete(s.jobs, id)
+		}
+	}
+}
+
+// parseSpotifySource extracts the source type and Spotify ID from a Spotify URL
+// (https://open.spotify.com/playlist/{id}), URI (spotify:album:{id}) or bare ID.
+func parseSpotifySource(source, sourceType string) (string, string, error) {
+	source = strings.TrimSpace(source)
+
+	var parsedType, spotifyID string
+	switch {
+	case strings.HasPrefix(source, "spotify:"):
+		parts := strings.Split(source, ":")
+		if len(parts) != 3 {
+			return "", "", ErrInvalidImportSource
+		}
+		parsedType, spotifyID = parts[1], parts[2]
+	case strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://"):
+		u, err := url.Parse(source)
+		if err != nil || u.Host != "open.spotify.com" {
+			return "", "", ErrInvalidImportSource
+		}
+		segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+		// Localized links are prefixed with the locale, e.g. /intl-pt/album/{id}.
+		if len(segments) > 0 && strings.HasPrefix(segments[0], "intl-") {
+			segments = segments[1:]
+		}
+		if len(segments) != 2 {
+			return "", "", ErrInvalidImportSource
+		}
+		parsedType, spotifyID = segments[0], segments[1]
+	default:
+		parsedType, spotifyID = sourceType, source
+	}
+
+	if sourceType != "" && sourceType != parsedType {
+		return "", "", fmt.Errorf("%w: source is a %s, not a %s", ErrInvalidImportSource, parsedType, sourceType)
+	}
+	if parsedType != ImportSourcePlaylist && parsedType != ImportSourceAlbum {
+		return "", "", ErrInvalidImportSource
+	}
+	if !spotifyIDPattern.MatchString(spotifyID) {
+		return "", "", ErrInvalidImportSource
+	}
+	return parsedType, spotifyID, nil
+}
+
+// newImportJobID returns a random, URL-safe job identifier.
+func newImportJobID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
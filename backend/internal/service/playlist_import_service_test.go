@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/dao/mocks"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/model"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/playlistfile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func TestParseSpotifySource(t *testing.T) {
+	tests := []struct {
+		source, sourceType string
+		wantType, wantID   string
+		wantErr            bool
+	}{
+		{source: "https://open.spotify.com/playlist/37i9dQZF1DXcBWIGoYBM5M?si=abc", wantType: "playlist", wantID: "37i9dQZF1DXcBWIGoYBM5M"},
+		{source: "https://open.spotify.com/intl-pt/album/4aawyAB9vmqN3uQ7FjRGTy", wantType: "album", wantID: "4aawyAB9vmqN3uQ7FjRGTy"},
+		{source: "spotify:album:4aawyAB9vmqN3uQ7FjRGTy", wantType: "album", wantID: "4aawyAB9vmqN3uQ7FjRGTy"},
+		{source: "37i9dQZF1DXcBWIGoYBM5M", sourceType: "playlist", wantType: "playlist", wantID: "37i9dQZF1DXcBWIGoYBM5M"},
+		{source: "37i9dQZF1DXcBWIGoYBM5M", wantErr: true},
+		{source: "spotify:album:4aawyAB9vmqN3uQ7FjRGTy", sourceType: "playlist", wantErr: true},
+		{source: "https://open.spotify.com/track/4aawyAB9vmqN3uQ7FjRGTy", wantErr: true},
+		{source: "https://example.com/playlist/37i9dQZF1DXcBWIGoYBM5M", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		sourceType, spotifyID, err := parseSpotifySource(tt.source, tt.sourceType)
+		if tt.wantErr {
+			assert.True(t, errors.Is(err, ErrInvalidImportSource), tt.source)
+			continue
+		}
+		assert.NoError(t, err, tt.source)
+		assert.Equal(t, tt.wantType, sourceType, tt.source)
+		assert.Equal(t, tt.wantID, spotifyID, tt.source)
+	}
+}
+
+func TestRunImport(t *testing.T) {
+	mockDAO := new(mocks.MusicDAO)
+	catalog := &fakeCatalogClient{collections: map[string]*CatalogCollection{
+		"album1": {Name: "Album", Tracks: []*model.Song{
+			{SpotifyID: "a", Name: "First"},
+			{SpotifyID: "b", Name: "Second"},
+			{SpotifyID: "a", Name: "First"},
+		}},
+	}}
+	is := NewPlaylistImportService(mockDAO, catalog, nil).(*playlistImportService)
+	job := &PlaylistImportJob{ID: "job", UserID: 7, SourceType: ImportSourceAlbum, SourceID: "album1"}
+	is.jobs[job.ID] = job
+
+	mockDAO.On("UpsertSongs", mock.AnythingOfType("[]*model.Song")).Run(func(args mock.Arguments) {
+		for i, song := range args.Get(0).([]*model.Song) {
+			song.ID = uint(i + 1)
+		}
+	}).Return(nil).Once()
+	mockDAO.On("CreatePlaylist", mock.MatchedBy(func(p *model.Playlist) bool {
+		return p.Name == "Album" && p.UserID == 7 && len(p.Entries) == 3 &&
+			p.Entries[0].SongID == 1 && p.Entries[1].SongID == 2 && p.Entries[2].Position == 2
+	})).Run(func(args mock.Arguments) {
+		args.Get(0).(*model.Playlist).ID = 42
+	}).Return(nil).Once()
+
+	mockDAO.On("CreateActivity", mock.MatchedBy(func(a *model.Activity) bool {
+		return a.Type == model.ActivityPlaylistCreated && a.ActorID == 7 && a.PlaylistID == 42
+	})).Return(nil).Once()
+
+	is.runImport(context.Background(), job, "")
+	mockDAO.AssertExpectations(t)
+
+	result, err := is.GetImportJob(7, "job")
+	assert.NoError(t, err)
+	assert.Equal(t, ImportJobCompleted, result.Status)
+	assert.Equal(t, uint(42), result.PlaylistID)
+	assert.Equal(t, 3, result.TotalTracks)
+	assert.Equal(t, 3, result.ImportedTracks)
+
+	_, err = is.GetImportJob(8, "job")
+	assert.Equal(t, ErrImportJobNotFound, err)
+}
+
+func TestRunImportCatalogError(t *testing.T) {
+	mockDAO := new(mocks.MusicDAO)
+	is := NewPlaylistImportService(mockDAO, &fakeCatalogClient{}, nil).(*playlistImportService)
+	job := &PlaylistImportJob{ID: "job", UserID: 7, SourceType: ImportSourcePlaylist, SourceID: "missing"}
+	is.jobs[job.ID] = job
+
+	is.runImport(context.Background(), job, "")
+
+	result, err := is.GetImportJob(7, "job")
+	assert.NoError(t, err)
+	assert.Equal(t, ImportJobFailed, result.Status)
+	assert.NotEmpty(t, result.Error)
+	mockDAO.AssertNotCalled(t, "CreatePlaylist", mock.Anything)
+}
+
+// fakeSongService answers catalog searches from a fixed list of songs.
+type fakeSongService struct {
+	SongService
+	results []*model.Song
+}
+
+func (f *fakeSongService) SearchSongsFromSpotify(trackName, artistName string) ([]*model.Song, error) {
+	return f.results, nil
+}
+
+func TestSongMatchConfidence(t *testing.T) {
+	song := &model.Song{Name: "Bohemian Rhapsody - Remastered 2011", Artist: "Queen"}
+
+	assert.InDelta(t, 1.0, songMatchConfidence("Bohemian Rhapsody", "Queen", song), 0.001)
+	assert.InDelta(t, 0.75, songMatchConfidence("bohemian rhapsody", "", song), 0.001)
+	assert.Greater(t, songMatchConfidence("Bohemian Rapsody", "Queen", song), matchThreshold)
+	assert.Less(t, songMatchConfidence("Under Pressure", "Queen", song), matchThreshold)
+	assert.Greater(t, songMatchConfidence("Bohemian Rhapsody", "Queen & David Bowie", song), matchThreshold)
+}
+
+func TestImportFile(t *testing.T) {
+	mockDAO := new(mocks.MusicDAO)
+	songs := &fakeSongService{results: []*model.Song{{SpotifyID: "cat1", Name: "Other Song", Artist: "Band"}}}
+	is := NewPlaylistImportService(mockDAO, &fakeCatalogClient{}, songs)
+
+	file := "#EXTM3U\n" +
+		"#EXTINF:-1,Queen - Bohemian Rhapsody (Live)\n/music/bohemian.mp3\n" +
+		"#EXTINF:-1,Band - Other Song\n/music/other.mp3\n" +
+		"https://open.spotify.com/track/4u7EnebtmKWzUH433cf5Qv\n" +
+		"#EXTINF:-1,Nobody - Unknown Track\n/music/unknown.mp3\n"
+
+	mockDAO.On("FindSongCandidates", "Bohemian Rhapsody", songCandidateLimit).
+		Return([]model.Song{{Model: gorm.Model{ID: 1}, Name: "Bohemian Rhapsody", Artist: "Queen"}}, nil)
+	mockDAO.On("FindSongCandidates", mock.AnythingOfType("string"), songCandidateLimit).Return([]model.Song{}, nil)
+	mockDAO.On("GetSongBySpotifyID", "cat1").Return(&model.Song{Model: gorm.Model{ID: 2}, SpotifyID: "cat1", Name: "Other Song", Artist: "Band"}, nil)
+	mockDAO.On("GetSongBySpotifyID", "4u7EnebtmKWzUH433cf5Qv").Return(&model.Song{Model: gorm.Model{ID: 3}, SpotifyID: "4u7EnebtmKWzUH433cf5Qv"}, nil)
+	mockDAO.On("CreatePlaylist", mock.MatchedBy(func(p *model.Playlist) bool {
+		return p.Name == "Mix" && p.UserID == 7 && len(p.Entries) == 3 &&
+			p.Entries[0].SongID == 1 && p.Entries[1].SongID == 2 && p.Entries[2].SongID == 3 && p.Entries[2].Position == 2
+	})).Run(func(args mock.Arguments) {
+		args.Get(0).(*model.Playlist).ID = 42
+	}).Return(nil).Once()
+
+	mockDAO.On("CreateActivity", mock.AnythingOfType("*model.Activity")).Return(nil).Once()
+
+	format, _ := playlistfile.LookupFormat("m3u")
+	report, err := is.ImportFile(7, "Mix", format, strings.NewReader(file))
+	assert.NoError(t, err)
+	mockDAO.AssertExpectations(t)
+
+	assert.Equal(t, uint(42), report.PlaylistID)
+	assert.Equal(t, 4, report.Total)
+	if assert.Len(t, report.Matched, 3) {
+		assert.Equal(t, MatchSourceLocal, report.Matched[0].Source)
+		assert.Equal(t, MatchSourceCatalog, report.Matched[1].Source)
+		assert.Equal(t, MatchSourceSpotifyID, report.Matched[2].Source)
+	}
+	if assert.Len(t, report.Unresolved, 1) {
+		assert.Equal(t, "Unknown Track", report.Unresolved[0].Title)
+		assert.Equal(t, 8, report.Unresolved[0].Line)
+	}
+}
+
+func TestImportFileEmpty(t *testing.T) {
+	mockDAO := new(mocks.MusicDAO)
+	is := NewPlaylistImportService(mockDAO, &fakeCatalogClient{}, &fakeSongService{})
+
+	format, _ := playlistfile.LookupFormat("m3u")
+	_, err := is.ImportFile(7, "Empty", format, strings.NewReader("#EXTM3U\n"))
+	assert.Equal(t, ErrEmptyPlaylistFile, err)
+	mockDAO.AssertNotCalled(t, "CreatePlaylist", mock.Anything)
+}
@@ -1,17 +1,53 @@
 package service
 
 import (
+	"errors"
 	"fmt"
+	"log"
+	"strings"
+	"time"
 
 	"github.com/kaiohenricunha/go-music-k8s/backend/internal/dao"
 	"github.com/kaiohenricunha/go-music-k8s/backend/internal/model"
 )
 
 type PlaylistService interface {
-	GetAllPlaylists() ([]model.Playlist, error)
+	CreatePlaylist(playlist *model.Playlist) error
+	GetAllPlaylists(userID uint) ([]model.Playlist, error)
 	GetPlaylistByID(playlistID string) (*model.Playlist, error)
-	AddSongToPlaylist(playlistID, songID string) error
-	RemoveSongFromPlaylist(playlistID, songID string) error
+	GetSharedPlaylist(token string) (*SharedPlaylist, error)
+	SetPlaylistVisibility(playlistID string, userID uint, visibility string) (*model.Playlist, error)
+	RotateShareToken(playlistID string, userID uint) (*model.Playlist, error)
+	SetSmartRules(playlistID string, userID uint, rules *model.SmartRules) (*model.Playlist, error)
+	RefreshSmartPlaylist(playlistID string, userID uint) (*model.Playlist, error)
+	GetPlaylistHistory(playlistID string, userID uint, before, limit int) (*PlaylistHistory, error)
+	DiffPlaylistRevisions(playlistID string, userID uint, from, to int) (*RevisionDiff, error)
+	RestorePlaylistRevision(playlistID string, userID uint, number int) (*model.Playlist, error)
+	AddSongToPlaylist(playlistID, songID string, position int, addedBy uint) (*model.PlaylistEntry, error)
+	RemoveSongFromPlaylist(playlistID, songID string, removedBy uint) error
+	AddSongsToPlaylist(playlistID string, refs []model.SongRef, addedBy uint) ([]model.SongRefResult, error)
+	RemoveSongsFromPlaylist(playlistID string, refs []model.SongRef, removedBy uint) ([]model.SongRefResult, error)
+	RemovePlaylistEntry(playlistID string, entryID uint, removedBy uint) error
+	MovePlaylistEntries(playlistID string, move PlaylistMove, movedBy uint) error
+	ReplacePlaylistSongs(playlistID string, songIDs []uint, addedBy uint) error
+	CopyPlaylist(playlistID string, userID uint, name, visibility string) (*model.Playlist, error)
+	MergePlaylists(userID uint, merge PlaylistMerge) (*model.Playlist, error)
+	DedupePlaylist(playlistID string, userID uint, by string) (*DedupeResult, error)
+
+	AuthorizePlaylist(playlistID string, userID uint, permission PlaylistPermission) error
+	InviteCollaborator(playlistID string, inviterID uint, username, role string) (*model.PlaylistCollaborator, error)
+	RespondToInvite(playlistID string, userID uint, accept bool) (*model.PlaylistCollaborator, error)
+	GetPlaylistCollaborators(playlistID string) ([]model.PlaylistCollaborator, error)
+	RemoveCollaborator(playlistID string, actorID, userID uint) error
+	GetPendingInvites(userID uint) ([]model.PlaylistCollaborator, error)
+}
+
+// PlaylistMove moves RangeLength entries starting at RangeStart so that they end up before the
+// entry currently at InsertBefore. Positions are zero-based.
+type PlaylistMove struct {
+	RangeStart   int `json:"range_start"`
+	RangeLength  int `json:"range_length"`
+	InsertBefore int `json:"insert_before"`
 }
 
 type playlistService struct {
@@ -23,11 +59,72 @@ func NewPlaylistService(musicDAO dao.MusicDAO) PlaylistService {
 }
 
 var (
-	ErrPlaylistNotFound = fmt.Errorf("playlist not found")
+	ErrPlaylistNotFound      = dao.ErrPlaylistNotFound
+	ErrPlaylistEntryNotFound = dao.ErrPlaylistEntryNotFound
+	ErrInvalidPosition       = dao.ErrInvalidPosition
+	ErrInvalidSongRefs       = errors.New("each song must be given by exactly one of song_id or spotify_id")
+	ErrInvalidPlaylistName   = errors.New("playlist name is required")
+	ErrTooManySongRefs       = fmt.Errorf("at most %d songs can be changed per request", maxSongRefsPerRequest)
 )
 
-func (s *playlistService) GetAllPlaylists() ([]model.Playlist, error) {
-	return s.musicDAO.GetAllPlaylists()
+// maxSongRefsPerRequest caps the number of songs in a bulk playlist operation.
+const maxSongRefsPerRequest = 100
+
+// CreatePlaylist creates a playlist owned by playlist.UserID. Playlists are public unless another
+// visibility is given. Static playlists start empty and smart playlists with the songs their rules select.
+func (s *playlistService) CreatePlaylist(playlist *model.Playlist) error {
+	if err := s.insertPlaylist(playlist, nil); err != nil {
+		return err
+	}
+	// The refresh worker retries playlists that could not be materialized.
+	if playlist.SmartRules != nil {
+		if err := materializeSmartPlaylist(s.musicDAO, playlist, time.Now(), playlist.UserID); err != nil {
+			log.Printf("Failed to materialize smart playlist %d: %v", playlist.ID, err)
+		}
+	}
+	return nil
+}
+
+// insertPlaylist validates a new playlist and creates it with the given songs, in order.
+func (s *playlistService) insertPlaylist(playlist *model.Playlist, songIDs []uint) error {
+	playlist.Name = strings.TrimSpace(playlist.Name)
+	if playlist.Name == "" {
+		return ErrInvalidPlaylistName
+	}
+	switch playlist.Visibility {
+	case "":
+		playlist.Visibility = model.VisibilityPublic
+	case model.VisibilityPublic, model.VisibilityPrivate:
+	case model.VisibilityUnlisted:
+		token, err := newShareToken()
+		if err != nil {
+			return err
+		}
+		playlist.ShareToken = token
+	default:
+		return ErrInvalidVisibility
+	}
+	if playlist.SmartRules != nil {
+		if err := validateSmartRules(playlist.SmartRules); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now()
+	playlist.Entries = make([]model.PlaylistEntry, len(songIDs))
+	for i, songID := range songIDs {
+		playlist.Entries[i] = model.PlaylistEntry{SongID: songID, Position: i, AddedByID: playlist.UserID, AddedAt: now}
+	}
+	if err := s.musicDAO.CreatePlaylist(playlist); err != nil {
+		return err
+	}
+	recordActivity(s.musicDAO, model.Activity{ActorID: playlist.UserID, Type: model.ActivityPlaylistCreated, PlaylistID: playlist.ID})
+	return nil
+}
+
+// GetAllPlaylists lists the playlists the user may see: public ones, their own and those they collaborate on.
+func (s *playlistService) GetAllPlaylists(userID uint) ([]model.Playlist, error) {
+	return s.musicDAO.GetVisiblePlaylists(userID)
 }
 
 func (s *playlistService) GetPlaylistByID(playlistID string) (*model.Playlist, error) {
@@ -43,11 +140,92 @@ func (s *playlistService) GetPlaylistByID(playlistID string) (*model.Playlist, e
 	return playlist, nil
 }
 
-// AddSongToPlaylist adds a song to a playlist.
-func (s *playlistService) AddSongToPlaylist(playlistID, songID string) error {
-	return s.musicDAO.AddSongToPlaylist(playlistID, songID)
+// AddSongToPlaylist inserts a song at the given position of a playlist, or appends it when the position is negative.
+func (s *playlistService) AddSongToPlaylist(playlistID, songID string, position int, addedBy uint) (*model.PlaylistEntry, error) {
+	entry, err := s.musicDAO.AddSongToPlaylist(playlistID, songID, position, addedBy)
+	if err != nil {
+		return nil, err
+	}
+	recordActivity(s.musicDAO, model.Activity{
+		ActorID: addedBy, Type: model.ActivitySongsAdded, PlaylistID: entry.PlaylistID, SongID: entry.SongID, SongCount: 1,
+	})
+	return entry, nil
+}
+
+// RemoveSongFromPlaylist removes every occurrence of a song from a playlist.
+func (s *playlistService) RemoveSongFromPlaylist(playlistID, songID string, removedBy uint) error {
+	return s.musicDAO.RemoveSongFromPlaylist(playlistID, songID, removedBy)
+}
+
+// AddSongsToPlaylist appends several songs to a playlist at once, skipping unknown songs and
+// songs that are already in the playlist.
+func (s *playlistService) AddSongsToPlaylist(playlistID string, refs []model.SongRef, addedBy uint) ([]model.SongRefResult, error) {
+	if err := validateSongRefs(refs); err != nil {
+		return nil, err
+	}
+	results, err := s.musicDAO.AddSongsToPlaylist(playlistID, refs, addedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	activity := model.Activity{ActorID: addedBy, Type: model.ActivitySongsAdded}
+	for _, result := range results {
+		if result.Status == model.SongRefAdded {
+			if activity.SongCount == 0 {
+				activity.SongID = result.SongID
+			}
+			activity.SongCount++
+		}
+	}
+	if activity.SongCount > 0 {
+		if activity.PlaylistID, err = parsePlaylistID(playlistID); err == nil {
+			recordActivity(s.musicDAO, activity)
+		}
+	}
+	return results, nil
+}
+
+// RemoveSongsFromPlaylist removes several songs from a playlist at once.
+func (s *playlistService) RemoveSongsFromPlaylist(playlistID string, refs []model.SongRef, removedBy uint) ([]model.SongRefResult, error) {
+	if err := validateSongRefs(refs); err != nil {
+		return nil, err
+	}
+	return s.musicDAO.RemoveSongsFromPlaylist(playlistID, refs, removedBy)
+}
+
+// validateSongRefs checks the size of a bulk request and that every song is identified exactly once.
+func validateSongRefs(refs []model.SongRef) error {
+	if len(refs) == 0 {
+		return ErrInvalidSongRefs
+	}
+	if len(refs) > maxSongRefsPerRequest {
+		return ErrTooManySongRefs
+	}
+	for _, ref := range refs {
+		if (ref.SongID == 0) == (ref.SpotifyID == "") {
+			return ErrInvalidSongRefs
+		}
+	}
+	return nil
+}
+
+// RemovePlaylistEntry removes a single entry from a playlist.
+func (s *playlistService) RemovePlaylistEntry(playlistID string, entryID uint, removedBy uint) error {
+	return s.musicDAO.RemovePlaylistEntry(playlistID, entryID, removedBy)
+}
+
+// MovePlaylistEntries moves a range of entries to another position in the playlist.
+func (s *playlistService) MovePlaylistEntries(playlistID string, move PlaylistMove, movedBy uint) error {
+	if move.RangeLength == 0 {
+		move.RangeLength = 1
+	}
+	if move.RangeStart < 0 || move.RangeLength < 0 || move.InsertBefore < 0 {
+		return ErrInvalidPosition
+	}
+	return s.musicDAO.MovePlaylistEntries(playlistID, move.RangeStart, move.RangeLength, move.InsertBefore, movedBy)
 }
 
-func (s *playlistService) RemoveSongFromPlaylist(playlistID, songID string) error {
-	return s.musicDAO.RemoveSongFromPlaylist(playlistID, songID)
+// ReplacePlaylistSongs replaces the contents of a playlist with the given songs, in order.
+func (s *playlistService) ReplacePlaylistSongs(playlistID string, songIDs []uint, addedBy uint) error {
+	return s.musicDAO.ReplacePlaylistEntries(playlistID, songIDs, addedBy)
 }
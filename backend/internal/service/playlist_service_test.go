@@ -6,6 +6,7 @@ import (
 	"github.com/kaiohenricunha/go-music-k8s/backend/internal/dao/mocks"
 	"github.com/kaiohenricunha/go-music-k8s/backend/internal/model"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestGetAllPlaylists(t *testing.T) {
@@ -13,9 +14,9 @@ func TestGetAllPlaylists(t *testing.T) {
 	ps := NewPlaylistService(mockDAO)
 	mockPlaylists := []model.Playlist{{Name: "Chill Vibes"}, {Name: "Workout"}}
 
-	mockDAO.On("GetAllPlaylists").Return(mockPlaylists, nil)
+	mockDAO.On("GetVisiblePlaylists", uint(1)).Return(mockPlaylists, nil)
 
-	playlists, err := ps.GetAllPlaylists()
+	playlists, err := ps.GetAllPlaylists(1)
 	assert.NoError(t, err)
 	assert.Equal(t, mockPlaylists, playlists)
 }
@@ -40,26 +41,115 @@ func TestAddSongToPlaylist(t *testing.T) {
 	mockDAO := new(mocks.MusicDAO)
 	ps := NewPlaylistService(mockDAO)
 
-	mockDAO.On("AddSongToPlaylist", "1", "1").Return(nil)
-	mockDAO.On("AddSongToPlaylist", "1", "2").Return(ErrPlaylistNotFound)
+	mockEntry := &model.PlaylistEntry{PlaylistID: 1, SongID: 1, Position: 0, AddedByID: 3}
 
-	err := ps.AddSongToPlaylist("1", "1")
+	mockDAO.On("AddSongToPlaylist", "1", "1", 0, uint(3)).Return(mockEntry, nil)
+	mockDAO.On("AddSongToPlaylist", "1", "2", -1, uint(3)).Return(nil, ErrPlaylistNotFound)
+	mockDAO.On("CreateActivity", mock.MatchedBy(func(a *model.Activity) bool {
+		return a.Type == model.ActivitySongsAdded && a.PlaylistID == 1 && a.SongID == 1 && a.SongCount == 1
+	})).Return(nil).Once()
+
+	entry, err := ps.AddSongToPlaylist("1", "1", 0, 3)
 	assert.NoError(t, err)
+	assert.Equal(t, mockEntry, entry)
 
-	err = ps.AddSongToPlaylist("1", "2")
+	_, err = ps.AddSongToPlaylist("1", "2", -1, 3)
 	assert.Equal(t, ErrPlaylistNotFound, err)
+	mockDAO.AssertExpectations(t)
 }
 
 func TestRemoveSongFromPlaylist(t *testing.T) {
 	mockDAO := new(mocks.MusicDAO)
 	ps := NewPlaylistService(mockDAO)
 
-	mockDAO.On("RemoveSongFromPlaylist", "1", "1").Return(nil)
-	mockDAO.On("RemoveSongFromPlaylist", "1", "2").Return(ErrPlaylistNotFound)
+	mockDAO.On("RemoveSongFromPlaylist", "1", "1", uint(1)).Return(nil)
+	mockDAO.On("RemoveSongFromPlaylist", "1", "2", uint(1)).Return(ErrPlaylistNotFound)
+
+	err := ps.RemoveSongFromPlaylist("1", "1", 1)
+	assert.NoError(t, err)
+
+	err = ps.RemoveSongFromPlaylist("1", "2", 1)
+	assert.Equal(t, ErrPlaylistNotFound, err)
+}
+
+func TestMovePlaylistEntries(t *testing.T) {
+	mockDAO := new(mocks.MusicDAO)
+	ps := NewPlaylistService(mockDAO)
+
+	mockDAO.On("MovePlaylistEntries", "1", 2, 1, 0, uint(1)).Return(nil)
+	mockDAO.On("MovePlaylistEntries", "1", 0, 2, 9, uint(1)).Return(ErrInvalidPosition)
+
+	// A zero range length moves a single entry.
+	err := ps.MovePlaylistEntries("1", PlaylistMove{RangeStart: 2, InsertBefore: 0}, 1)
+	assert.NoError(t, err)
+
+	err = ps.MovePlaylistEntries("1", PlaylistMove{RangeStart: 0, RangeLength: 2, InsertBefore: 9}, 1)
+	assert.Equal(t, ErrInvalidPosition, err)
+
+	err = ps.MovePlaylistEntries("1", PlaylistMove{RangeStart: -1, InsertBefore: 0}, 1)
+	assert.Equal(t, ErrInvalidPosition, err)
+	mockDAO.AssertNumberOfCalls(t, "MovePlaylistEntries", 2)
+}
+
+func TestReplacePlaylistSongs(t *testing.T) {
+	mockDAO := new(mocks.MusicDAO)
+	ps := NewPlaylistService(mockDAO)
+
+	mockDAO.On("ReplacePlaylistEntries", "1", []uint{3, 1, 3}, uint(2)).Return(nil)
+	mockDAO.On("ReplacePlaylistEntries", "1", []uint{99}, uint(2)).Return(ErrSongNotFound)
+
+	err := ps.ReplacePlaylistSongs("1", []uint{3, 1, 3}, 2)
+	assert.NoError(t, err)
+
+	err = ps.ReplacePlaylistSongs("1", []uint{99}, 2)
+	assert.Equal(t, ErrSongNotFound, err)
+}
+
+func TestAddSongsToPlaylist(t *testing.T) {
+	mockDAO := new(mocks.MusicDAO)
+	ps := NewPlaylistService(mockDAO)
+
+	refs := []model.SongRef{{SongID: 1}, {SpotifyID: "4uLU6hMCjMI75M1A2tKUQC"}}
+	mockResults := []model.SongRefResult{
+		{SongRef: refs[0], Status: model.SongRefAdded, EntryID: 10},
+		{SongRef: refs[1], Status: model.SongRefAlreadyPresent},
+	}
+	mockDAO.On("AddSongsToPlaylist", "1", refs, uint(2)).Return(mockResults, nil)
+	mockDAO.On("CreateActivity", mock.MatchedBy(func(a *model.Activity) bool {
+		return a.Type == model.ActivitySongsAdded && a.PlaylistID == 1 && a.SongID == 1 && a.SongCount == 1
+	})).Return(nil).Once()
+
+	results, err := ps.AddSongsToPlaylist("1", refs, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, mockResults, results)
+
+	_, err = ps.AddSongsToPlaylist("1", nil, 2)
+	assert.Equal(t, ErrInvalidSongRefs, err)
+
+	_, err = ps.AddSongsToPlaylist("1", []model.SongRef{{SongID: 1, SpotifyID: "x"}}, 2)
+	assert.Equal(t, ErrInvalidSongRefs, err)
+
+	_, err = ps.AddSongsToPlaylist("1", make([]model.SongRef, maxSongRefsPerRequest+1), 2)
+	assert.Equal(t, ErrTooManySongRefs, err)
+	mockDAO.AssertNumberOfCalls(t, "AddSongsToPlaylist", 1)
+}
+
+func TestRemoveSongsFromPlaylist(t *testing.T) {
+	mockDAO := new(mocks.MusicDAO)
+	ps := NewPlaylistService(mockDAO)
+
+	refs := []model.SongRef{{SongID: 1}, {SongID: 9}}
+	mockResults := []model.SongRefResult{
+		{SongRef: refs[0], Status: model.SongRefRemoved},
+		{SongRef: refs[1], Status: model.SongRefNotFound},
+	}
+	mockDAO.On("RemoveSongsFromPlaylist", "1", refs, uint(1)).Return(mockResults, nil)
+	mockDAO.On("RemoveSongsFromPlaylist", "2", refs, uint(1)).Return(nil, ErrPlaylistNotFound)
 
-	err := ps.RemoveSongFromPlaylist("1", "1")
+	results, err := ps.RemoveSongsFromPlaylist("1", refs, 1)
 	assert.NoError(t, err)
+	assert.Equal(t, mockResults, results)
 
-	err = ps.RemoveSongFromPlaylist("1", "2")
+	_, err = ps.RemoveSongsFromPlaylist("2", refs, 1)
 	assert.Equal(t, ErrPlaylistNotFound, err)
 }
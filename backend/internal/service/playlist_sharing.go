@@ -0,0 +1,143 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/model"
+)
+
+var (
+	ErrInvalidVisibility = errors.New("visibility must be public, unlisted or private")
+	ErrPlaylistNotShared = errors.New("only unlisted playlists have a share link")
+)
+
+// shareTokenBytes is the amount of randomness in a share token.
+const shareTokenBytes = 24
+
+// SharedPlaylist is what anyone with the share link of an unlisted playlist may see. It leaves
+// out the sequential IDs of the playlist, its owner, its entries and its raters, and the share
+// token itself.
+type SharedPlaylist struct {
+	Name          string       `json:"playlist_name"`
+	ImageURL      string       `json:"playlist_image_url,omitempty"`
+	Owner         string       `json:"owner"` // The owner's username.
+	Songs         []SharedSong `json:"songs"`
+	AverageRating *float64     `json:"average_rating"` // Nil until someone rates the playlist.
+	RatingCount   int          `json:"rating_count"`
+}
+
+// SharedSong is a song of a SharedPlaylist.
+type SharedSong struct {
+	Name          string `json:"name"`
+	Artist        string `json:"artist"`
+	AlbumName     string `json:"album_name"`
+	AlbumImageURL string `json:"album_image_url,omitempty"`
+	PreviewURL    string `json:"preview_url,omitempty"`
+	ExternalURL   string `json:"external_url,omitempty"`
+}
+
+// GetSharedPlaylist resolves the share token of an unlisted playlist.
+func (s *playlistService) GetSharedPlaylist(token string) (*SharedPlaylist, error) {
+	if token == "" {
+		return nil, ErrPlaylistNotFound
+	}
+	playlist, err := s.musicDAO.GetPlaylistByShareToken(token)
+	if err != nil {
+		return nil, err
+	}
+	owner, err := s.musicDAO.GetUserByID(playlist.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	shared := &SharedPlaylist{
+		Name:        playlist.Name,
+		ImageURL:    playlist.PlaylistImageURL,
+		Owner:       owner.Username,
+		Songs:       make([]SharedSong, len(playlist.Songs)),
+		RatingCount: len(playlist.Ratings),
+	}
+	for i, song := range playlist.Songs {
+		shared.Songs[i] = SharedSong{
+			Name:          song.Name,
+			Artist:        song.Artist,
+			AlbumName:     song.AlbumName,
+			AlbumImageURL: song.AlbumImageURL,
+			PreviewURL:    song.PreviewURL,
+			ExternalURL:   song.ExternalURL,
+		}
+	}
+	if len(playlist.Ratings) > 0 {
+		total := 0
+		for _, rating := range playlist.Ratings {
+			total += rating.Score
+		}
+		average := float64(total) / float64(len(playlist.Ratings))
+		shared.AverageRating = &average
+	}
+	return shared, nil
+}
+
+// SetPlaylistVisibility changes who can see a playlist. Making it unlisted creates a share link;
+// making it public or private revokes the link.
+func (s *playlistService) SetPlaylistVisibility(playlistID string, userID uint, visibility string) (*model.Playlist, error) {
+	if visibility != model.VisibilityPublic && visibility != model.VisibilityUnlisted && visibility != model.VisibilityPrivate {
+		return nil, ErrInvalidVisibility
+	}
+	playlist, err := s.musicDAO.GetPlaylistInfo(playlistID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(playlist, userID, PermissionManage); err != nil {
+		return nil, err
+	}
+
+	playlist.Visibility = visibility
+	switch {
+	case visibility != model.VisibilityUnlisted:
+		playlist.ShareToken = nil
+	case playlist.ShareToken == nil:
+		if playlist.ShareToken, err = newShareToken(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.musicDAO.UpdatePlaylistSharing(playlist, userID); err != nil {
+		return nil, err
+	}
+	return playlist, nil
+}
+
+// RotateShareToken replaces the share link of an unlisted playlist, so that the old link stops working.
+func (s *playlistService) RotateShareToken(playlistID string, userID uint) (*model.Playlist, error) {
+	playlist, err := s.musicDAO.GetPlaylistInfo(playlistID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(playlist, userID, PermissionManage); err != nil {
+		return nil, err
+	}
+	if playlist.Visibility != model.VisibilityUnlisted {
+		return nil, ErrPlaylistNotShared
+	}
+
+	if playlist.ShareToken, err = newShareToken(); err != nil {
+		return nil, err
+	}
+	if err := s.musicDAO.UpdatePlaylistSharing(playlist, userID); err != nil {
+		return nil, err
+	}
+	return playlist, nil
+}
+
+// newShareToken returns a random, URL-safe share token.
+func newShareToken() (*string, error) {
+	b := make([]byte, shareTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+	return &token, nil
+}
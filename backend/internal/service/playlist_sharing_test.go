@@ -0,0 +1,79 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/dao/mocks"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func TestSetPlaylistVisibility(t *testing.T) {
+	mockDAO := new(mocks.MusicDAO)
+	ps := NewPlaylistService(mockDAO)
+	playlist := &model.Playlist{Model: gorm.Model{ID: 1}, UserID: 10, Visibility: model.VisibilityPublic}
+
+	mockDAO.On("GetPlaylistInfo", "1").Return(playlist, nil)
+	mockDAO.On("GetPlaylistCollaborator", uint(1), uint(20)).Return(nil, ErrCollaboratorNotFound)
+	mockDAO.On("UpdatePlaylistSharing", mock.AnythingOfType("*model.Playlist"), uint(10)).Return(nil)
+
+	updated, err := ps.SetPlaylistVisibility("1", 10, model.VisibilityUnlisted)
+	assert.NoError(t, err)
+	assert.Equal(t, model.VisibilityUnlisted, updated.Visibility)
+	if assert.NotNil(t, updated.ShareToken) {
+		assert.Len(t, *updated.ShareToken, 32)
+	}
+
+	// Rotating replaces the token.
+	previous := *updated.ShareToken
+	updated, err = ps.RotateShareToken("1", 10)
+	assert.NoError(t, err)
+	assert.NotEqual(t, previous, *updated.ShareToken)
+
+	// Leaving unlisted revokes the link.
+	updated, err = ps.SetPlaylistVisibility("1", 10, model.VisibilityPrivate)
+	assert.NoError(t, err)
+	assert.Nil(t, updated.ShareToken)
+
+	_, err = ps.RotateShareToken("1", 10)
+	assert.Equal(t, ErrPlaylistNotShared, err)
+
+	_, err = ps.SetPlaylistVisibility("1", 10, "friends")
+	assert.Equal(t, ErrInvalidVisibility, err)
+
+	_, err = ps.SetPlaylistVisibility("1", 20, model.VisibilityPublic)
+	assert.Equal(t, ErrPlaylistNotFound, err)
+	mockDAO.AssertNumberOfCalls(t, "UpdatePlaylistSharing", 3)
+}
+
+func TestGetSharedPlaylist(t *testing.T) {
+	mockDAO := new(mocks.MusicDAO)
+	ps := NewPlaylistService(mockDAO)
+	mockPlaylist := &model.Playlist{
+		Model: gorm.Model{ID: 3}, Name: "Road Trip", UserID: 10, Visibility: model.VisibilityUnlisted,
+		Songs:   []model.Song{{Model: gorm.Model{ID: 5}, Name: "Song", Artist: "Artist"}},
+		Ratings: []model.Rating{{Score: 4}, {Score: 5}},
+	}
+
+	mockDAO.On("GetPlaylistByShareToken", "token").Return(mockPlaylist, nil)
+	mockDAO.On("GetPlaylistByShareToken", "stale").Return(nil, ErrPlaylistNotFound)
+	mockDAO.On("GetUserByID", uint(10)).Return(&model.User{Model: gorm.Model{ID: 10}, Username: "alice"}, nil)
+
+	playlist, err := ps.GetSharedPlaylist("token")
+	assert.NoError(t, err)
+	assert.Equal(t, "Road Trip", playlist.Name)
+	assert.Equal(t, "alice", playlist.Owner)
+	assert.Equal(t, []SharedSong{{Name: "Song", Artist: "Artist"}}, playlist.Songs)
+	if assert.NotNil(t, playlist.AverageRating) {
+		assert.Equal(t, 4.5, *playlist.AverageRating)
+	}
+	assert.Equal(t, 2, playlist.RatingCount)
+
+	_, err = ps.GetSharedPlaylist("stale")
+	assert.Equal(t, ErrPlaylistNotFound, err)
+
+	_, err = ps.GetSharedPlaylist("")
+	assert.Equal(t, ErrPlaylistNotFound, err)
+}
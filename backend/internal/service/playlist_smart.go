@@ -0,0 +1,117 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/dao"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/model"
+)
+
+var (
+	ErrInvalidSmartRules     = errors.New("invalid smart playlist rules")
+	ErrSmartPlaylistReadOnly = errors.New("the songs of a smart playlist are computed from its rules and cannot be edited")
+	ErrNotSmartPlaylist      = errors.New("playlist is not a smart playlist")
+)
+
+const (
+	defaultSmartPlaylistLimit = 100
+	maxSmartPlaylistLimit     = 500
+)
+
+// SetSmartRules replaces the rules of a playlist and recomputes its songs. Nil rules turn it into
+// a static playlist that keeps its current songs.
+func (s *playlistService) SetSmartRules(playlistID string, userID uint, rules *model.SmartRules) (*model.Playlist, error) {
+	if rules != nil {
+		if err := validateSmartRules(rules); err != nil {
+			return nil, err
+		}
+	}
+	playlist, err := s.musicDAO.GetPlaylistInfo(playlistID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(playlist, userID, PermissionEdit); err != nil {
+		return nil, err
+	}
+
+	playlist.SmartRules = rules
+	if err := s.musicDAO.UpdatePlaylistSmartRules(playlist, userID); err != nil {
+		return nil, err
+	}
+	if rules != nil {
+		if err := materializeSmartPlaylist(s.musicDAO, playlist, time.Now(), userID); err != nil {
+			return nil, err
+		}
+	}
+	return s.musicDAO.GetPlaylistByID(playlistID)
+}
+
+// RefreshSmartPlaylist recomputes the songs of a smart playlist from its rules.
+func (s *playlistService) RefreshSmartPlaylist(playlistID string, userID uint) (*model.Playlist, error) {
+	playlist, err := s.musicDAO.GetPlaylistInfo(playlistID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(playlist, userID, PermissionEdit); err != nil {
+		return nil, err
+	}
+	if playlist.SmartRules == nil {
+		return nil, ErrNotSmartPlaylist
+	}
+
+	if err := materializeSmartPlaylist(s.musicDAO, playlist, time.Now(), userID); err != nil {
+		return nil, err
+	}
+	return s.musicDAO.GetPlaylistByID(playlistID)
+}
+
+// validateSmartRules normalizes rules and checks that they select a bounded set of songs.
+func validateSmartRules(rules *model.SmartRules) error {
+	artists := make([]string, 0, len(rules.Artists))
+	for _, artist := range rules.Artists {
+		if artist = strings.TrimSpace(artist); artist != "" {
+			artists = append(artists, artist)
+		}
+	}
+	rules.Artists = artists
+	rules.Album = strings.TrimSpace(rules.Album)
+
+	if len(rules.Artists) == 0 && rules.Album == "" && rules.AddedAfter == nil && rules.RatingAbove == nil &&
+		rules.MinPlayCount == nil && !rules.LikedByMe {
+		return fmt.Errorf("%w: at least one rule is required", ErrInvalidSmartRules)
+	}
+	if rules.RatingAbove != nil && (*rules.RatingAbove < 0 || *rules.RatingAbove >= maxRatingScore) {
+		return fmt.Errorf("%w: rating_above must be at least 0 and below %d", ErrInvalidSmartRules, maxRatingScore)
+	}
+	if rules.MinPlayCount != nil && *rules.MinPlayCount < 0 {
+		return fmt.Errorf("%w: min_play_count must not be negative", ErrInvalidSmartRules)
+	}
+	if rules.Limit < 0 || rules.Limit > maxSmartPlaylistLimit {
+		return fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidSmartRules, maxSmartPlaylistLimit)
+	}
+	if rules.Limit == 0 {
+		rules.Limit = defaultSmartPlaylistLimit
+	}
+	return nil
+}
+
+// materializeSmartPlaylist replaces the entries of a smart playlist with the songs its rules
+// select, on behalf of refreshedBy, or of the server when zero.
+func materializeSmartPlaylist(musicDAO dao.MusicDAO, playlist *model.Playlist, now time.Time, refreshedBy uint) error {
+	limit := playlist.SmartRules.Limit
+	if limit <= 0 {
+		limit = defaultSmartPlaylistLimit
+	}
+	songIDs, err := musicDAO.FindSongsMatchingRules(playlist.UserID, *playlist.SmartRules, limit)
+	if err != nil {
+		return err
+	}
+	if err := musicDAO.MaterializeSmartPlaylist(playlist.ID, songIDs, now, refreshedBy); err != nil {
+		return err
+	}
+	playlist.MaterializedAt = &now
+	return nil
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/dao/mocks"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func TestValidateSmartRules(t *testing.T) {
+	rating, negative := 5.0, int64(-1)
+
+	rules := &model.SmartRules{Artists: []string{" Queen ", ""}}
+	assert.NoError(t, validateSmartRules(rules))
+	assert.Equal(t, []string{"Queen"}, rules.Artists)
+	assert.Equal(t, defaultSmartPlaylistLimit, rules.Limit)
+
+	for _, rules := range []*model.SmartRules{
+		{},
+		{Artists: []string{" "}},
+		{LikedByMe: true, RatingAbove: &rating},
+		{LikedByMe: true, MinPlayCount: &negative},
+		{LikedByMe: true, Limit: maxSmartPlaylistLimit + 1},
+	} {
+		assert.True(t, errors.Is(validateSmartRules(rules), ErrInvalidSmartRules), "%+v", rules)
+	}
+}
+
+func TestCreateSmartPlaylist(t *testing.T) {
+	mockDAO := new(mocks.MusicDAO)
+	ps := NewPlaylistService(mockDAO)
+	rules := &model.SmartRules{LikedByMe: true}
+
+	mockDAO.On("CreatePlaylist", mock.AnythingOfType("*model.Playlist")).Run(func(args mock.Arguments) {
+		args.Get(0).(*model.Playlist).ID = 1
+	}).Return(nil).Once()
+	mockDAO.On("CreateActivity", mock.AnythingOfType("*model.Activity")).Return(nil).Once()
+	mockDAO.On("FindSongsMatchingRules", uint(10), model.SmartRules{Artists: []string{}, LikedByMe: true, Limit: defaultSmartPlaylistLimit}, defaultSmartPlaylistLimit).
+		Return([]uint{4, 2}, nil).Once()
+	mockDAO.On("MaterializeSmartPlaylist", uint(1), []uint{4, 2}, mock.AnythingOfType("time.Time"), uint(10)).Return(nil).Once()
+
+	playlist := &model.Playlist{Name: "Favorites", UserID: 10, SmartRules: rules}
+	assert.NoError(t, ps.CreatePlaylist(playlist))
+	assert.NotNil(t, playlist.MaterializedAt)
+	mockDAO.AssertExpectations(t)
+
+	err := ps.CreatePlaylist(&model.Playlist{Name: "Everything", UserID: 10, SmartRules: &model.SmartRules{}})
+	assert.True(t, errors.Is(err, ErrInvalidSmartRules))
+}
+
+func TestSetSmartRules(t *testing.T) {
+	mockDAO := new(mocks.MusicDAO)
+	ps := NewPlaylistService(mockDAO)
+	playlist := &model.Playlist{Model: gorm.Model{ID: 1}, UserID: 10, Visibility: model.VisibilityPublic}
+
+	mockDAO.On("GetPlaylistInfo", "1").Return(playlist, nil)
+	mockDAO.On("GetPlaylistCollaborator", uint(1), uint(20)).Return(nil, ErrCollaboratorNotFound)
+	mockDAO.On("UpdatePlaylistSmartRules", playlist, uint(10)).Return(nil)
+	mockDAO.On("FindSongsMatchingRules", uint(10), mock.AnythingOfType("model.SmartRules"), 10).Return([]uint{3}, nil).Once()
+	mockDAO.On("MaterializeSmartPlaylist", uint(1), []uint{3}, mock.AnythingOfType("time.Time"), uint(10)).Return(nil).Once()
+	mockDAO.On("GetPlaylistByID", "1").Return(playlist, nil)
+
+	_, err := ps.RefreshSmartPlaylist("1", 10)
+	assert.Equal(t, ErrNotSmartPlaylist, err)
+
+	updated, err := ps.SetSmartRules("1", 10, &model.SmartRules{Album: "Jazz", Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, "Jazz", updated.SmartRules.Album)
+
+	// Smart playlists cannot be edited song by song, and only editors may change their rules.
+	assert.Equal(t, ErrSmartPlaylistReadOnly, ps.AuthorizePlaylist("1", 10, PermissionEditSongs))
+	assert.NoError(t, ps.AuthorizePlaylist("1", 10, PermissionEdit))
+	_, err = ps.SetSmartRules("1", 20, nil)
+	assert.Equal(t, ErrPlaylistForbidden, err)
+
+	updated, err = ps.SetSmartRules("1", 10, nil)
+	assert.NoError(t, err)
+	assert.Nil(t, updated.SmartRules)
+	assert.NoError(t, ps.AuthorizePlaylist("1", 10, PermissionEditSongs))
+	mockDAO.AssertExpectations(t)
+}
+
+func TestRefreshStaleSmartPlaylists(t *testing.T) {
+	mockDAO := new(mocks.MusicDAO)
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	rs := NewSmartPlaylistRefreshService(mockDAO, time.Hour).(*smartPlaylistRefreshService)
+	rs.now = func() time.Time { return now }
+
+	rules := &model.SmartRules{LikedByMe: true, Limit: 5}
+	mockDAO.On("GetStaleSmartPlaylists", now.Add(-time.Hour), []uint(nil), smartPlaylistRefreshBatchSize).Return([]model.Playlist{
+		{Model: gorm.Model{ID: 1}, UserID: 10, SmartRules: rules},
+		{Model: gorm.Model{ID: 2}, UserID: 11, SmartRules: rules},
+	}, nil).Once()
+	mockDAO.On("FindSongsMatchingRules", uint(10), *rules, 5).Return([]uint{1}, nil).Once()
+	mockDAO.On("FindSongsMatchingRules", uint(11), *rules, 5).Return(nil, nil).Once()
+	mockDAO.On("MaterializeSmartPlaylist", uint(1), []uint{1}, now, uint(0)).Return(nil).Once()
+	mockDAO.On("MaterializeSmartPlaylist", uint(2), []uint(nil), now, uint(0)).Return(nil).Once()
+
+	refreshed, err := rs.RefreshStalePlaylists(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, refreshed)
+	mockDAO.AssertExpectations(t)
+}
+
+func TestRefreshStaleSmartPlaylistsContinuesAfterFailures(t *testing.T) {
+	mockDAO := new(mocks.MusicDAO)
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	rs := NewSmartPlaylistRefreshService(mockDAO, time.Hour).(*smartPlaylistRefreshService)
+	rs.now = func() time.Time { return now }
+
+	rules := &model.SmartRules{LikedByMe: true, Limit: 5}
+	batch := make([]model.Playlist, smartPlaylistRefreshBatchSize)
+	for i := range batch {
+		batch[i] = model.Playlist{Model: gorm.Model{ID: uint(i + 1)}, UserID: uint(i + 1), SmartRules: rules}
+	}
+	// The second playlist of a full batch fails; the next batch must leave it out and go on.
+	mockDAO.On("GetStaleSmartPlaylists", now.Add(-time.Hour), []uint(nil), smartPlaylistRefreshBatchSize).Return(batch, nil).Once()
+	mockDAO.On("GetStaleSmartPlaylists", now.Add(-time.Hour), []uint{2}, smartPlaylistRefreshBatchSize).Return([]model.Playlist{
+		{Model: gorm.Model{ID: 51}, UserID: 51, SmartRules: rules},
+	}, nil).Once()
+	mockDAO.On("FindSongsMatchingRules", uint(2), *rules, 5).Return(nil, errors.New("connection reset")).Once()
+	mockDAO.On("FindSongsMatchingRules", mock.AnythingOfType("uint"), *rules, 5).Return([]uint{1}, nil)
+	mockDAO.On("MaterializeSmartPlaylist", mock.AnythingOfType("uint"), []uint{1}, now, uint(0)).Return(nil)
+
+	refreshed, err := rs.RefreshStalePlaylists(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, smartPlaylistRefreshBatchSize, refreshed)
+	mockDAO.AssertCalled(t, "MaterializeSmartPlaylist", uint(3), []uint{1}, now, uint(0))
+	mockDAO.AssertCalled(t, "MaterializeSmartPlaylist", uint(51), []uint{1}, now, uint(0))
+	mockDAO.AssertNotCalled(t, "MaterializeSmartPlaylist", uint(2), mock.Anything, mock.Anything, mock.Anything)
+	mockDAO.AssertExpectations(t)
+}
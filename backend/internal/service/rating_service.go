@@ -0,0 +1,55 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/dao"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/model"
+)
+
+const (
+	minRatingScore = 1
+	maxRatingScore = 5
+)
+
+var ErrInvalidScore = fmt.Errorf("score must be between %d and %d", minRatingScore, maxRatingScore)
+
+// RatingService handles users' ratings of playlists.
+type RatingService interface {
+	RatePlaylist(playlistID string, userID uint, score int) (*model.Rating, error)
+}
+
+type ratingService struct {
+	musicDAO        dao.MusicDAO
+	playlistService PlaylistService
+}
+
+func NewRatingService(musicDAO dao.MusicDAO, playlistService PlaylistService) RatingService {
+	return &ratingService{musicDAO: musicDAO, playlistService: playlistService}
+}
+
+// RatePlaylist records the user's score for a playlist they can see, replacing their earlier score.
+func (s *ratingService) RatePlaylist(playlistID string, userID uint, score int) (*model.Rating, error) {
+	if score < minRatingScore || score > maxRatingScore {
+		return nil, ErrInvalidScore
+	}
+	id, err := parsePlaylistID(playlistID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.playlistService.AuthorizePlaylist(playlistID, userID, PermissionView); err != nil {
+		return nil, err
+	}
+
+	rating := &model.Rating{
+		PlaylistID: strconv.FormatUint(uint64(id), 10),
+		UserID:     strconv.FormatUint(uint64(userID), 10),
+		Score:      score,
+	}
+	if err := s.musicDAO.SaveRating(rating); err != nil {
+		return nil, err
+	}
+	recordActivity(s.musicDAO, model.Activity{ActorID: userID, Type: model.ActivityPlaylistRated, PlaylistID: id, Score: score})
+	return rating, nil
+}
@@ -0,0 +1,35 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/dao/mocks"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func TestRatePlaylist(t *testing.T) {
+	mockDAO := new(mocks.MusicDAO)
+	rs := NewRatingService(mockDAO, NewPlaylistService(mockDAO))
+
+	mockDAO.On("GetPlaylistInfo", "1").Return(&model.Playlist{Model: gorm.Model{ID: 1}, UserID: 3, Visibility: model.VisibilityPublic}, nil)
+	mockDAO.On("GetPlaylistInfo", "2").Return(nil, ErrPlaylistNotFound)
+	mockDAO.On("GetPlaylistCollaborator", uint(1), uint(5)).Return(nil, ErrCollaboratorNotFound)
+	mockDAO.On("SaveRating", &model.Rating{PlaylistID: "1", UserID: "5", Score: 4}).Return(nil).Once()
+	mockDAO.On("CreateActivity", mock.MatchedBy(func(a *model.Activity) bool {
+		return a.Type == model.ActivityPlaylistRated && a.ActorID == 5 && a.PlaylistID == 1 && a.Score == 4
+	})).Return(nil).Once()
+
+	rating, err := rs.RatePlaylist("1", 5, 4)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, rating.Score)
+
+	_, err = rs.RatePlaylist("1", 5, 6)
+	assert.Equal(t, ErrInvalidScore, err)
+
+	_, err = rs.RatePlaylist("2", 5, 3)
+	assert.Equal(t, ErrPlaylistNotFound, err)
+	mockDAO.AssertExpectations(t)
+}
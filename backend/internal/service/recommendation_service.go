@@ -0,0 +1,178 @@
+package service
+
+import (
+	"sort"
+
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/dao"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/model"
+)
+
+const (
+	defaultRecommendationLimit = 20
+	maxRecommendationLimit     = 100
+
+	// recommendationSeedLimit caps how many liked and recently played songs seed a user's recommendations.
+	recommendationSeedLimit = 50
+	// recommendationCandidateLimit caps how many candidates each signal contributes before scoring.
+	recommendationCandidateLimit = 200
+
+	// Weights of the two signals in a recommendation's score. Both signals are normalized to [0, 1].
+	coOccurrenceWeight  = 0.7
+	artistOverlapWeight = 0.3
+)
+
+// Recommendation is a suggested song with the evidence behind it. CoOccurrences is the number of
+// playlists the song shares with the seed songs and SameArtist is set when an artist of the seed
+// songs performs it.
+type Recommendation struct {
+	Song          model.Song `json:"song"`
+	Score         float64    `json:"score"`
+	CoOccurrences int64      `json:"co_occurrences"`
+	SameArtist    bool       `json:"same_artist"`
+}
+
+// RecommendationService suggests songs based on which songs appear together in playlists and
+// which artists the seed songs are by, using only our own data.
+type RecommendationService interface {
+	RecommendForPlaylist(playlistID string, userID uint, limit int) ([]Recommendation, error)
+	RecommendForUser(userID uint, limit int) ([]Recommendation, error)
+}
+
+type recommendationService struct {
+	musicDAO        dao.MusicDAO
+	playlistService PlaylistService
+}
+
+func NewRecommendationService(musicDAO dao.MusicDAO, playlistService PlaylistService) RecommendationService {
+	return &recommendationService{musicDAO: musicDAO, playlistService: playlistService}
+}
+
+// RecommendForPlaylist suggests songs that are not in a playlist the user can see, seeded by its songs.
+func (s *recommendationService) RecommendForPlaylist(playlistID string, userID uint, limit int) ([]Recommendation, error) {
+	if err := s.playlistService.AuthorizePlaylist(playlistID, userID, PermissionView); err != nil {
+		return nil, err
+	}
+	playlist, err := s.musicDAO.GetPlaylistByID(playlistID)
+	if err != nil {
+		return nil, err
+	}
+	return s.recommend(userID, playlist.Songs, limit)
+}
+
+// RecommendForUser suggests songs seeded by the user's liked songs and recent plays, leaving out
+// the songs used as seeds.
+func (s *recommendationService) RecommendForUser(userID uint, limit int) ([]Recommendation, error) {
+	liked, _, err := s.musicDAO.GetLikedSongs(userID, false, 0, recommendationSeedLimit)
+	if err != nil {
+		return nil, err
+	}
+	plays, err := s.musicDAO.GetPlayHistory(userID, 0, recommendationSeedLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	seeds := make([]model.Song, 0, len(liked)+len(plays))
+	for _, like := range liked {
+		seeds = append(seeds, like.Song)
+	}
+	for _, play := range plays {
+		if play.Song != nil {
+			seeds = append(seeds, *play.Song)
+		}
+	}
+	return s.recommend(userID, seeds, limit)
+}
+
+// recommend scores songs outside seeds by how often they share a playlist with the seeds and by
+// how many of the seeds are by the same artist, and returns the best scoring ones. Only public
+// and unlisted playlists and the user's own are counted.
+func (s *recommendationService) recommend(userID uint, seeds []model.Song, limit int) ([]Recommendation, error) {
+	if limit <= 0 {
+		limit = defaultRecommendationLimit
+	}
+	if limit > maxRecommendationLimit {
+		limit = maxRecommendationLimit
+	}
+	recommendations := []Recommendation{}
+	if len(seeds) == 0 {
+		return recommendations, nil
+	}
+
+	seedIDs := make([]uint, 0, len(seeds))
+	seen := make(map[uint]bool, len(seeds))
+	artistSeeds := make(map[string]int)
+	for _, song := range seeds {
+		if seen[song.ID] {
+			continue
+		}
+		seen[song.ID] = true
+		seedIDs = append(seedIDs, song.ID)
+		if song.Artist != "" {
+			artistSeeds[song.Artist]++
+		}
+	}
+	artists := make([]string, 0, len(artistSeeds))
+	maxArtistSeeds := 0
+	for artist, count := range artistSeeds {
+		artists = append(artists, artist)
+		maxArtistSeeds = max(maxArtistSeeds, count)
+	}
+	sort.Strings(artists)
+
+	occurrences, err := s.musicDAO.GetCoOccurringSongs(userID, seedIDs, recommendationCandidateLimit)
+	if err != nil {
+		return nil, err
+	}
+	sameArtist, err := s.musicDAO.GetSongsByArtists(artists, seedIDs, recommendationCandidateLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make(map[uint]*Recommendation)
+	var maxOccurrences int64
+	occurringIDs := make([]uint, 0, len(occurrences))
+	for _, occurrence := range occurrences {
+		candidates[occurrence.SongID] = &Recommendation{CoOccurrences: occurrence.Occurrences}
+		occurringIDs = append(occurringIDs, occurrence.SongID)
+		maxOccurrences = max(maxOccurrences, occurrence.Occurrences)
+	}
+	songs, err := s.musicDAO.GetSongsByIDs(occurringIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, song := range songs {
+		candidates[song.ID].Song = song
+	}
+	for _, song := range sameArtist {
+		candidate, ok := candidates[song.ID]
+		if !ok {
+			candidate = &Recommendation{Song: song}
+			candidates[song.ID] = candidate
+		}
+		candidate.SameArtist = true
+	}
+
+	for id, candidate := range candidates {
+		// Songs that were deleted since their occurrences were counted have no details.
+		if candidate.Song.ID != id {
+			continue
+		}
+		if maxOccurrences > 0 {
+			candidate.Score += coOccurrenceWeight * float64(candidate.CoOccurrences) / float64(maxOccurrences)
+		}
+		if candidate.SameArtist {
+			candidate.Score += artistOverlapWeight * float64(artistSeeds[candidate.Song.Artist]) / float64(maxArtistSeeds)
+		}
+		recommendations = append(recommendations, *candidate)
+	}
+	sort.Slice(recommendations, func(i, j int) bool {
+		if recommendations[i].Score != recommendations[j].Score {
+			return recommendations[i].Score > recommendations[j].Score
+		}
+		return recommendations[i].Song.ID < recommendations[j].Song.ID
+	})
+	if len(recommendations) > limit {
+		recommendations = recommendations[:limit]
+	}
+	return recommendations, nil
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/dao/mocks"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/model"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestRecommendForPlaylist(t *testing.T) {
+	mockDAO := new(mocks.MusicDAO)
+	rs := NewRecommendationService(mockDAO, NewPlaylistService(mockDAO))
+
+	seeds := []model.Song{
+		{Model: gorm.Model{ID: 1}, Artist: "Queen"},
+		{Model: gorm.Model{ID: 2}, Artist: "Queen"},
+		{Model: gorm.Model{ID: 3}, Artist: "Bowie"},
+		{Model: gorm.Model{ID: 1}, Artist: "Queen"},
+	}
+	mockDAO.On("GetPlaylistInfo", "1").Return(&model.Playlist{Model: gorm.Model{ID: 1}, UserID: 5}, nil)
+	mockDAO.On("GetPlaylistInfo", "2").Return(nil, ErrPlaylistNotFound)
+	mockDAO.On("GetPlaylistByID", "1").Return(&model.Playlist{Model: gorm.Model{ID: 1}, UserID: 5, Songs: seeds}, nil)
+	mockDAO.On("GetCoOccurringSongs", uint(5), []uint{1, 2, 3}, recommendationCandidateLimit).Return([]model.SongOccurrence{
+		{SongID: 10, Occurrences: 4},
+		{SongID: 11, Occurrences: 2},
+		{SongID: 12, Occurrences: 1},
+	}, nil)
+	mockDAO.On("GetSongsByArtists", []string{"Bowie", "Queen"}, []uint{1, 2, 3}, recommendationCandidateLimit).Return([]model.Song{
+		{Model: gorm.Model{ID: 11}, Artist: "Queen"},
+		{Model: gorm.Model{ID: 13}, Artist: "Bowie"},
+	}, nil)
+	// Song 12 was deleted after its occurrences were counted.
+	mockDAO.On("GetSongsByIDs", []uint{10, 11, 12}).Return([]model.Song{
+		{Model: gorm.Model{ID: 10}, Artist: "Other"},
+		{Model: gorm.Model{ID: 11}, Artist: "Queen"},
+	}, nil)
+
+	recommendations, err := rs.RecommendForPlaylist("1", 5, 0)
+	assert.NoError(t, err)
+	if assert.Len(t, recommendations, 3) {
+		// 10: 0.7*4/4, 11: 0.7*2/4 + 0.3*2/2, 13: 0.3*1/2
+		assert.Equal(t, uint(10), recommendations[0].Song.ID)
+		assert.Equal(t, int64(4), recommendations[0].CoOccurrences)
+		assert.False(t, recommendations[0].SameArtist)
+		assert.Equal(t, uint(11), recommendations[1].Song.ID)
+		assert.InDelta(t, 0.65, recommendations[1].Score, 0.001)
+		assert.True(t, recommendations[1].SameArtist)
+		assert.Equal(t, uint(13), recommendations[2].Song.ID)
+		assert.InDelta(t, 0.15, recommendations[2].Score, 0.001)
+	}
+
+	recommendations, err = rs.RecommendForPlaylist("1", 5, 1)
+	assert.NoError(t, err)
+	assert.Len(t, recommendations, 1)
+
+	_, err = rs.RecommendForPlaylist("2", 5, 0)
+	assert.Equal(t, ErrPlaylistNotFound, err)
+}
+
+func TestRecommendForUserWithoutSeeds(t *testing.T) {
+	mockDAO := new(mocks.MusicDAO)
+	rs := NewRecommendationService(mockDAO, NewPlaylistService(mockDAO))
+
+	mockDAO.On("GetLikedSongs", uint(5), false, 0, recommendationSeedLimit).Return(nil, int64(0), nil)
+	mockDAO.On("GetPlayHistory", uint(5), uint(0), recommendationSeedLimit).Return(nil, nil)
+
+	recommendations, err := rs.RecommendForUser(5, 0)
+	assert.NoError(t, err)
+	assert.NotNil(t, recommendations)
+	assert.Empty(t, recommendations)
+	mockDAO.AssertNotCalled(t, "GetCoOccurringSongs")
+}
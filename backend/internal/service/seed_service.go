@@ -0,0 +1,152 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/dao"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/model"
+)
+
+// minSeedPasswordLength is the shortest bootstrap admin password accepted at startup.
+const minSeedPasswordLength = 12
+
+var ErrInvalidSeedConfig = errors.New("invalid seed configuration")
+
+// SeedConfig describes the data created when the application starts.
+type SeedConfig struct {
+	// The bootstrap admin is created when AdminUsername is set and no user has that name yet.
+	AdminUsername string
+	AdminEmail    string
+	AdminPassword string // Usually read from a secret; never logged.
+	AdminRole     string
+
+	// DemoData adds sample songs and playlists owned by the bootstrap admin to an empty catalog.
+	DemoData bool
+}
+
+// SeedService creates the bootstrap admin and the optional demo data.
+type SeedService interface {
+	Seed() error
+}
+
+type seedService struct {
+	musicDAO        dao.MusicDAO
+	userService     UserService
+	playlistService PlaylistService
+	config          SeedConfig
+}
+
+func NewSeedService(musicDAO dao.MusicDAO, userService UserService, playlistService PlaylistService, config SeedConfig) SeedService {
+	return &seedService{musicDAO: musicDAO, userService: userService, playlistService: playlistService, config: config}
+}
+
+// Seed creates whatever the configuration asks for and is not in the database yet, so it is safe
+// to run on every start. An existing admin is left untouched, including its password.
+func (s *seedService) Seed() error {
+	if s.config.AdminUsername == "" {
+		if s.config.DemoData {
+			return fmt.Errorf("%w: demo data needs a bootstrap admin to own it", ErrInvalidSeedConfig)
+		}
+		return nil
+	}
+
+	admin, err := s.bootstrapAdmin()
+	if err != nil {
+		return fmt.Errorf("failed to create bootstrap admin: %w", err)
+	}
+	if s.config.DemoData {
+		if err := s.seedDemoData(admin.ID); err != nil {
+			return fmt.Errorf("failed to seed demo data: %w", err)
+		}
+	}
+	return nil
+}
+
+// bootstrapAdmin returns the bootstrap admin, registering it the same way as any other user if it does not exist.
+func (s *seedService) bootstrapAdmin() (*model.User, error) {
+	existing, err := s.musicDAO.GetUserByUsername(s.config.AdminUsername)
+	if err == nil {
+		return existing, nil
+	}
+	if !errors.Is(err, ErrUserNotFound) {
+		return nil, err
+	}
+
+	if s.config.AdminEmail == "" {
+		return nil, fmt.Errorf("%w: the bootstrap admin needs an email", ErrInvalidSeedConfig)
+	}
+	if len(s.config.AdminPassword) < minSeedPasswordLength {
+		return nil, fmt.Errorf("%w: the bootstrap admin password must have at least %d characters", ErrInvalidSeedConfig, minSeedPasswordLength)
+	}
+
+	admin := &model.User{
+		Username: s.config.AdminUsername,
+		Email:    s.config.AdminEmail,
+		Password: s.config.AdminPassword,
+		Role:     s.config.AdminRole,
+	}
+	if err := s.userService.RegisterUser(admin); err != nil {
+		return nil, err
+	}
+	log.Printf("Created bootstrap admin %q with role %q", admin.Username, admin.Role)
+	return admin, nil
+}
+
+// demoSongs are the songs added by the demo data. They have no Spotify ID, so the catalog
+// refresh worker leaves them alone.
+var demoSongs = []model.Song{
+	{Name: "Morning Commute", Artist: "The Demo Band", AlbumName: "Sample Sessions"},
+	{Name: "Placeholder Blues", Artist: "The Demo Band", AlbumName: "Sample Sessions"},
+	{Name: "Lorem Ipsum", Artist: "Fixture Four", AlbumName: "Test Pressing"},
+	{Name: "Hello, World", Artist: "Fixture Four", AlbumName: "Test Pressing"},
+	{Name: "Seed Data", Artist: "Null Pointer", AlbumName: "Stack Trace"},
+	{Name: "Off By One", Artist: "Null Pointer", AlbumName: "Stack Trace"},
+}
+
+// demoPlaylists lists the demo playlists with the indexes of their songs in demoSongs.
+var demoPlaylists = []struct {
+	name       string
+	visibility string
+	songs      []int
+}{
+	{"Demo Mix", model.VisibilityPublic, []int{0, 2, 4, 1, 3, 5}},
+	{"Demo Favorites", model.VisibilityPrivate, []int{4, 5, 0}},
+}
+
+// seedDemoData adds the demo songs and playlists unless the catalog already has songs.
+func (s *seedService) seedDemoData(ownerID uint) error {
+	songs, err := s.musicDAO.GetAllSongs()
+	if err != nil {
+		return err
+	}
+	if len(songs) > 0 {
+		return nil
+	}
+
+	songIDs := make([]uint, len(demoSongs))
+	for i := range demoSongs {
+		song := demoSongs[i]
+		if err := s.musicDAO.CreateSong(&song); err != nil {
+			return err
+		}
+		songIDs[i] = song.ID
+	}
+
+	for _, demo := range demoPlaylists {
+		playlist := &model.Playlist{Name: demo.name, UserID: ownerID, Visibility: demo.visibility}
+		if err := s.playlistService.CreatePlaylist(playlist); err != nil {
+			return err
+		}
+		ids := make([]uint, len(demo.songs))
+		for i, song := range demo.songs {
+			ids[i] = songIDs[song]
+		}
+		if err := s.playlistService.ReplacePlaylistSongs(fmt.Sprint(playlist.ID), ids, ownerID); err != nil {
+			return err
+		}
+	}
+	log.Printf("Seeded %d demo songs and %d demo playlists", len(demoSongs), len(demoPlaylists))
+	return nil
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/dao/mocks"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+func TestSeedBootstrapAdmin(t *testing.T) {
+	mockDAO := new(mocks.MusicDAO)
+	config := SeedConfig{AdminUsername: "root", AdminEmail: "Root@Example.com", AdminPassword: "correct-horse-battery", AdminRole: "admin"}
+	seeder := NewSeedService(mockDAO, NewUserService(mockDAO), NewPlaylistService(mockDAO), config)
+
+	mockDAO.On("GetUserByUsername", "root").Return(nil, ErrUserNotFound).Once()
+	mockDAO.On("UsernameExists", "root").Return(false, nil)
+	mockDAO.On("EmailExists", "root@example.com").Return(false, nil)
+	mockDAO.On("CreateUser", mock.MatchedBy(func(u *model.User) bool {
+		return u.Role == "admin" && bcrypt.CompareHashAndPassword([]byte(u.Password), []byte("correct-horse-battery")) == nil
+	})).Return(nil).Once()
+
+	assert.NoError(t, seeder.Seed())
+
+	// Later starts leave the existing admin alone.
+	mockDAO.On("GetUserByUsername", "root").Return(&model.User{Model: gorm.Model{ID: 1}, Username: "root"}, nil)
+	assert.NoError(t, seeder.Seed())
+	mockDAO.AssertExpectations(t)
+}
+
+func TestSeedInvalidConfig(t *testing.T) {
+	mockDAO := new(mocks.MusicDAO)
+	mockDAO.On("GetUserByUsername", "root").Return(nil, ErrUserNotFound)
+
+	tests := []SeedConfig{
+		{DemoData: true},
+		{AdminUsername: "root", AdminPassword: "correct-horse-battery"},
+		{AdminUsername: "root", AdminEmail: "root@example.com", AdminPassword: "short"},
+	}
+	for _, config := range tests {
+		err := NewSeedService(mockDAO, NewUserService(mockDAO), NewPlaylistService(mockDAO), config).Seed()
+		assert.ErrorIs(t, err, ErrInvalidSeedConfig)
+	}
+	mockDAO.AssertNotCalled(t, "CreateUser", mock.Anything)
+}
+
+func TestSeedDemoData(t *testing.T) {
+	mockDAO := new(mocks.MusicDAO)
+	config := SeedConfig{AdminUsername: "root", DemoData: true}
+	seeder := NewSeedService(mockDAO, NewUserService(mockDAO), NewPlaylistService(mockDAO), config)
+
+	mockDAO.On("GetUserByUsername", "root").Return(&model.User{Model: gorm.Model{ID: 1}, Username: "root"}, nil)
+	mockDAO.On("GetAllSongs").Return([]model.Song{}, nil).Once()
+	nextSongID := uint(0)
+	mockDAO.On("CreateSong", mock.AnythingOfType("*model.Song")).Run(func(args mock.Arguments) {
+		nextSongID++
+		args.Get(0).(*model.Song).ID = nextSongID
+	}).Return(nil).Times(len(demoSongs))
+	nextPlaylistID := uint(0)
+	mockDAO.On("CreatePlaylist", mock.MatchedBy(func(p *model.Playlist) bool { return p.UserID == 1 })).Run(func(args mock.Arguments) {
+		nextPlaylistID++
+		args.Get(0).(*model.Playlist).ID = nextPlaylistID
+	}).Return(nil).Times(len(demoPlaylists))
+	mockDAO.On("CreateActivity", mock.AnythingOfType("*model.Activity")).Return(nil)
+	mockDAO.On("ReplacePlaylistEntries", "1", []uint{1, 3, 5, 2, 4, 6}, uint(1)).Return(nil).Once()
+	mockDAO.On("ReplacePlaylistEntries", "2", []uint{5, 6, 1}, uint(1)).Return(nil).Once()
+
+	assert.NoError(t, seeder.Seed())
+
+	// The demo data is only added to an empty catalog.
+	mockDAO.On("GetAllSongs").Return([]model.Song{{Model: gorm.Model{ID: 1}}}, nil)
+	assert.NoError(t, seeder.Seed())
+	mockDAO.AssertExpectations(t)
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/dao"
+)
+
+// SmartPlaylistRefreshService periodically recomputes the songs of smart playlists, so that they
+// pick up new songs, plays, likes and ratings.
+type SmartPlaylistRefreshService interface {
+	Run(ctx context.Context)
+	RefreshStalePlaylists(ctx context.Context) (int, error)
+}
+
+// smartPlaylistRefreshBatchSize is the number of smart playlists loaded at a time by a refresh run.
+const smartPlaylistRefreshBatchSize = 50
+
+type smartPlaylistRefreshService struct {
+	musicDAO dao.MusicDAO
+	interval time.Duration
+	now      func() time.Time
+}
+
+// NewSmartPlaylistRefreshService creates a worker that refreshes smart playlists not refreshed
+// within interval; a zero interval disables it.
+func NewSmartPlaylistRefreshService(musicDAO dao.MusicDAO, interval time.Duration) SmartPlaylistRefreshService {
+	return &smartPlaylistRefreshService{musicDAO: musicDAO, interval: interval, now: time.Now}
+}
+
+// Run refreshes stale smart playlists immediately and then on every interval until ctx is cancelled.
+func (s *smartPlaylistRefreshService) Run(ctx context.Context) {
+	if s.interval <= 0 {
+		log.Println("Smart playlist refresh worker disabled")
+		return
+	}
+
+	log.Printf("Starting smart playlist refresh worker (interval %s)", s.interval)
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if refreshed, err := s.RefreshStalePlaylists(ctx); err != nil {
+			log.Printf("Smart playlist refresh failed after %d playlists: %v", refreshed, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Println("Stopping smart playlist refresh worker")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RefreshStalePlaylists recomputes every smart playlist that was not refreshed within the
+// interval and returns how many were refreshed. A playlist that fails to refresh is logged and
+// left out of the rest of the run, so that it neither stops the others from being refreshed nor
+// is loaded again with every batch; the next run tries it again.
+func (s *smartPlaylistRefreshService) RefreshStalePlaylists(ctx context.Context) (int, error) {
+	cutoff := s.now().Add(-s.interval)
+	refreshed := 0
+	var failedIDs []uint
+	for {
+		if err := ctx.Err(); err != nil {
+			return refreshed, err
+		}
+
+		playlists, err := s.musicDAO.GetStaleSmartPlaylists(cutoff, failedIDs, smartPlaylistRefreshBatchSize)
+		if err != nil {
+			return refreshed, err
+		}
+		for i := range playlists {
+			if err := materializeSmartPlaylist(s.musicDAO, &playlists[i], s.now(), 0); err != nil {
+				log.Printf("Failed to refresh smart playlist %d: %v", playlists[i].ID, err)
+				failedIDs = append(failedIDs, playlists[i].ID)
+				continue
+			}
+			refreshed++
+		}
+		if len(playlists) < smartPlaylistRefreshBatchSize {
+			return refreshed, nil
+		}
+	}
+}
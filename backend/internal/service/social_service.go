@@ -0,0 +1,152 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/dao"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/model"
+)
+
+var ErrCannotFollowSelf = errors.New("users cannot follow themselves")
+
+const (
+	defaultFeedLimit = 20
+	maxFeedLimit     = 100
+)
+
+// FollowCounts reports how many users follow a user or playlist and, for users, how much they follow.
+type FollowCounts struct {
+	Followers int64  `json:"followers"`
+	Following *int64 `json:"following,omitempty"`
+}
+
+// Feed is a page of activity, newest first. NextBefore is passed as "before" to fetch the next page.
+type Feed struct {
+	Activities []model.Activity `json:"activities"`
+	NextBefore uint             `json:"next_before,omitempty"`
+}
+
+// SocialService handles following users and playlists and the resulting activity feed.
+type SocialService interface {
+	FollowUser(followerID uint, username string) error
+	UnfollowUser(followerID uint, username string) error
+	FollowPlaylist(followerID uint, playlistID string) error
+	UnfollowPlaylist(followerID uint, playlistID string) error
+	GetUserFollowCounts(username string) (*FollowCounts, error)
+	GetPlaylistFollowCounts(userID uint, playlistID string) (*FollowCounts, error)
+	GetFeed(userID, before uint, limit int) (*Feed, error)
+}
+
+type socialService struct {
+	musicDAO        dao.MusicDAO
+	playlistService PlaylistService
+}
+
+func NewSocialService(musicDAO dao.MusicDAO, playlistService PlaylistService) SocialService {
+	return &socialService{musicDAO: musicDAO, playlistService: playlistService}
+}
+
+// FollowUser makes the follower follow the user with the given username.
+func (s *socialService) FollowUser(followerID uint, username string) error {
+	user, err := s.musicDAO.GetUserByUsername(username)
+	if err != nil {
+		return err
+	}
+	if user.ID == followerID {
+		return ErrCannotFollowSelf
+	}
+	return s.musicDAO.CreateFollow(&model.Follow{FollowerID: followerID, TargetType: model.FollowUser, TargetID: user.ID})
+}
+
+// UnfollowUser stops the follower following the user with the given username.
+func (s *socialService) UnfollowUser(followerID uint, username string) error {
+	user, err := s.musicDAO.GetUserByUsername(username)
+	if err != nil {
+		return err
+	}
+	return s.musicDAO.DeleteFollow(followerID, model.FollowUser, user.ID)
+}
+
+// FollowPlaylist makes the follower follow a playlist they can see.
+func (s *socialService) FollowPlaylist(followerID uint, playlistID string) error {
+	id, err := s.visiblePlaylistID(followerID, playlistID)
+	if err != nil {
+		return err
+	}
+	return s.musicDAO.CreateFollow(&model.Follow{FollowerID: followerID, TargetType: model.FollowPlaylist, TargetID: id})
+}
+
+// UnfollowPlaylist stops the follower following a playlist. It works even if the playlist is no
+// longer visible to them.
+func (s *socialService) UnfollowPlaylist(followerID uint, playlistID string) error {
+	id, err := parsePlaylistID(playlistID)
+	if err != nil {
+		return err
+	}
+	return s.musicDAO.DeleteFollow(followerID, model.FollowPlaylist, id)
+}
+
+// GetUserFollowCounts counts a user's followers and the users and playlists they follow.
+func (s *socialService) GetUserFollowCounts(username string) (*FollowCounts, error) {
+	user, err := s.musicDAO.GetUserByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+	followers, err := s.musicDAO.CountFollowers(model.FollowUser, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	following, err := s.musicDAO.CountFollowing(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &FollowCounts{Followers: followers, Following: &following}, nil
+}
+
+// GetPlaylistFollowCounts counts the followers of a playlist the user can see.
+func (s *socialService) GetPlaylistFollowCounts(userID uint, playlistID string) (*FollowCounts, error) {
+	id, err := s.visiblePlaylistID(userID, playlistID)
+	if err != nil {
+		return nil, err
+	}
+	followers, err := s.musicDAO.CountFollowers(model.FollowPlaylist, id)
+	if err != nil {
+		return nil, err
+	}
+	return &FollowCounts{Followers: followers}, nil
+}
+
+// GetFeed returns a page of recent activity by the users and on the playlists the user follows.
+func (s *socialService) GetFeed(userID, before uint, limit int) (*Feed, error) {
+	if limit <= 0 {
+		limit = defaultFeedLimit
+	}
+	if limit > maxFeedLimit {
+		limit = maxFeedLimit
+	}
+
+	activities, err := s.musicDAO.GetFeed(userID, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	feed := &Feed{Activities: activities}
+	if feed.Activities == nil {
+		feed.Activities = []model.Activity{}
+	}
+	if len(activities) == limit {
+		feed.NextBefore = activities[len(activities)-1].ID
+	}
+	return feed, nil
+}
+
+// visiblePlaylistID checks that the user can see the playlist and returns its numeric ID.
+func (s *socialService) visiblePlaylistID(userID uint, playlistID string) (uint, error) {
+	id, err := parsePlaylistID(playlistID)
+	if err != nil {
+		return 0, err
+	}
+	if err := s.playlistService.AuthorizePlaylist(playlistID, userID, PermissionView); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
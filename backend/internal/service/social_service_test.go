@@ -0,0 +1,70 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/dao/mocks"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func TestFollowUser(t *testing.T) {
+	mockDAO := new(mocks.MusicDAO)
+	ss := NewSocialService(mockDAO, NewPlaylistService(mockDAO))
+
+	mockDAO.On("GetUserByUsername", "alice").Return(&model.User{Model: gorm.Model{ID: 2}, Username: "alice"}, nil)
+	mockDAO.On("GetUserByUsername", "ghost").Return(nil, ErrUserNotFound)
+	mockDAO.On("CreateFollow", &model.Follow{FollowerID: 1, TargetType: model.FollowUser, TargetID: 2}).Return(nil).Once()
+
+	assert.NoError(t, ss.FollowUser(1, "alice"))
+	assert.Equal(t, ErrCannotFollowSelf, ss.FollowUser(2, "alice"))
+	assert.Equal(t, ErrUserNotFound, ss.FollowUser(1, "ghost"))
+	mockDAO.AssertExpectations(t)
+}
+
+func TestFollowPlaylist(t *testing.T) {
+	mockDAO := new(mocks.MusicDAO)
+	ss := NewSocialService(mockDAO, NewPlaylistService(mockDAO))
+
+	mockDAO.On("GetPlaylistInfo", "1").Return(&model.Playlist{Model: gorm.Model{ID: 1}, UserID: 10, Visibility: model.VisibilityPublic}, nil)
+	mockDAO.On("GetPlaylistInfo", "2").Return(&model.Playlist{Model: gorm.Model{ID: 2}, UserID: 10, Visibility: model.VisibilityPrivate}, nil)
+	mockDAO.On("GetPlaylistCollaborator", mock.Anything, uint(3)).Return(nil, ErrCollaboratorNotFound)
+	mockDAO.On("CreateFollow", &model.Follow{FollowerID: 3, TargetType: model.FollowPlaylist, TargetID: 1}).Return(nil).Once()
+	mockDAO.On("CountFollowers", model.FollowPlaylist, uint(1)).Return(int64(4), nil)
+
+	assert.NoError(t, ss.FollowPlaylist(3, "1"))
+	assert.Equal(t, ErrPlaylistNotFound, ss.FollowPlaylist(3, "2"))
+
+	counts, err := ss.GetPlaylistFollowCounts(3, "1")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), counts.Followers)
+	assert.Nil(t, counts.Following)
+	mockDAO.AssertExpectations(t)
+}
+
+func TestGetFeed(t *testing.T) {
+	mockDAO := new(mocks.MusicDAO)
+	ss := NewSocialService(mockDAO, NewPlaylistService(mockDAO))
+
+	page := []model.Activity{{ID: 9}, {ID: 7}}
+	mockDAO.On("GetFeed", uint(1), uint(0), 2).Return(page, nil)
+	mockDAO.On("GetFeed", uint(1), uint(7), defaultFeedLimit).Return(nil, nil)
+	mockDAO.On("GetFeed", uint(1), uint(0), maxFeedLimit).Return(page, nil)
+
+	feed, err := ss.GetFeed(1, 0, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, page, feed.Activities)
+	assert.Equal(t, uint(7), feed.NextBefore)
+
+	// A short page is the last one.
+	feed, err = ss.GetFeed(1, 7, 0)
+	assert.NoError(t, err)
+	assert.Empty(t, feed.Activities)
+	assert.Zero(t, feed.NextBefore)
+
+	feed, err = ss.GetFeed(1, 0, 1000)
+	assert.NoError(t, err)
+	assert.Zero(t, feed.NextBefore)
+}
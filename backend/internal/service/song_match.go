@@ -0,0 +1,85 @@
+package service
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/model"
+)
+
+var (
+	// bracketedPattern matches "(Remastered 2011)" or "[Live]" annotations.
+	bracketedPattern = regexp.MustCompile(`\([^)]*\)|\[[^\]]*\]`)
+	// featuringPattern matches a trailing "feat. Someone" credit.
+	featuringPattern = regexp.MustCompile(`(?i)\s(feat\.?|ft\.?|featuring)\s.*$`)
+	// versionSuffixPattern matches a trailing " - Remastered" style version note.
+	versionSuffixPattern = regexp.MustCompile(`(?i)\s-\s.*(remaster|version|edit|mix|live|mono|stereo).*$`)
+)
+
+// stripTitleAnnotations removes version notes and featured artists from a song title.
+func stripTitleAnnotations(title string) string {
+	title = bracketedPattern.ReplaceAllString(title, "")
+	title = featuringPattern.ReplaceAllString(title, "")
+	title = versionSuffixPattern.ReplaceAllString(title, "")
+	return strings.TrimSpace(title)
+}
+
+// normalizeForMatch lowercases s and reduces it to space-separated letters and digits.
+func normalizeForMatch(s string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+// similarity returns a score between 0 and 1 based on the edit distance between a and b.
+func similarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	if longest == 0 {
+		return 0
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+// levenshtein computes the edit distance between two rune slices.
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+// songMatchConfidence scores how likely song is the track described by title and artist.
+// Without an artist the score is capped, since the title alone is ambiguous.
+func songMatchConfidence(title, artist string, song *model.Song) float64 {
+	titleScore := similarity(normalizeForMatch(stripTitleAnnotations(title)), normalizeForMatch(stripTitleAnnotations(song.Name)))
+	if artist == "" {
+		return titleScore * 0.75
+	}
+
+	wantArtist, gotArtist := normalizeForMatch(artist), normalizeForMatch(song.Artist)
+	artistScore := similarity(wantArtist, gotArtist)
+	// Credits such as "Artist A & Artist B" still match the main artist.
+	if artistScore < 0.9 && wantArtist != "" && gotArtist != "" &&
+		(strings.Contains(wantArtist, gotArtist) || strings.Contains(gotArtist, wantArtist)) {
+		artistScore = 0.9
+	}
+	return 0.7*titleScore + 0.3*artistScore
+}
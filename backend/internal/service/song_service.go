@@ -22,7 +22,7 @@ import (
 var (
 	ErrSongNameRequired    = errors.New("song name and artist are required")
 	ErrSongAlreadyExists   = errors.New("a song with the same name by the same artist already exists")
-	ErrSongNotFound        = errors.New("song not found")
+	ErrSongNotFound        = dao.ErrSongNotFound
 	ErrInvalidSongID       = errors.New("invalid song ID")
 	ErrFetchingFromSpotify = errors.New("error fetching data from Spotify")
 )
@@ -65,15 +65,19 @@ type SpotifyTrackResponse struct {
 }
 
 func NewSongService(songDAO dao.MusicDAO) SongService {
+	return &songService{songDAO: songDAO, httpClient: newSpotifyHTTPClient()}
+}
+
+// newSpotifyHTTPClient returns an HTTP client authenticated against the Spotify API
+// with the client credentials flow.
+func newSpotifyHTTPClient() *http.Client {
 	// Configure the client for Spotify API authentication
 	config := &clientcredentials.Config{
 		ClientID:     os.Getenv("SPOTIFY_CLIENT_ID"), // these are set in the environment or Kubernetes secrets
 		ClientSecret: os.Getenv("SPOTIFY_CLIENT_SECRET"),
 		TokenURL:     "https://accounts.spotify.com/api/token",
 	}
-	httpClient := config.Client(context.Background())
-
-	return &songService{songDAO: songDAO, httpClient: httpClient}
+	return config.Client(context.Background())
 }
 
 // CreateSong creates a new song in the database.
@@ -3,6 +3,7 @@ package service
 import (
 	"errors"
 	"log"
+	"strings"
 
 	"github.com/kaiohenricunha/go-music-k8s/backend/internal/dao"
 	"github.com/kaiohenricunha/go-music-k8s/backend/internal/model"
@@ -11,10 +12,13 @@ import (
 
 var (
 	// ErrUserNotFound is returned when a user is not found.
-	ErrUserNotFound = errors.New("user not found")
+	ErrUserNotFound = dao.ErrUserNotFound
 
-	// ErrUsernameOrEmailTaken is returned when a username or email is already taken.
-	ErrUsernameOrEmailTaken = errors.New("username or email already taken")
+	// ErrUsernameTaken is returned when another user has the username, in any letter case.
+	ErrUsernameTaken = dao.ErrUsernameTaken
+
+	// ErrEmailTaken is returned when another user has registered the email, in any letter case.
+	ErrEmailTaken = dao.ErrEmailTaken
 
 	// ErrInvalidCredentials is returned when the username or password is incorrect.
 	ErrInvalidCredentials = errors.New("invalid username or password")
@@ -26,6 +30,7 @@ type UserService interface {
 	RegisterUser(user *model.User) error
 	GetAllUsers() ([]model.User, error)
 	GetUserByUsername(username string) (*model.User, error)
+	GetUserByID(userID uint) (*model.User, error)
 }
 
 type userService struct {
@@ -36,32 +41,46 @@ func NewUserService(userDAO dao.MusicDAO) UserService {
 	return &userService{userDAO: userDAO}
 }
 
-// ValidateUser checks if the username and password are correct.
+// dummyPasswordHash is checked against when a username does not exist, so that unknown and known
+// usernames take as long to reject.
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("dummy password"), bcrypt.DefaultCost)
+
+// ValidateUser checks if the username and password are correct. The password is hashed even when
+// the user does not exist, so the response time does not reveal which usernames are taken.
 func (us *userService) ValidateUser(username, password string) (uint, bool) {
 	log.Printf("Validating user: %s\n", username)
 	user, err := us.userDAO.GetUserByUsername(username)
-	if err != nil || user == nil {
-		log.Printf("User not found: %s\n", username)
-		return 0, false
+	hash := dummyPasswordHash
+	if err == nil && user != nil {
+		hash = []byte(user.Password)
 	}
 
-	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
-	if err != nil {
-		log.Printf("Password comparison failed for user: %s\n", username)
+	if bcrypt.CompareHashAndPassword(hash, []byte(password)) != nil || err != nil || user == nil {
+		log.Printf("Invalid credentials for user: %s\n", username)
 		return 0, false
 	}
 
 	return user.ID, true
 }
 
-// RegisterUser handles registering a new user with hashed password.
+// RegisterUser handles registering a new user with hashed password. Usernames and emails are
+// unique regardless of letter case; emails are stored in lower case.
 func (us *userService) RegisterUser(user *model.User) error {
-	// Check if username already exists
-	existingUser, _ := us.GetUserByUsername(user.Username)
-	if existingUser != nil {
-		if user.Email == existingUser.Email {
-			return ErrUsernameOrEmailTaken
-		}
+	user.Username = strings.TrimSpace(user.Username)
+	user.Email = strings.ToLower(strings.TrimSpace(user.Email))
+
+	taken, err := us.userDAO.UsernameExists(user.Username)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return ErrUsernameTaken
+	}
+	if taken, err = us.userDAO.EmailExists(user.Email); err != nil {
+		return err
+	}
+	if taken {
+		return ErrEmailTaken
 	}
 
 	// Hash the password
@@ -84,3 +103,8 @@ func (us *userService) GetAllUsers() ([]model.User, error) {
 func (us *userService) GetUserByUsername(username string) (*model.User, error) {
 	return us.userDAO.GetUserByUsername(username)
 }
+
+// GetUserByID retrieves a user by their ID.
+func (us *userService) GetUserByID(userID uint) (*model.User, error) {
+	return us.userDAO.GetUserByID(userID)
+}
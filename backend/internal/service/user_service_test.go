@@ -15,13 +15,15 @@ func TestRegisterUser(t *testing.T) {
 	mockDAO := &mocks.MusicDAO{}
 	userService := NewUserService(mockDAO)
 
-	password, _ := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.DefaultCost)
-	testUser := &model.User{Username: "testUser", Password: string(password)}
+	testUser := &model.User{Username: " testUser ", Email: "Test@Example.com", Password: "password1"}
 
-	// Mock setup corrected to accurately reflect the logic flow
 	// Scenario 1: User does not exist and is created successfully
-	mockDAO.On("GetUserByUsername", "testUser").Return(nil, nil) // Simulate user not existing
-	mockDAO.On("CreateUser", mock.AnythingOfType("*model.User")).Return(nil)
+	mockDAO.On("UsernameExists", "testUser").Return(false, nil)
+	mockDAO.On("EmailExists", "test@example.com").Return(false, nil)
+	mockDAO.On("CreateUser", mock.MatchedBy(func(u *model.User) bool {
+		return u.Username == "testUser" && u.Email == "test@example.com" &&
+			bcrypt.CompareHashAndPassword([]byte(u.Password), []byte("password1")) == nil
+	})).Return(nil)
 
 	err := userService.RegisterUser(testUser)
 	assert.NoError(t, err)
@@ -31,12 +33,28 @@ func TestRegisterUser(t *testing.T) {
 	mockDAO.ExpectedCalls = nil
 	mockDAO.Calls = nil
 
-	// Scenario 2: User already exists
-	mockDAO.On("GetUserByUsername", "testUser").Return(testUser, nil) // Simulate user existing
+	// Scenario 2: Username taken by another user, whatever the email
+	mockDAO.On("UsernameExists", "TESTUSER").Return(true, nil)
 
-	err = userService.RegisterUser(testUser)
-	assert.Equal(t, ErrUsernameOrEmailTaken, err)
-	mockDAO.AssertExpectations(t)
+	err = userService.RegisterUser(&model.User{Username: "TESTUSER", Email: "other@example.com", Password: "password1"})
+	assert.Equal(t, ErrUsernameTaken, err)
+	mockDAO.AssertNotCalled(t, "CreateUser", mock.Anything)
+
+	// Scenario 3: Email registered by another user
+	mockDAO.On("UsernameExists", "other").Return(false, nil)
+	mockDAO.On("EmailExists", "test@example.com").Return(true, nil)
+
+	err = userService.RegisterUser(&model.User{Username: "other", Email: "TEST@example.com", Password: "password1"})
+	assert.Equal(t, ErrEmailTaken, err)
+	mockDAO.AssertNotCalled(t, "CreateUser", mock.Anything)
+
+	// Scenario 4: A concurrent registration wins the race after the checks
+	mockDAO.On("UsernameExists", "racer").Return(false, nil)
+	mockDAO.On("EmailExists", "racer@example.com").Return(false, nil)
+	mockDAO.On("CreateUser", mock.AnythingOfType("*model.User")).Return(ErrUsernameTaken)
+
+	err = userService.RegisterUser(&model.User{Username: "racer", Email: "racer@example.com", Password: "password1"})
+	assert.ErrorIs(t, err, ErrUsernameTaken)
 }
 
 func TestValidateUser(t *testing.T) {
@@ -106,8 +124,9 @@ func TestGetUserByUsername(t *testing.T) {
 
 	// Scenario 1: Successfully retrieve a user by username
 	mockDAO.On("GetUserByUsername", "testUser").Return(testUser, nil) // Simulates user exists
-	err := userService.RegisterUser(testUser)
-	assert.Equal(t, ErrUsernameOrEmailTaken, err) // Verifies the correct error is returned
+	user, err := userService.GetUserByUsername("testUser")
+	assert.NoError(t, err)
+	assert.Equal(t, testUser, user)
 
 	// Scenario 2: User not found
 	mockDAO.On("GetUserByUsername", "nonExistingUser").Return(nil, ErrUserNotFound)
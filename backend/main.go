@@ -1,12 +1,16 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
 
+	"github.com/kaiohenricunha/go-music-k8s/backend/api"
 	"github.com/kaiohenricunha/go-music-k8s/backend/api/routes"
 	"github.com/kaiohenricunha/go-music-k8s/backend/internal/config"
 	"github.com/kaiohenricunha/go-music-k8s/backend/internal/dao"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/mail"
+	"github.com/kaiohenricunha/go-music-k8s/backend/internal/ratelimit"
 	"github.com/kaiohenricunha/go-music-k8s/backend/internal/service"
 )
 
@@ -25,11 +29,69 @@ func main() {
 
 	// Setup Services with the DAOs
 	userService := service.NewUserService(userDAO)
+	loginService := service.NewLoginService(userDAO, userService)
+	oidcLoginService := service.NewOIDCLoginService(userDAO, service.OIDCConfig{
+		IssuerURL:    cfg.OIDCIssuerURL,
+		ClientID:     cfg.OIDCClientID,
+		ClientSecret: cfg.OIDCClientSecret,
+		RedirectURL:  cfg.OIDCRedirectURL,
+	})
+	apiTokenService := service.NewAPITokenService(userDAO)
 	songService := service.NewSongService(songDAO)
 	playlistService := service.NewPlaylistService(playlistDAO)
+	ratingService := service.NewRatingService(playlistDAO, playlistService)
+	socialService := service.NewSocialService(playlistDAO, playlistService)
+	playService := service.NewPlayService(songDAO, playlistService)
+	libraryService := service.NewLibraryService(songDAO)
+	recommendationService := service.NewRecommendationService(playlistDAO, playlistService)
+
+	// Create the bootstrap admin and the optional demo data
+	seedService := service.NewSeedService(userDAO, userService, playlistService, service.SeedConfig{
+		AdminUsername: cfg.BootstrapAdminUsername,
+		AdminEmail:    cfg.BootstrapAdminEmail,
+		AdminPassword: cfg.BootstrapAdminPassword,
+		AdminRole:     cfg.BootstrapAdminRole,
+		DemoData:      cfg.SeedDemoData,
+	})
+	if err := seedService.Seed(); err != nil {
+		log.Fatalf("Failed to seed data: %v", err)
+	}
+
+	catalogClient := service.NewSpotifyCatalogClient()
+	playlistImportService := service.NewPlaylistImportService(playlistDAO, catalogClient, songService)
+
+	// Start the background worker that keeps cached Spotify metadata fresh
+	catalogRefreshService := service.NewCatalogRefreshService(songDAO, catalogClient, service.CatalogRefreshConfig{
+		Interval:  cfg.CatalogRefreshInterval,
+		MaxAge:    cfg.CatalogRefreshMaxAge,
+		BatchSize: cfg.CatalogRefreshBatchSize,
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go catalogRefreshService.Run(ctx)
+
+	// Start the background worker that recomputes smart playlists
+	smartPlaylistRefreshService := service.NewSmartPlaylistRefreshService(playlistDAO, cfg.SmartPlaylistRefreshInterval)
+	go smartPlaylistRefreshService.Run(ctx)
+
+	// Keep rate limits in the database when they must hold across replicas
+	var rateLimitStore ratelimit.Store = ratelimit.NewMemoryStore()
+	if cfg.RateLimitStore == "database" {
+		rateLimitStore = ratelimit.NewDAOStore(userDAO, cfg.RateLimits)
+	}
+
+	// Send emails to users through SMTP, or keep them local during development
+	var mailSender mail.Sender = mail.LogSender{}
+	switch cfg.MailSender {
+	case "smtp":
+		mailSender = &mail.SMTPSender{Addr: cfg.SMTPAddr, From: cfg.MailFrom, Username: cfg.SMTPUsername, Password: cfg.SMTPPassword}
+	case "file":
+		mailSender = &mail.FileSender{Dir: cfg.MailDir, From: cfg.MailFrom}
+	}
+	accountService := service.NewAccountService(userDAO, mailSender, service.AccountConfig{Tokens: cfg.Tokens, AppURL: cfg.AppURL})
 
 	// Setup API routes with the services
-	router := routes.SetupRoutes(userService, songService, playlistService)
+	router := routes.SetupRoutes(userService, songService, playlistService, playlistImportService, ratingService, socialService, playService, libraryService, recommendationService, catalogRefreshService, loginService, oidcLoginService, apiTokenService, accountService, cfg.Tokens, rateLimitStore, cfg.RateLimits, api.TrustedProxies(cfg.TrustedProxies))
 
 	// Start the server
 	log.Printf("Starting server on port %s", cfg.ServerPort)
@@ -5,7 +5,6 @@ function RegistrationForm() {
     const [email, setEmail] = useState('');
     const [username, setUsername] = useState('');
     const [password, setPassword] = useState('');
-    const [role, setRole] = useState('');
     const [message, setMessage] = useState('');
     const [isError, setIsError] = useState(false);
 
@@ -32,9 +31,6 @@ function RegistrationForm() {
             case 'password':
                 setPassword(value);
                 break;
-            case 'role':
-                setRole(value);
-                break;
             default:
                 break;
         }
@@ -52,12 +48,12 @@ function RegistrationForm() {
                 headers: {
                     'Content-Type': 'application/json',
                 },
-                body: JSON.stringify({ fullName, email, username, password, role }),
+                body: JSON.stringify({ full_name: fullName, email, username, password }),
             });
 
             const data = await response.json();
             if (!response.ok) {
-                throw new Error(data.message || 'Registration failed. Please try again.');
+                throw new Error(data.error || data.message || 'Registration failed. Please try again.');
             }
             setMessage('Registration successful. Please log in.');
         } catch (error) {
@@ -121,24 +117,6 @@ function RegistrationForm() {
                         required
                     />
                 </div>
-                <div className="form-group">
-                    <label htmlFor="role">What option best defines you?</label>
-                    <select
-                        id="role"
-                        name="role"
-                        className="form-control"
-                        value={role}
-                        onChange={handleInputChange} 
-                        required
-                    >
-                        <option disabled value="">Select your current status</option>
-                        <option value="student">Music Student</option>
-                        <option value="amateur">Amateur Musician</option>
-                        <option value="professional">Professional Musician</option>
-                        <option value="songwriter">Songwriter</option>
-                        <option value="other">Other</option>
-                    </select>
-                </div>
                 <div className="form-group">
                     <button type="submit" className="submit-button">Register</button>
                 </div>
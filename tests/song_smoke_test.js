@@ -10,7 +10,7 @@ export let options = {
 
 const BASE_URL = 'http://localhost:8081/api/v1';
 const USERNAME = `testUser${Math.random().toString(36).substring(7)}`; // Ensure unique username
-const PASSWORD = 'testPass';
+const PASSWORD = 'testPass1';
 
 function basicAuthHeader(user, pass) {
     const credentials = b64encode(`${user}:${pass}`);
@@ -20,6 +20,7 @@ function basicAuthHeader(user, pass) {
 function createUser() {
     let res = http.post(`${BASE_URL}/register`, JSON.stringify({
         username: USERNAME,
+        email: `${USERNAME}@example.com`,
         password: PASSWORD,
     }), {
         headers: {
@@ -9,8 +9,8 @@ export let options = {
 
 const BASE_URL = 'http://localhost:8081/api/v1';
 const USERNAME = `testUser${Math.random().toString(36).substring(7)}`; // Generate a random username
-const PASSWORD = 'testPass';
-const NEW_PASSWORD = 'newPassword'; // Correctly define the new password for update operation
+const PASSWORD = 'testPass1';
+const NEW_PASSWORD = 'newPassword1'; // Correctly define the new password for update operation
 
 function basicAuthHeader(user, pass) {
     const credentials = `${user}:${pass}`;
@@ -22,6 +22,7 @@ export default function () {
     // Register a new user
     let res = http.post(`${BASE_URL}/register`, JSON.stringify({
         username: USERNAME,
+        email: `${USERNAME}@example.com`,
         password: PASSWORD,
     }), {
         headers: { 'Content-Type': 'application/json' },
@@ -40,6 +41,7 @@ export default function () {
     // Create a new user with the same username
     res = http.post(`${BASE_URL}/register`, JSON.stringify({
         username: USERNAME,
+        email: `${USERNAME}@example.com`,
         password: PASSWORD,
     }), {
         headers: { 'Content-Type': 'application/json' },